@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+	"github.com/agentplexus/mcp-confluence/storage"
+)
+
+// exportedPage is a page fetched for export and the file it is written to.
+type exportedPage struct {
+	info *confluence.PageInfo
+	page *storage.Page
+	file string
+}
+
+// exportHTMLCommand writes a page and its descendants as standalone HTML
+// files. Links between exported pages point at the exported files; other
+// page links and attachments point back at Confluence.
+func exportHTMLCommand(args []string) error {
+	fs := flag.NewFlagSet("export-html", flag.ContinueOnError)
+	out := fs.String("out", ".", "output directory")
+	css := fs.Bool("css", true, "embed the default stylesheet")
+	limit := fs.Int("limit", 200, "maximum number of descendant pages (0 for no limit)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected a page ID, got %d arguments", fs.NArg())
+	}
+	rootID := fs.Arg(0)
+
+	baseURL, client, err := clientFromEnv()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	descendants, err := client.GetDescendants(ctx, rootID)
+	if err != nil {
+		return err
+	}
+	if *limit > 0 && len(descendants) > *limit {
+		fmt.Fprintf(os.Stderr, "export-html: exporting %d of %d descendant pages; raise -limit to export all\n", *limit, len(descendants))
+		descendants = descendants[:*limit]
+	}
+	ids := []string{rootID}
+	for _, d := range descendants {
+		ids = append(ids, d.ID)
+	}
+
+	pages := make([]*exportedPage, 0, len(ids))
+	files := make(map[string]string) // space key + "/" + title -> file
+	used := make(map[string]bool)
+	for i, id := range ids {
+		page, info, err := client.GetPageStorage(ctx, id)
+		if err != nil {
+			return fmt.Errorf("page %s: %w", id, err)
+		}
+		file := "index.html"
+		if i > 0 {
+			file = fileName(info.Title, info.ID, used)
+		}
+		used[file] = true
+		files[info.SpaceKey+"/"+info.Title] = file
+		pages = append(pages, &exportedPage{info: info, page: page, file: file})
+	}
+
+	if err := os.MkdirAll(*out, 0o750); err != nil {
+		return err
+	}
+	for _, p := range pages {
+		spaceKey, pageID := p.info.SpaceKey, p.info.ID
+		html, err := storage.RenderHTML(p.page, storage.HTMLOptions{
+			Standalone: true,
+			Title:      p.info.Title,
+			Stylesheet: *css,
+			ResolvePage: func(space, title string) string {
+				if space == "" {
+					space = spaceKey
+				}
+				if file, ok := files[space+"/"+title]; ok {
+					return file
+				}
+				return baseURL + "/display/" + url.PathEscape(space) + "/" + url.QueryEscape(title)
+			},
+			ResolveAttachment: func(filename string) string {
+				return baseURL + "/download/attachments/" + pageID + "/" + url.PathEscape(filename)
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("page %s: %w", pageID, err)
+		}
+		path := filepath.Join(*out, p.file)
+		if err := os.WriteFile(path, []byte(html), 0o600); err != nil {
+			return err
+		}
+		fmt.Println(path)
+	}
+	return nil
+}
+
+// fileName derives an HTML file name from a page title, falling back to the
+// page ID when the title has no usable characters or the name is taken.
+func fileName(title, id string, used map[string]bool) string {
+	var buf strings.Builder
+	dash := false
+	for _, c := range strings.ToLower(title) {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) {
+			if dash && buf.Len() > 0 {
+				buf.WriteByte('-')
+			}
+			buf.WriteRune(c)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	name := buf.String()
+	if name == "" || name == "index" || used[name+".html"] {
+		name = strings.TrimPrefix(name+"-"+id, "-")
+	}
+	return name + ".html"
+}
+
+// clientFromEnv creates a Confluence client from the same environment
+// variables as the MCP server.
+func clientFromEnv() (string, *confluence.Client, error) {
+	baseURL := strings.TrimSuffix(os.Getenv("CONFLUENCE_BASE_URL"), "/")
+	username := os.Getenv("CONFLUENCE_USERNAME")
+	apiToken := os.Getenv("CONFLUENCE_API_TOKEN")
+	if baseURL == "" || username == "" || apiToken == "" {
+		return "", nil, fmt.Errorf("CONFLUENCE_BASE_URL, CONFLUENCE_USERNAME and CONFLUENCE_API_TOKEN must be set")
+	}
+	auth := confluence.BasicAuth{Username: username, Token: apiToken}
+	return baseURL, confluence.NewClient(baseURL, auth), nil
+}
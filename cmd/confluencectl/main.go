@@ -0,0 +1,161 @@
+// Command confluencectl provides command-line utilities for working with
+// Confluence Storage Format outside of the MCP server.
+//
+// Usage:
+//
+//	confluencectl normalize [file]   Print the canonical form of Storage XHTML
+//	confluencectl pretty [file]      Print indented Storage XHTML for review
+//	confluencectl diff [-json] old new
+//	                                 Print the block-level diff between two pages
+//	confluencectl markdown [file]    Convert Markdown to Storage XHTML
+//	confluencectl html [file]        Convert Storage XHTML to semantic HTML
+//	confluencectl export-html [-out dir] [-css=false] [-limit n] page-id
+//	                                 Export a page and its descendants as HTML
+//
+// When no file is given, input is read from stdin. export-html reads the
+// Confluence connection from CONFLUENCE_BASE_URL, CONFLUENCE_USERNAME and
+// CONFLUENCE_API_TOKEN, like the MCP server.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/agentplexus/mcp-confluence/storage"
+)
+
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"normalize": {
+		usage: "normalize [file]   Print the canonical form of Storage XHTML",
+		run:   formatCommand(storage.Normalize),
+	},
+	"pretty": {
+		usage: "pretty [file]      Print indented Storage XHTML for review",
+		run:   formatCommand(storage.Pretty),
+	},
+	"diff": {
+		usage: "diff [-json] old new\n                     Print the block-level diff between two pages",
+		run:   diffCommand,
+	},
+	"markdown": {
+		usage: "markdown [file]    Convert Markdown to Storage XHTML",
+		run: formatCommand(func(input string) (string, error) {
+			return storage.Render(storage.ParseMarkdown(input))
+		}),
+	},
+	"html": {
+		usage: "html [file]        Convert Storage XHTML to semantic HTML",
+		run: formatCommand(func(input string) (string, error) {
+			page, err := storage.Parse(input)
+			if err != nil {
+				return "", err
+			}
+			out, err := storage.RenderHTML(page, storage.HTMLOptions{})
+			return out + "\n", err
+		}),
+	},
+	"export-html": {
+		usage: "export-html [-out dir] [-css=false] [-limit n] page-id\n                     Export a page and its descendants as standalone HTML",
+		run:   exportHTMLCommand,
+	},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "confluencectl %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: confluencectl <command> [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %s\n", commands[name].usage)
+	}
+}
+
+// formatCommand returns a command that applies format to a file or stdin.
+func formatCommand(format func(string) (string, error)) func(args []string) error {
+	return func(args []string) error {
+		input, err := readInput(args)
+		if err != nil {
+			return err
+		}
+		out, err := format(input)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(os.Stdout, out)
+		return err
+	}
+}
+
+// diffCommand prints the diff between two Storage XHTML files.
+func diffCommand(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print edits as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return fmt.Errorf("expected two files, got %d", fs.NArg())
+	}
+
+	pages := make([]*storage.Page, 2)
+	for i, name := range fs.Args() {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		if pages[i], err = storage.Parse(string(data)); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	diff := storage.Diff(pages[0], pages[1])
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(diff)
+	}
+	_, err := io.WriteString(os.Stdout, diff.Unified())
+	return err
+}
+
+// readInput reads the file named by args[0], or stdin when args is empty.
+func readInput(args []string) (string, error) {
+	if len(args) == 0 {
+		data, err := io.ReadAll(os.Stdin)
+		return string(data), err
+	}
+	data, err := os.ReadFile(args[0])
+	return string(data), err
+}
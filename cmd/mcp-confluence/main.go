@@ -5,6 +5,8 @@
 //   - CONFLUENCE_BASE_URL: The base URL of your Confluence instance (e.g., https://example.atlassian.net/wiki)
 //   - CONFLUENCE_USERNAME: Your Confluence username (email)
 //   - CONFLUENCE_API_TOKEN: Your Confluence API token
+//   - CONFLUENCE_ATTACHMENT_DIR: Optional directory the attachment tools may read
+//     files from and write files to. Without it, attachments are exchanged as base64.
 //
 // Example usage:
 //
@@ -74,7 +76,11 @@ func main() {
 	client := confluence.NewClient(baseURL, auth)
 
 	// Create MCP server
-	server := mcpserver.New(client)
+	var opts []mcpserver.Option
+	if dir := os.Getenv("CONFLUENCE_ATTACHMENT_DIR"); dir != "" {
+		opts = append(opts, mcpserver.WithAttachmentDir(dir))
+	}
+	server := mcpserver.New(client, opts...)
 
 	// Run the stdio transport
 	if err := runStdio(server); err != nil {
@@ -0,0 +1,80 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/agentplexus/mcp-confluence/storage"
+)
+
+// GetPageADF retrieves a page in Atlas Document Format, converted to IR.
+// The returned issues describe ADF content that has no IR equivalent and
+// was simplified or dropped.
+func (c *Client) GetPageADF(ctx context.Context, pageID string) (*storage.Page, *PageInfo, []storage.FidelityIssue, error) {
+	doc, info, err := c.GetPageADFRaw(ctx, pageID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	page, issues, err := storage.ParseADF(doc)
+	if err != nil {
+		return nil, info, nil, fmt.Errorf("parse error: %w", err)
+	}
+
+	return page, info, issues, nil
+}
+
+// GetPageADFRaw retrieves a page's ADF document.
+func (c *Client) GetPageADFRaw(ctx context.Context, pageID string) (*storage.ADFNode, *PageInfo, error) {
+	value, info, err := c.getPageRaw(ctx, pageID, "atlas_doc_format", "")
+	if err != nil {
+		return nil, nil, err
+	}
+	if value == "" {
+		return &storage.ADFNode{Type: "doc", Version: 1, Content: []*storage.ADFNode{}}, info, nil
+	}
+
+	var doc storage.ADFNode
+	if err := json.Unmarshal([]byte(value), &doc); err != nil {
+		return nil, info, fmt.Errorf("adf decode error: %w", err)
+	}
+
+	return &doc, info, nil
+}
+
+// ErrLossyADF is returned by UpdatePageADF when the page has content that
+// cannot be represented in ADF and WithAllowLossy was not given.
+var ErrLossyADF = errors.New("page content cannot be fully represented in ADF")
+
+// UpdatePageADF updates a page with IR content, sent as ADF. The returned
+// issues describe content that cannot be represented in ADF. If there are
+// any, the page is left unchanged and the error wraps ErrLossyADF, unless
+// WithAllowLossy is given; the update then leaves that content out.
+func (c *Client) UpdatePageADF(ctx context.Context, pageID string, page *storage.Page, version int, title string, opts ...UpdateOption) ([]storage.FidelityIssue, error) {
+	doc, issues, err := storage.RenderADF(page)
+	if err != nil {
+		return nil, fmt.Errorf("render error: %w", err)
+	}
+
+	var o updateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if len(issues) > 0 && !o.allowLossy {
+		return issues, fmt.Errorf("%w: %d parts would be dropped; the page was not updated", ErrLossyADF, len(issues))
+	}
+
+	return issues, c.UpdatePageADFRaw(ctx, pageID, doc, version, title, opts...)
+}
+
+// UpdatePageADFRaw updates a page with an ADF document.
+func (c *Client) UpdatePageADFRaw(ctx context.Context, pageID string, doc *storage.ADFNode, version int, title string, opts ...UpdateOption) error {
+	value, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	return c.updatePageBody(ctx, pageID, "atlas_doc_format", string(value), version, title, opts)
+}
@@ -0,0 +1,118 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/mcp-confluence/storage"
+)
+
+func TestGetPageADF(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if expand := r.URL.Query().Get("expand"); !strings.Contains(expand, "body.atlas_doc_format") {
+			t.Errorf("Expected atlas_doc_format expansion, got %q", expand)
+		}
+		doc := `{"type":"doc","version":1,"content":[` +
+			`{"type":"heading","attrs":{"level":2},"content":[{"type":"text","text":"Plan"}]},` +
+			`{"type":"paragraph","content":[{"type":"mention","attrs":{"text":"@Ana"}}]}]}`
+		response := map[string]interface{}{
+			"id":      "12345",
+			"type":    "page",
+			"status":  "current",
+			"title":   "Test Page",
+			"body":    map[string]interface{}{"atlas_doc_format": map[string]string{"value": doc, "representation": "atlas_doc_format"}},
+			"version": map[string]int{"number": 3},
+			"space":   map[string]string{"key": "TEST"},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			panic(err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	page, info, issues, err := client.GetPageADF(context.Background(), "12345")
+	if err != nil {
+		t.Fatalf("GetPageADF() error = %v", err)
+	}
+
+	if info.Version != 3 {
+		t.Errorf("GetPageADF() version = %d, want 3", info.Version)
+	}
+	if len(page.Blocks) != 2 {
+		t.Fatalf("GetPageADF() blocks = %d, want 2", len(page.Blocks))
+	}
+	if h, ok := page.Blocks[0].(*storage.Heading); !ok || h.Level != 2 || h.Text != "Plan" {
+		t.Errorf("GetPageADF() blocks[0] = %#v", page.Blocks[0])
+	}
+	if len(issues) != 1 || issues[0].Node != "mention" {
+		t.Errorf("GetPageADF() issues = %+v, want one mention issue", issues)
+	}
+}
+
+func TestUpdatePageADF(t *testing.T) {
+	requests := 0
+	var body struct {
+		Body map[string]struct {
+			Value          string `json:"value"`
+			Representation string `json:"representation"`
+		} `json:"body"`
+		Version struct {
+			Number int `json:"number"`
+		} `json:"version"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Method != "PUT" {
+			t.Errorf("Expected PUT request, got %s", r.Method)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			panic(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write([]byte(`{"id": "12345"}`)); err != nil {
+			panic(err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	page := &storage.Page{Blocks: []storage.Block{
+		&storage.Paragraph{Text: "Hello"},
+		&storage.Image{Attachment: "a.png"},
+	}}
+	// Content that ADF cannot hold is not published unless allowed.
+	issues, err := client.UpdatePageADF(context.Background(), "12345", page, 4, "Title")
+	if !errors.Is(err, ErrLossyADF) || requests != 0 {
+		t.Fatalf("UpdatePageADF() error = %v after %d requests, want ErrLossyADF before any", err, requests)
+	}
+	if len(issues) != 1 {
+		t.Errorf("UpdatePageADF() issues = %+v, want one", issues)
+	}
+
+	issues, err = client.UpdatePageADF(context.Background(), "12345", page, 4, "Title", WithAllowLossy(true))
+	if err != nil {
+		t.Fatalf("UpdatePageADF() error = %v", err)
+	}
+
+	if len(issues) != 1 || issues[0].Node != "image" {
+		t.Errorf("UpdatePageADF() issues = %+v, want one image issue", issues)
+	}
+	adf, ok := body.Body["atlas_doc_format"]
+	if !ok || adf.Representation != "atlas_doc_format" {
+		t.Fatalf("UpdatePageADF() body = %+v", body.Body)
+	}
+	want := `{"type":"doc","version":1,"content":[{"type":"paragraph","content":[{"type":"text","text":"Hello"}]}]}`
+	if adf.Value != want {
+		t.Errorf("UpdatePageADF() value = %s, want %s", adf.Value, want)
+	}
+	if body.Version.Number != 5 {
+		t.Errorf("UpdatePageADF() version = %d, want 5", body.Version.Number)
+	}
+}
@@ -0,0 +1,237 @@
+package confluence
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func attachmentJSON(id, title string, version int) map[string]interface{} {
+	return map[string]interface{}{
+		"id":      id,
+		"title":   title,
+		"version": map[string]interface{}{"number": version},
+		"extensions": map[string]interface{}{
+			"mediaType": "image/png",
+			"fileSize":  1024,
+		},
+		"_links": map[string]interface{}{
+			"download": fmt.Sprintf("/download/attachments/12345/%s?version=%d", title, version),
+		},
+	}
+}
+
+func TestListAttachments(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/rest/api/content/12345/child/attachment" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+
+		// Serve a short page that still links to the next, then the last.
+		count, links := 2, map[string]interface{}{"next": "/rest/api/content/12345/child/attachment?start=2"}
+		if r.URL.Query().Get("start") != "0" {
+			count, links = 1, map[string]interface{}{}
+		}
+		results := make([]interface{}, count)
+		for i := range results {
+			results[i] = attachmentJSON(fmt.Sprintf("att%d", i), fmt.Sprintf("file-%d.png", i), 1)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]interface{}{"results": results, "_links": links}); err != nil {
+			panic(err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	attachments, err := client.ListAttachments(context.Background(), "12345")
+	if err != nil {
+		t.Fatalf("ListAttachments() error = %v", err)
+	}
+	if len(attachments) != 3 || requests != 2 {
+		t.Errorf("ListAttachments() = %d attachments in %d requests", len(attachments), requests)
+	}
+	want := Attachment{
+		ID:          "att0",
+		Title:       "file-0.png",
+		MediaType:   "image/png",
+		FileSize:    1024,
+		Version:     1,
+		DownloadURL: server.URL + "/download/attachments/12345/file-0.png?version=1",
+	}
+	if attachments[0] != want {
+		t.Errorf("ListAttachments()[0] = %+v, want %+v", attachments[0], want)
+	}
+}
+
+func TestFindAttachment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var results []interface{}
+		if name := r.URL.Query().Get("filename"); name == "diagram.png" {
+			results = append(results, attachmentJSON("att1", name, 2))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]interface{}{"results": results}); err != nil {
+			panic(err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	attachment, err := client.FindAttachment(context.Background(), "12345", "diagram.png")
+	if err != nil {
+		t.Fatalf("FindAttachment() error = %v", err)
+	}
+	if attachment.ID != "att1" || attachment.Version != 2 {
+		t.Errorf("FindAttachment() = %+v", attachment)
+	}
+
+	_, err = client.FindAttachment(context.Background(), "12345", "missing.png")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("FindAttachment(missing) error = %v, want 404", err)
+	}
+}
+
+func TestUploadAttachment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("Expected POST request, got %s", r.Method)
+		}
+		if r.URL.Path != "/rest/api/content/12345/child/attachment" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		if r.Header.Get("X-Atlassian-Token") != "no-check" {
+			t.Errorf("X-Atlassian-Token = %q", r.Header.Get("X-Atlassian-Token"))
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			panic(err)
+		}
+		data, err := io.ReadAll(file)
+		if err != nil {
+			panic(err)
+		}
+		if header.Filename != "report.csv" || string(data) != "a,b\n1,2\n" {
+			t.Errorf("Unexpected file %q: %q", header.Filename, data)
+		}
+		if ct := header.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+			t.Errorf("Part Content-Type = %q", ct)
+		}
+		if r.FormValue("comment") != "nightly run" {
+			t.Errorf("comment = %q", r.FormValue("comment"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]interface{}{
+			"results": []interface{}{attachmentJSON("att9", header.Filename, 1)},
+		}); err != nil {
+			panic(err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	attachment, err := client.UploadAttachment(context.Background(), "12345", "report.csv", strings.NewReader("a,b\n1,2\n"), "nightly run")
+	if err != nil {
+		t.Fatalf("UploadAttachment() error = %v", err)
+	}
+	if attachment.ID != "att9" || attachment.Title != "report.csv" {
+		t.Errorf("UploadAttachment() = %+v", attachment)
+	}
+}
+
+func TestUpdateAttachmentData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/content/12345/child/attachment/att1/data" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		if _, _, err := r.FormFile("file"); err != nil {
+			panic(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(attachmentJSON("att1", "diagram.png", 3)); err != nil {
+			panic(err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	attachment, err := client.UpdateAttachmentData(context.Background(), "12345", "att1", "diagram.png", strings.NewReader("png"), "")
+	if err != nil {
+		t.Fatalf("UpdateAttachmentData() error = %v", err)
+	}
+	if attachment.Version != 3 {
+		t.Errorf("UpdateAttachmentData() version = %d, want 3", attachment.Version)
+	}
+}
+
+func TestUploadAttachmentError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"message":"Cannot add a new attachment with same file name"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	_, err := client.UploadAttachment(context.Background(), "12345", "diagram.png", strings.NewReader("png"), "")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("UploadAttachment() error = %v, want 400", err)
+	}
+}
+
+func TestDownloadAttachment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/download/attachments/12345/diagram.png" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("png data"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	var buf bytes.Buffer
+	n, err := client.DownloadAttachment(context.Background(), &Attachment{
+		DownloadURL: server.URL + "/download/attachments/12345/diagram.png?version=1",
+	}, &buf)
+	if err != nil {
+		t.Fatalf("DownloadAttachment() error = %v", err)
+	}
+	if n != 8 || buf.String() != "png data" {
+		t.Errorf("DownloadAttachment() = %d, %q", n, buf.String())
+	}
+
+	_, err = client.DownloadAttachment(context.Background(), &Attachment{DownloadURL: server.URL + "/missing"}, &buf)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("DownloadAttachment(missing) error = %v, want 404", err)
+	}
+}
+
+func TestDeleteAttachment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" || r.URL.Path != "/rest/api/content/att1" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	if err := client.DeleteAttachment(context.Background(), "att1"); err != nil {
+		t.Errorf("DeleteAttachment() error = %v", err)
+	}
+}
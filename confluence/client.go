@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 
 	"github.com/agentplexus/mcp-confluence/storage"
 )
@@ -81,6 +82,80 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("confluence API error %d: %s", e.StatusCode, e.Message)
 }
 
+// listLinks holds the links of one page of a paginated listing. Confluence
+// may return fewer results than requested before the end of a listing, so
+// only the absence of a next link marks the last page.
+type listLinks struct {
+	Links struct {
+		Next string `json:"next"`
+	} `json:"_links"`
+}
+
+// hasNext reports whether the listing continues after this page.
+func (l listLinks) hasNext() bool {
+	return l.Links.Next != ""
+}
+
+// doJSON sends a request to the API path (e.g. "/rest/api/content/123/label")
+// with payload, if non-nil, encoded as JSON, and decodes the JSON response
+// into out, if non-nil. Responses other than 200, 201 and 204 are returned
+// as an *APIError with the given message.
+func (c *Client) doJSON(ctx context.Context, method, path string, payload, out interface{}, message string) error {
+	var reqBody io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	c.auth.Apply(req)
+
+	return c.send(req, out, message)
+}
+
+// send sends a prepared request and decodes the JSON response into out, if
+// non-nil, treating responses as doJSON does.
+func (c *Client) send(req *http.Request, out interface{}, message string) error {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response body: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+	default:
+		return &APIError{
+			StatusCode: resp.StatusCode,
+			Message:    message,
+			Body:       string(body),
+		}
+	}
+
+	if out == nil || len(body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("json decode error: %w", err)
+	}
+	return nil
+}
+
 // PageInfo contains metadata about a Confluence page.
 type PageInfo struct {
 	ID       string `json:"id"`
@@ -108,7 +183,33 @@ func (c *Client) GetPageStorage(ctx context.Context, pageID string) (*storage.Pa
 
 // GetPageStorageRaw retrieves a page's raw Storage XHTML.
 func (c *Client) GetPageStorageRaw(ctx context.Context, pageID string) (string, *PageInfo, error) {
-	u := fmt.Sprintf("%s/rest/api/content/%s?expand=body.storage,version,space", c.baseURL, pageID)
+	return c.getPageRaw(ctx, pageID, "storage", "")
+}
+
+// GetPageVersion retrieves a historical version of a page, parsed to IR.
+func (c *Client) GetPageVersion(ctx context.Context, pageID string, version int) (*storage.Page, *PageInfo, error) {
+	xhtml, info, err := c.GetPageVersionRaw(ctx, pageID, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page, err := storage.Parse(xhtml)
+	if err != nil {
+		return nil, info, fmt.Errorf("parse error: %w", err)
+	}
+
+	return page, info, nil
+}
+
+// GetPageVersionRaw retrieves the raw Storage XHTML of a historical version of a page.
+func (c *Client) GetPageVersionRaw(ctx context.Context, pageID string, version int) (string, *PageInfo, error) {
+	return c.getPageRaw(ctx, pageID, "storage", fmt.Sprintf("&status=historical&version=%d", version))
+}
+
+// getPageRaw retrieves a page's body in the given representation, such as
+// storage or atlas_doc_format.
+func (c *Client) getPageRaw(ctx context.Context, pageID, representation, query string) (string, *PageInfo, error) {
+	u := fmt.Sprintf("%s/rest/api/content/%s?expand=body.%s,version,space%s", c.baseURL, pageID, representation, query)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
 	if err != nil {
@@ -141,10 +242,8 @@ func (c *Client) GetPageStorageRaw(ctx context.Context, pageID string) (string,
 		Type   string `json:"type"`
 		Status string `json:"status"`
 		Title  string `json:"title"`
-		Body   struct {
-			Storage struct {
-				Value string `json:"value"`
-			} `json:"storage"`
+		Body   map[string]struct {
+			Value string `json:"value"`
 		} `json:"body"`
 		Version struct {
 			Number int `json:"number"`
@@ -166,11 +265,44 @@ func (c *Client) GetPageStorageRaw(ctx context.Context, pageID string) (string,
 		SpaceKey: result.Space.Key,
 	}
 
-	return result.Body.Storage.Value, info, nil
+	return result.Body[representation].Value, info, nil
+}
+
+// UpdateOption configures a page update.
+type UpdateOption func(*updateOptions)
+
+type updateOptions struct {
+	message    string
+	minorEdit  bool
+	allowLossy bool
+}
+
+// WithVersionMessage sets the message shown for the new version in the
+// page history.
+func WithVersionMessage(message string) UpdateOption {
+	return func(o *updateOptions) {
+		o.message = message
+	}
+}
+
+// WithMinorEdit marks the new version as a minor edit, which does not
+// notify page watchers.
+func WithMinorEdit(minor bool) UpdateOption {
+	return func(o *updateOptions) {
+		o.minorEdit = minor
+	}
+}
+
+// WithAllowLossy lets UpdatePageADF publish content that cannot be fully
+// represented in ADF, leaving the unrepresentable parts out.
+func WithAllowLossy(allow bool) UpdateOption {
+	return func(o *updateOptions) {
+		o.allowLossy = allow
+	}
 }
 
 // UpdatePageStorage updates a page with the given IR content.
-func (c *Client) UpdatePageStorage(ctx context.Context, pageID string, page *storage.Page, version int, title string) error {
+func (c *Client) UpdatePageStorage(ctx context.Context, pageID string, page *storage.Page, version int, title string, opts ...UpdateOption) error {
 	xhtml, err := storage.Render(page)
 	if err != nil {
 		return fmt.Errorf("render error: %w", err)
@@ -180,29 +312,47 @@ func (c *Client) UpdatePageStorage(ctx context.Context, pageID string, page *sto
 		return fmt.Errorf("validation error: %w", err)
 	}
 
-	return c.UpdatePageStorageRaw(ctx, pageID, xhtml, version, title)
+	return c.UpdatePageStorageRaw(ctx, pageID, xhtml, version, title, opts...)
 }
 
 // UpdatePageStorageRaw updates a page with raw Storage XHTML.
-func (c *Client) UpdatePageStorageRaw(ctx context.Context, pageID, xhtml string, version int, title string) error {
+func (c *Client) UpdatePageStorageRaw(ctx context.Context, pageID, xhtml string, version int, title string, opts ...UpdateOption) error {
 	if err := storage.Validate(xhtml); err != nil {
 		return fmt.Errorf("validation error: %w", err)
 	}
 
+	return c.updatePageBody(ctx, pageID, "storage", xhtml, version, title, opts)
+}
+
+// updatePageBody publishes the next version of a page with a body in the
+// given representation.
+func (c *Client) updatePageBody(ctx context.Context, pageID, representation, value string, version int, title string, opts []UpdateOption) error {
 	u := fmt.Sprintf("%s/rest/api/content/%s", c.baseURL, pageID)
 
+	var o updateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	versionInfo := map[string]interface{}{
+		"number": version + 1,
+	}
+	if o.message != "" {
+		versionInfo["message"] = o.message
+	}
+	if o.minorEdit {
+		versionInfo["minorEdit"] = true
+	}
+
 	payload := map[string]interface{}{
 		"type":  "page",
 		"title": title,
 		"body": map[string]interface{}{
-			"storage": map[string]string{
-				"value":          xhtml,
-				"representation": "storage",
+			representation: map[string]string{
+				"value":          value,
+				"representation": representation,
 			},
 		},
-		"version": map[string]int{
-			"number": version + 1,
-		},
+		"version": versionInfo,
 	}
 
 	body, err := json.Marshal(payload)
@@ -350,56 +500,9 @@ func (c *Client) DeletePage(ctx context.Context, pageID string) error {
 	return nil
 }
 
-// GetSpace retrieves information about a space.
-func (c *Client) GetSpace(ctx context.Context, spaceKey string) (*SpaceInfo, error) {
-	u := fmt.Sprintf("%s/rest/api/space/%s", c.baseURL, spaceKey)
-
-	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Accept", "application/json")
-	c.auth.Apply(req)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("read response body: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, &APIError{
-			StatusCode: resp.StatusCode,
-			Message:    "failed to get space",
-			Body:       string(body),
-		}
-	}
-
-	var result SpaceInfo
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("json decode error: %w", err)
-	}
-
-	return &result, nil
-}
-
-// SpaceInfo contains metadata about a Confluence space.
-type SpaceInfo struct {
-	ID          int    `json:"id"`
-	Key         string `json:"key"`
-	Name        string `json:"name"`
-	Type        string `json:"type"`
-	Description string `json:"description,omitempty"`
-}
-
 // SearchPages searches for pages matching the given CQL query.
 func (c *Client) SearchPages(ctx context.Context, cql string, limit int) ([]PageInfo, error) {
-	u := fmt.Sprintf("%s/rest/api/content/search?cql=%s&limit=%d", c.baseURL, cql, limit)
+	u := fmt.Sprintf("%s/rest/api/content/search?cql=%s&limit=%d", c.baseURL, url.QueryEscape(cql), limit)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
 	if err != nil {
@@ -240,6 +240,40 @@ func TestUpdatePageStorageRaw(t *testing.T) {
 	}
 }
 
+func TestUpdatePageStorageRaw_Options(t *testing.T) {
+	var version map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			panic(err)
+		}
+		version = payload["version"].(map[string]interface{})
+
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write([]byte(`{"id": "12345"}`)); err != nil {
+			panic(err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{Username: "user", Token: "token"})
+	err := client.UpdatePageStorageRaw(context.Background(), "12345", "<p>Updated content</p>", 5, "Title",
+		WithVersionMessage("Sync inventory table"), WithMinorEdit(true))
+	if err != nil {
+		t.Fatalf("UpdatePageStorageRaw() error = %v", err)
+	}
+	if version["message"] != "Sync inventory table" || version["minorEdit"] != true || version["number"] != float64(6) {
+		t.Errorf("version = %v", version)
+	}
+
+	if err := client.UpdatePageStorageRaw(context.Background(), "12345", "<p>Updated content</p>", 5, "Title"); err != nil {
+		t.Fatalf("UpdatePageStorageRaw() error = %v", err)
+	}
+	if _, ok := version["message"]; ok {
+		t.Errorf("version without options = %v", version)
+	}
+}
+
 func TestUpdatePageStorageRaw_ValidationError(t *testing.T) {
 	client := NewClient("http://example.com", BasicAuth{Username: "user", Token: "token"})
 	err := client.UpdatePageStorageRaw(context.Background(), "12345", "<div>Invalid</div>", 5, "Title")
@@ -431,6 +465,9 @@ func TestGetSpace(t *testing.T) {
 
 func TestSearchPages(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cql := r.URL.Query().Get("cql"); cql != "space = TEST AND title ~ \"a&b\"" {
+			t.Errorf("Expected escaped CQL to round-trip, got %q", cql)
+		}
 		response := map[string]interface{}{
 			"results": []map[string]interface{}{
 				{"id": "1", "type": "page", "status": "current", "title": "Page 1"},
@@ -446,7 +483,7 @@ func TestSearchPages(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient(server.URL, BasicAuth{Username: "user", Token: "token"})
-	pages, err := client.SearchPages(context.Background(), "space=TEST", 10)
+	pages, err := client.SearchPages(context.Background(), `space = TEST AND title ~ "a&b"`, 10)
 
 	if err != nil {
 		t.Fatalf("SearchPages() error = %v", err)
@@ -460,3 +497,40 @@ func TestSearchPages(t *testing.T) {
 		t.Errorf("SearchPages() pages[0].Title = %v, want Page 1", pages[0].Title)
 	}
 }
+
+func TestGetPageVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("status") != "historical" {
+			t.Errorf("Expected status=historical, got %s", r.URL.Query().Get("status"))
+		}
+		if r.URL.Query().Get("version") != "3" {
+			t.Errorf("Expected version=3, got %s", r.URL.Query().Get("version"))
+		}
+
+		response := map[string]interface{}{
+			"id":    "12345",
+			"title": "Test Page",
+			"body": map[string]interface{}{
+				"storage": map[string]string{"value": "<p>Old</p>"},
+			},
+			"version": map[string]int{"number": 3},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			panic(err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{Username: "user", Token: "token"})
+	page, info, err := client.GetPageVersion(context.Background(), "12345", 3)
+	if err != nil {
+		t.Fatalf("GetPageVersion() error = %v", err)
+	}
+	if info.Version != 3 {
+		t.Errorf("GetPageVersion() info.Version = %v, want 3", info.Version)
+	}
+	if len(page.Blocks) != 1 {
+		t.Errorf("GetPageVersion() blocks = %d, want 1", len(page.Blocks))
+	}
+}
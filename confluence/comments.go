@@ -0,0 +1,220 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/agentplexus/mcp-confluence/storage"
+)
+
+// commentPageSize is the number of comments requested per page of results.
+const commentPageSize = 100
+
+// Comment locations.
+const (
+	CommentFooter = "footer"
+	CommentInline = "inline"
+)
+
+// Comment is a footer or inline comment on a page, with its replies.
+type Comment struct {
+	ID       string `json:"id"`
+	ParentID string `json:"parentId,omitempty"`
+	Location string `json:"location"`
+	Author   string `json:"author,omitempty"`
+	Created  string `json:"created,omitempty"`
+	Version  int    `json:"version"`
+
+	// Resolution and Selection are set for inline comments. Resolution is
+	// open, resolved, reopened or dangling; Selection is the page text the
+	// comment is anchored to.
+	Resolution string `json:"resolution,omitempty"`
+	Selection  string `json:"selection,omitempty"`
+
+	Body    *storage.Page `json:"-"`
+	Replies []*Comment    `json:"replies,omitempty"`
+}
+
+// commentResult is a comment as returned by the REST API.
+type commentResult struct {
+	ID   string `json:"id"`
+	Body struct {
+		Storage struct {
+			Value string `json:"value"`
+		} `json:"storage"`
+	} `json:"body"`
+	Version struct {
+		Number int    `json:"number"`
+		When   string `json:"when"`
+		By     struct {
+			DisplayName string `json:"displayName"`
+		} `json:"by"`
+	} `json:"version"`
+	Ancestors []struct {
+		ID string `json:"id"`
+	} `json:"ancestors"`
+	Extensions struct {
+		Location         string `json:"location"`
+		InlineProperties struct {
+			OriginalSelection string `json:"originalSelection"`
+		} `json:"inlineProperties"`
+		Resolution struct {
+			Status string `json:"status"`
+		} `json:"resolution"`
+	} `json:"extensions"`
+}
+
+func (r commentResult) comment() (*Comment, error) {
+	body, err := storage.Parse(r.Body.Storage.Value)
+	if err != nil {
+		return nil, fmt.Errorf("parse comment %s: %w", r.ID, err)
+	}
+	c := &Comment{
+		ID:         r.ID,
+		Location:   r.Extensions.Location,
+		Author:     r.Version.By.DisplayName,
+		Created:    r.Version.When,
+		Version:    r.Version.Number,
+		Resolution: r.Extensions.Resolution.Status,
+		Selection:  r.Extensions.InlineProperties.OriginalSelection,
+		Body:       body,
+	}
+	// Ancestors run from the thread's root comment to the direct parent.
+	if n := len(r.Ancestors); n > 0 {
+		c.ParentID = r.Ancestors[n-1].ID
+	}
+	if c.Location == "" {
+		c.Location = CommentFooter
+	}
+	return c, nil
+}
+
+// ListComments lists the footer and inline comments of a page as threads:
+// top-level comments in page order, each with its replies.
+func (c *Client) ListComments(ctx context.Context, pageID string) ([]*Comment, error) {
+	var all []*Comment
+	for start := 0; ; {
+		var result struct {
+			Results []commentResult `json:"results"`
+			listLinks
+		}
+		path := fmt.Sprintf("/rest/api/content/%s/child/comment?expand=body.storage,version,ancestors,extensions.inlineProperties,extensions.resolution&depth=all&start=%d&limit=%d", pageID, start, commentPageSize)
+		if err := c.doJSON(ctx, "GET", path, nil, &result, "failed to list comments"); err != nil {
+			return nil, err
+		}
+		for _, r := range result.Results {
+			comment, err := r.comment()
+			if err != nil {
+				return nil, err
+			}
+			all = append(all, comment)
+		}
+		if !result.hasNext() || len(result.Results) == 0 {
+			break
+		}
+		start += len(result.Results)
+	}
+
+	byID := make(map[string]*Comment, len(all))
+	for _, comment := range all {
+		byID[comment.ID] = comment
+	}
+	threads := []*Comment{}
+	for _, comment := range all {
+		if parent := byID[comment.ParentID]; parent != nil {
+			parent.Replies = append(parent.Replies, comment)
+		} else {
+			threads = append(threads, comment)
+		}
+	}
+	return threads, nil
+}
+
+// AddComment adds a footer comment to a page.
+func (c *Client) AddComment(ctx context.Context, pageID string, body *storage.Page) (*Comment, error) {
+	return c.createComment(ctx, pageID, "", body)
+}
+
+// ReplyToComment adds a reply to a comment on a page.
+func (c *Client) ReplyToComment(ctx context.Context, pageID, parentID string, body *storage.Page) (*Comment, error) {
+	return c.createComment(ctx, pageID, parentID, body)
+}
+
+func (c *Client) createComment(ctx context.Context, pageID, parentID string, body *storage.Page) (*Comment, error) {
+	xhtml, err := storage.Render(body)
+	if err != nil {
+		return nil, fmt.Errorf("render error: %w", err)
+	}
+	if err := storage.Validate(xhtml); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	payload := map[string]interface{}{
+		"type":      "comment",
+		"container": map[string]string{"id": pageID, "type": "page"},
+		"body": map[string]interface{}{
+			"storage": map[string]string{
+				"value":          xhtml,
+				"representation": "storage",
+			},
+		},
+	}
+	if parentID != "" {
+		payload["ancestors"] = []map[string]string{{"id": parentID}}
+	}
+
+	var result commentResult
+	if err := c.doJSON(ctx, "POST", "/rest/api/content", payload, &result, "failed to create comment"); err != nil {
+		return nil, err
+	}
+	comment, err := result.comment()
+	if err != nil {
+		return nil, err
+	}
+	comment.ParentID = parentID
+	return comment, nil
+}
+
+// ResolveComment marks an inline comment as resolved. It uses the v2 API,
+// which is only available on Confluence Cloud.
+func (c *Client) ResolveComment(ctx context.Context, commentID string) error {
+	return c.setCommentResolved(ctx, commentID, true)
+}
+
+// ReopenComment reopens a resolved inline comment. Like ResolveComment, it
+// requires Confluence Cloud.
+func (c *Client) ReopenComment(ctx context.Context, commentID string) error {
+	return c.setCommentResolved(ctx, commentID, false)
+}
+
+func (c *Client) setCommentResolved(ctx context.Context, commentID string, resolved bool) error {
+	path := "/api/v2/inline-comments/" + commentID
+	var current struct {
+		Version struct {
+			Number int `json:"number"`
+		} `json:"version"`
+		Body struct {
+			Storage struct {
+				Value string `json:"value"`
+			} `json:"storage"`
+		} `json:"body"`
+	}
+	if err := c.doJSON(ctx, "GET", path+"?body-format=storage", nil, &current, "failed to get comment"); err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"version": map[string]int{"number": current.Version.Number + 1},
+		"body": map[string]string{
+			"representation": "storage",
+			"value":          current.Body.Storage.Value,
+		},
+		"resolved": resolved,
+	}
+	return c.doJSON(ctx, "PUT", path, payload, nil, "failed to update comment")
+}
+
+// DeleteComment deletes a comment.
+func (c *Client) DeleteComment(ctx context.Context, commentID string) error {
+	return c.doJSON(ctx, "DELETE", "/rest/api/content/"+commentID, nil, nil, "failed to delete comment")
+}
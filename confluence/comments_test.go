@@ -0,0 +1,194 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/mcp-confluence/storage"
+)
+
+func commentJSON(id, body, location string, ancestors ...string) map[string]interface{} {
+	ancestorList := []interface{}{}
+	for _, a := range ancestors {
+		ancestorList = append(ancestorList, map[string]interface{}{"id": a})
+	}
+	return map[string]interface{}{
+		"id":   id,
+		"body": map[string]interface{}{"storage": map[string]interface{}{"value": body}},
+		"version": map[string]interface{}{
+			"number": 1,
+			"when":   "2024-05-01T10:00:00.000Z",
+			"by":     map[string]interface{}{"displayName": "Ana"},
+		},
+		"ancestors":  ancestorList,
+		"extensions": map[string]interface{}{"location": location},
+	}
+}
+
+func TestListComments(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/content/12345/child/comment" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("depth") != "all" {
+			t.Errorf("depth = %q, want all", r.URL.Query().Get("depth"))
+		}
+
+		inline := commentJSON("2", "<p>Is this number right?</p>", "inline")
+		inline["extensions"] = map[string]interface{}{
+			"location":         "inline",
+			"inlineProperties": map[string]interface{}{"originalSelection": "99.9%"},
+			"resolution":       map[string]interface{}{"status": "open"},
+		}
+		// Replies arrive on a later page than their parents, after a page
+		// shorter than the requested size.
+		requests++
+		results := []interface{}{
+			commentJSON("1", "<p>Looks good.</p>", "footer"),
+			inline,
+			commentJSON("3", "<p>Thanks!</p>", "footer", "1"),
+		}
+		links := map[string]interface{}{"next": "/rest/api/content/12345/child/comment?start=3"}
+		if r.URL.Query().Get("start") != "0" {
+			results = []interface{}{
+				commentJSON("4", "<p>It is.</p>", "inline", "2"),
+				commentJSON("5", "<p>Great.</p>", "footer", "1", "3"),
+			}
+			links = map[string]interface{}{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]interface{}{"results": results, "_links": links}); err != nil {
+			panic(err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	threads, err := client.ListComments(context.Background(), "12345")
+	if err != nil {
+		t.Fatalf("ListComments() error = %v", err)
+	}
+	if len(threads) != 2 || requests != 2 {
+		t.Fatalf("ListComments() = %d threads in %d requests, want 2 in 2", len(threads), requests)
+	}
+
+	footer := threads[0]
+	if footer.ID != "1" || footer.Location != CommentFooter || footer.Author != "Ana" {
+		t.Errorf("threads[0] = %+v", footer)
+	}
+	if storage.RenderText(footer.Body) != "Looks good.\n" {
+		t.Errorf("threads[0] body = %q", storage.RenderText(footer.Body))
+	}
+	if len(footer.Replies) != 1 || footer.Replies[0].ID != "3" || len(footer.Replies[0].Replies) != 1 {
+		t.Errorf("threads[0] replies = %+v", footer.Replies)
+	}
+
+	inlineThread := threads[1]
+	if inlineThread.Location != CommentInline || inlineThread.Selection != "99.9%" || inlineThread.Resolution != "open" {
+		t.Errorf("threads[1] = %+v", inlineThread)
+	}
+	if len(inlineThread.Replies) != 1 || inlineThread.Replies[0].ParentID != "2" {
+		t.Errorf("threads[1] replies = %+v", inlineThread.Replies)
+	}
+}
+
+func TestReplyToComment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/rest/api/content" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		var payload struct {
+			Type      string              `json:"type"`
+			Container map[string]string   `json:"container"`
+			Ancestors []map[string]string `json:"ancestors"`
+			Body      struct {
+				Storage struct {
+					Value string `json:"value"`
+				} `json:"storage"`
+			} `json:"body"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			panic(err)
+		}
+		if payload.Type != "comment" || payload.Container["id"] != "12345" {
+			t.Errorf("Unexpected payload: %+v", payload)
+		}
+		if len(payload.Ancestors) != 1 || payload.Ancestors[0]["id"] != "1" {
+			t.Errorf("ancestors = %v", payload.Ancestors)
+		}
+		if !strings.Contains(payload.Body.Storage.Value, "Fixed in v2.") {
+			t.Errorf("body = %q", payload.Body.Storage.Value)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(commentJSON("7", payload.Body.Storage.Value, "footer")); err != nil {
+			panic(err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	body := &storage.Page{Blocks: []storage.Block{&storage.Paragraph{Text: "Fixed in v2."}}}
+	comment, err := client.ReplyToComment(context.Background(), "12345", "1", body)
+	if err != nil {
+		t.Fatalf("ReplyToComment() error = %v", err)
+	}
+	if comment.ID != "7" || comment.ParentID != "1" {
+		t.Errorf("ReplyToComment() = %+v", comment)
+	}
+}
+
+func TestResolveComment(t *testing.T) {
+	var update map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/inline-comments/2" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		if r.Method == "PUT" {
+			if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+				panic(err)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":      "2",
+			"version": map[string]interface{}{"number": 3},
+			"body":    map[string]interface{}{"storage": map[string]interface{}{"value": "<p>Typo</p>"}},
+		}); err != nil {
+			panic(err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	if err := client.ResolveComment(context.Background(), "2"); err != nil {
+		t.Fatalf("ResolveComment() error = %v", err)
+	}
+	if update["resolved"] != true || update["version"].(map[string]interface{})["number"] != float64(4) {
+		t.Errorf("update = %v", update)
+	}
+	if update["body"].(map[string]interface{})["value"] != "<p>Typo</p>" {
+		t.Errorf("update body = %v", update["body"])
+	}
+}
+
+func TestDeleteComment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" || r.URL.Path != "/rest/api/content/7" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	if err := client.DeleteComment(context.Background(), "7"); err != nil {
+		t.Errorf("DeleteComment() error = %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// labelPageSize is the number of labels requested per page of results.
+const labelPageSize = 200
+
+// Label is a label on a page. Prefix is "global" for ordinary labels and
+// "my" for personal ones.
+type Label struct {
+	ID     string `json:"id,omitempty"`
+	Prefix string `json:"prefix"`
+	Name   string `json:"name"`
+}
+
+// GetLabels lists the labels of a page.
+func (c *Client) GetLabels(ctx context.Context, pageID string) ([]Label, error) {
+	labels := []Label{}
+	for start := 0; ; {
+		var result struct {
+			Results []Label `json:"results"`
+			listLinks
+		}
+		path := fmt.Sprintf("/rest/api/content/%s/label?start=%d&limit=%d", pageID, start, labelPageSize)
+		if err := c.doJSON(ctx, "GET", path, nil, &result, "failed to get labels"); err != nil {
+			return nil, err
+		}
+		labels = append(labels, result.Results...)
+		if !result.hasNext() || len(result.Results) == 0 {
+			return labels, nil
+		}
+		start += len(result.Results)
+	}
+}
+
+// AddLabels adds global labels to a page and returns the page's labels.
+// Labels the page already has are left alone. Confluence stores label names
+// in lower case; names must not contain whitespace.
+func (c *Client) AddLabels(ctx context.Context, pageID string, names ...string) ([]Label, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no labels given")
+	}
+	payload := make([]Label, len(names))
+	for i, name := range names {
+		if name == "" || strings.ContainsAny(name, " \t\r\n") {
+			return nil, fmt.Errorf("invalid label %q: labels must be non-empty and contain no whitespace", name)
+		}
+		payload[i] = Label{Prefix: "global", Name: name}
+	}
+
+	var result struct {
+		Results []Label `json:"results"`
+	}
+	path := fmt.Sprintf("/rest/api/content/%s/label", pageID)
+	if err := c.doJSON(ctx, "POST", path, payload, &result, "failed to add labels"); err != nil {
+		return nil, err
+	}
+	return result.Results, nil
+}
+
+// RemoveLabel removes a label from a page.
+func (c *Client) RemoveLabel(ctx context.Context, pageID, name string) error {
+	path := fmt.Sprintf("/rest/api/content/%s/label?name=%s", pageID, url.QueryEscape(name))
+	return c.doJSON(ctx, "DELETE", path, nil, nil, "failed to remove label")
+}
+
+// SearchPagesByLabel searches for pages carrying all of labels, in the given
+// space or, if spaceKey is empty, in all spaces.
+func (c *Client) SearchPagesByLabel(ctx context.Context, spaceKey string, labels []string, limit int) ([]PageInfo, error) {
+	if len(labels) == 0 {
+		return nil, fmt.Errorf("no labels given")
+	}
+	return c.SearchPages(ctx, LabelCQL(spaceKey, labels...), limit)
+}
+
+// LabelCQL returns a CQL query for pages carrying all of labels, limited to
+// a space unless spaceKey is empty.
+func LabelCQL(spaceKey string, labels ...string) string {
+	clauses := []string{"type = page"}
+	if spaceKey != "" {
+		clauses = append(clauses, "space = "+cqlQuote(spaceKey))
+	}
+	for _, label := range labels {
+		clauses = append(clauses, "label = "+cqlQuote(label))
+	}
+	return strings.Join(clauses, " AND ")
+}
+
+// cqlQuote returns s as a double-quoted CQL string.
+func cqlQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
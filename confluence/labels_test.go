@@ -0,0 +1,152 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetLabels(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/rest/api/content/12345/label" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+
+		// Serve fewer labels than requested while more remain, as
+		// Confluence does when it caps the page size, then the last one.
+		if r.URL.Query().Get("limit") != fmt.Sprint(labelPageSize) {
+			t.Errorf("limit = %s", r.URL.Query().Get("limit"))
+		}
+		count, links := 3, map[string]interface{}{"next": "/rest/api/content/12345/label?start=3"}
+		if r.URL.Query().Get("start") != "0" {
+			count, links = 1, map[string]interface{}{}
+		}
+		results := make([]Label, count)
+		for i := range results {
+			results[i] = Label{ID: fmt.Sprint(i), Prefix: "global", Name: fmt.Sprintf("label-%d", i)}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]interface{}{"results": results, "_links": links}); err != nil {
+			panic(err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	labels, err := client.GetLabels(context.Background(), "12345")
+	if err != nil {
+		t.Fatalf("GetLabels() error = %v", err)
+	}
+	if len(labels) != 4 || requests != 2 {
+		t.Errorf("GetLabels() = %d labels in %d requests", len(labels), requests)
+	}
+	if labels[0].Name != "label-0" || labels[0].Prefix != "global" {
+		t.Errorf("GetLabels()[0] = %+v", labels[0])
+	}
+}
+
+func TestAddLabels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("Expected POST request, got %s", r.Method)
+		}
+		var payload []Label
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			panic(err)
+		}
+		if len(payload) != 2 || payload[0].Prefix != "global" || payload[1].Name != "lifecycle-active" {
+			t.Errorf("Unexpected payload: %+v", payload)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]interface{}{"results": append(payload, Label{Prefix: "global", Name: "existing"})}); err != nil {
+			panic(err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	labels, err := client.AddLabels(context.Background(), "12345", "team-platform", "lifecycle-active")
+	if err != nil {
+		t.Fatalf("AddLabels() error = %v", err)
+	}
+	if len(labels) != 3 {
+		t.Errorf("AddLabels() = %+v", labels)
+	}
+
+	if _, err := client.AddLabels(context.Background(), "12345", "two words"); err == nil {
+		t.Error("AddLabels() should reject labels with spaces")
+	}
+}
+
+func TestRemoveLabel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" {
+			t.Errorf("Expected DELETE request, got %s", r.Method)
+		}
+		if r.URL.Query().Get("name") != "team-platform" {
+			t.Errorf("Unexpected name: %s", r.URL.Query().Get("name"))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	if err := client.RemoveLabel(context.Background(), "12345", "team-platform"); err != nil {
+		t.Fatalf("RemoveLabel() error = %v", err)
+	}
+}
+
+func TestRemoveLabel_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"No content found"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	err := client.RemoveLabel(context.Background(), "12345", "missing")
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 404 || apiErr.Message != "failed to remove label" {
+		t.Errorf("RemoveLabel() error = %v", err)
+	}
+}
+
+func TestSearchPagesByLabel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		want := `type = page AND space = "ENG" AND label = "team-platform" AND label = "lifecycle-active"`
+		if got := r.URL.Query().Get("cql"); got != want {
+			t.Errorf("cql = %s, want %s", got, want)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]interface{}{
+			"results": []map[string]string{{"id": "1", "type": "page", "title": "Runbook"}},
+		}); err != nil {
+			panic(err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	pages, err := client.SearchPagesByLabel(context.Background(), "ENG", []string{"team-platform", "lifecycle-active"}, 25)
+	if err != nil {
+		t.Fatalf("SearchPagesByLabel() error = %v", err)
+	}
+	if len(pages) != 1 || pages[0].Title != "Runbook" {
+		t.Errorf("SearchPagesByLabel() = %+v", pages)
+	}
+}
+
+func TestLabelCQL(t *testing.T) {
+	if got := LabelCQL("", `a"b`); got != `type = page AND label = "a\"b"` {
+		t.Errorf("LabelCQL() = %s", got)
+	}
+}
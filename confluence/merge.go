@@ -0,0 +1,101 @@
+package confluence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/agentplexus/mcp-confluence/storage"
+)
+
+// MergeConflictError is returned by UpdatePageStorageFromBase when the page
+// changed since the base version in ways that overlap with the update, or
+// when the current version cannot be merged safely at all.
+type MergeConflictError struct {
+	BaseVersion    int
+	CurrentVersion int
+	Conflicts      []storage.Conflict
+	// Unmergeable is set when the current version contains markup the
+	// block model does not preserve, so that merging would silently drop
+	// it. Conflicts is empty in that case.
+	Unmergeable *storage.LossError
+}
+
+func (e *MergeConflictError) Error() string {
+	if e.Unmergeable != nil {
+		return fmt.Sprintf("merge conflict: page changed from version %d to %d and the current version cannot be merged: %v",
+			e.BaseVersion, e.CurrentVersion, e.Unmergeable)
+	}
+	return fmt.Sprintf("merge conflict: page changed from version %d to %d with %d conflicting region(s)",
+		e.BaseVersion, e.CurrentVersion, len(e.Conflicts))
+}
+
+// UpdateResult describes a published update.
+type UpdateResult struct {
+	// Version is the page's new version number.
+	Version int
+	// Merged reports whether concurrent changes were merged into the update.
+	Merged bool
+	// Page is the content that was published.
+	Page *storage.Page
+}
+
+// UpdatePageStorageFromBase publishes page as an edit of baseVersion, the
+// version the caller originally read. If the page is still at baseVersion
+// the update is applied directly. Otherwise the base is fetched from the
+// page history and merged three-way with the current content and page; the
+// merged result is published unless the changes conflict, in which case a
+// *MergeConflictError is returned and nothing is written. A current version
+// that does not survive storage.CheckRoundTrip is reported as a conflict
+// too, since the concurrent edits in it could be lost in the merge.
+func (c *Client) UpdatePageStorageFromBase(ctx context.Context, pageID string, page *storage.Page, baseVersion int, title string, opts ...UpdateOption) (*UpdateResult, error) {
+	xhtml, info, err := c.GetPageStorageRaw(ctx, pageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current version: %w", err)
+	}
+
+	if info.Version < baseVersion {
+		return nil, fmt.Errorf("base version %d is newer than current version %d", baseVersion, info.Version)
+	}
+
+	if info.Version == baseVersion {
+		if err := c.UpdatePageStorage(ctx, pageID, page, info.Version, title, opts...); err != nil {
+			return nil, err
+		}
+		return &UpdateResult{Version: info.Version + 1, Page: page}, nil
+	}
+
+	var lossErr *storage.LossError
+	if err := storage.CheckRoundTrip(xhtml); errors.As(err, &lossErr) {
+		return nil, &MergeConflictError{
+			BaseVersion:    baseVersion,
+			CurrentVersion: info.Version,
+			Unmergeable:    lossErr,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	current, err := storage.Parse(xhtml)
+	if err != nil {
+		return nil, err
+	}
+
+	base, _, err := c.GetPageVersion(ctx, pageID, baseVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get base version %d: %w", baseVersion, err)
+	}
+
+	merged := storage.Merge(base, current, page)
+	if len(merged.Conflicts) > 0 {
+		return nil, &MergeConflictError{
+			BaseVersion:    baseVersion,
+			CurrentVersion: info.Version,
+			Conflicts:      merged.Conflicts,
+		}
+	}
+
+	if err := c.UpdatePageStorage(ctx, pageID, merged.Page, info.Version, title, opts...); err != nil {
+		return nil, err
+	}
+	return &UpdateResult{Version: info.Version + 1, Merged: true, Page: merged.Page}, nil
+}
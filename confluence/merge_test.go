@@ -0,0 +1,181 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/agentplexus/mcp-confluence/storage"
+)
+
+// versionedServer serves a page whose current and historical versions are
+// given as Storage XHTML, and records the XHTML of the last update.
+func versionedServer(t *testing.T, versions map[int]string, current int, published *string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			version := current
+			if r.URL.Query().Get("status") == "historical" {
+				v, err := strconv.Atoi(r.URL.Query().Get("version"))
+				if err != nil {
+					t.Errorf("invalid version query: %v", err)
+				}
+				version = v
+			}
+			response := map[string]interface{}{
+				"id":    "12345",
+				"title": "Page",
+				"body": map[string]interface{}{
+					"storage": map[string]string{"value": versions[version]},
+				},
+				"version": map[string]int{"number": version},
+			}
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(response); err != nil {
+				panic(err)
+			}
+		case "PUT":
+			var payload struct {
+				Body struct {
+					Storage struct {
+						Value string `json:"value"`
+					} `json:"storage"`
+				} `json:"body"`
+				Version struct {
+					Number int `json:"number"`
+				} `json:"version"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				panic(err)
+			}
+			if payload.Version.Number != current+1 {
+				t.Errorf("Update version = %d, want %d", payload.Version.Number, current+1)
+			}
+			*published = payload.Body.Storage.Value
+			w.Header().Set("Content-Type", "application/json")
+			if _, err := w.Write([]byte(`{"id": "12345"}`)); err != nil {
+				panic(err)
+			}
+		}
+	}))
+}
+
+func TestUpdatePageStorageFromBase(t *testing.T) {
+	tests := []struct {
+		name       string
+		versions   map[int]string
+		current    int
+		ours       []string
+		wantXHTML  string
+		wantMerged bool
+	}{
+		{
+			name:      "unchanged since base",
+			versions:  map[int]string{5: "<p>A</p><p>B</p>"},
+			current:   5,
+			ours:      []string{"A", "B2"},
+			wantXHTML: "<p>A</p><p>B2</p>",
+		},
+		{
+			name: "disjoint concurrent edit",
+			versions: map[int]string{
+				5: "<p>A</p><p>B</p><p>C</p>",
+				6: "<p>A2</p><p>B</p><p>C</p>",
+			},
+			current:    6,
+			ours:       []string{"A", "B", "C2"},
+			wantXHTML:  "<p>A2</p><p>B</p><p>C2</p>",
+			wantMerged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var published string
+			server := versionedServer(t, tt.versions, tt.current, &published)
+			defer server.Close()
+
+			page := &storage.Page{}
+			for _, text := range tt.ours {
+				page.Blocks = append(page.Blocks, &storage.Paragraph{Text: text})
+			}
+
+			client := NewClient(server.URL, BasicAuth{Username: "user", Token: "token"})
+			result, err := client.UpdatePageStorageFromBase(context.Background(), "12345", page, 5, "Page")
+			if err != nil {
+				t.Fatalf("UpdatePageStorageFromBase() error = %v", err)
+			}
+			if result.Version != tt.current+1 {
+				t.Errorf("UpdatePageStorageFromBase() version = %d, want %d", result.Version, tt.current+1)
+			}
+			if result.Merged != tt.wantMerged {
+				t.Errorf("UpdatePageStorageFromBase() merged = %v, want %v", result.Merged, tt.wantMerged)
+			}
+			if published != tt.wantXHTML {
+				t.Errorf("published XHTML = %q, want %q", published, tt.wantXHTML)
+			}
+		})
+	}
+}
+
+func TestUpdatePageStorageFromBase_Conflict(t *testing.T) {
+	var published string
+	server := versionedServer(t, map[int]string{
+		5: "<p>A</p><p>B</p>",
+		6: "<p>A</p><p>B-theirs</p>",
+	}, 6, &published)
+	defer server.Close()
+
+	page := &storage.Page{Blocks: []storage.Block{
+		&storage.Paragraph{Text: "A"},
+		&storage.Paragraph{Text: "B-ours"},
+	}}
+
+	client := NewClient(server.URL, BasicAuth{Username: "user", Token: "token"})
+	_, err := client.UpdatePageStorageFromBase(context.Background(), "12345", page, 5, "Page")
+
+	var conflictErr *MergeConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("UpdatePageStorageFromBase() error = %v, want *MergeConflictError", err)
+	}
+	if conflictErr.BaseVersion != 5 || conflictErr.CurrentVersion != 6 {
+		t.Errorf("MergeConflictError versions = %d..%d, want 5..6", conflictErr.BaseVersion, conflictErr.CurrentVersion)
+	}
+	if len(conflictErr.Conflicts) != 1 {
+		t.Errorf("MergeConflictError conflicts = %d, want 1", len(conflictErr.Conflicts))
+	}
+	if published != "" {
+		t.Errorf("page was published despite conflict: %q", published)
+	}
+}
+
+func TestUpdatePageStorageFromBase_Unmergeable(t *testing.T) {
+	var published string
+	server := versionedServer(t, map[int]string{
+		5: "<p>A</p><p>B</p>",
+		6: `<p>A</p><p>B <ac:emoticon ac:name="smile" /></p>`,
+	}, 6, &published)
+	defer server.Close()
+
+	// Our edit doesn't overlap theirs, but merging would drop the emoticon.
+	page := &storage.Page{Blocks: []storage.Block{
+		&storage.Paragraph{Text: "A-ours"},
+		&storage.Paragraph{Text: "B"},
+	}}
+
+	client := NewClient(server.URL, BasicAuth{Username: "user", Token: "token"})
+	_, err := client.UpdatePageStorageFromBase(context.Background(), "12345", page, 5, "Page")
+
+	var conflictErr *MergeConflictError
+	if !errors.As(err, &conflictErr) || conflictErr.Unmergeable == nil {
+		t.Fatalf("UpdatePageStorageFromBase() error = %v, want unmergeable *MergeConflictError", err)
+	}
+	if published != "" {
+		t.Errorf("page was published despite unmergeable content: %q", published)
+	}
+}
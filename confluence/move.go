@@ -0,0 +1,128 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+)
+
+// Positions for MovePage, relative to the target page.
+const (
+	MoveAppend = "append" // last child of the target
+	MoveBefore = "before" // sibling before the target
+	MoveAfter  = "after"  // sibling after the target
+)
+
+// MovePage moves a page, with its descendants, to position relative to
+// the target page.
+func (c *Client) MovePage(ctx context.Context, pageID, position, targetID string) error {
+	switch position {
+	case MoveAppend, MoveBefore, MoveAfter:
+	default:
+		return fmt.Errorf("invalid position %q: want append, before or after", position)
+	}
+	path := fmt.Sprintf("/rest/api/content/%s/move/%s/%s", pageID, position, targetID)
+	return c.doJSON(ctx, "PUT", path, nil, nil, "failed to move page")
+}
+
+// CopyOptions configures CopyPage and CopyPageTree.
+type CopyOptions struct {
+	// Title is the title of the copy. If empty, the copy is titled
+	// TitlePrefix + original title + TitleSuffix. CopyPageTree applies
+	// Title only to the root page.
+	Title       string
+	TitlePrefix string
+	TitleSuffix string
+
+	Attachments bool // copy attachments
+	Labels      bool // copy labels
+}
+
+// CopyPage copies a page, without its descendants, as a child of parentID.
+// Copies need a title that is unique in the destination space. Copying
+// requires Confluence Cloud.
+func (c *Client) CopyPage(ctx context.Context, pageID, parentID string, opts CopyOptions) (*PageInfo, error) {
+	title := opts.Title
+	if title == "" && (opts.TitlePrefix != "" || opts.TitleSuffix != "") {
+		info, err := c.GetPageInfo(ctx, pageID)
+		if err != nil {
+			return nil, err
+		}
+		title = opts.TitlePrefix + info.Title + opts.TitleSuffix
+	}
+	return c.copyPage(ctx, pageID, parentID, title, opts)
+}
+
+func (c *Client) copyPage(ctx context.Context, pageID, parentID, title string, opts CopyOptions) (*PageInfo, error) {
+	payload := map[string]interface{}{
+		"copyAttachments": opts.Attachments,
+		"copyLabels":      opts.Labels,
+		"copyPermissions": false,
+		"copyProperties":  false,
+		"destination":     map[string]string{"type": "parent_page", "value": parentID},
+	}
+	if title != "" {
+		payload["pageTitle"] = title
+	}
+
+	var result pageResult
+	path := fmt.Sprintf("/rest/api/content/%s/copy?expand=version,space", pageID)
+	if err := c.doJSON(ctx, "POST", path, payload, &result, "failed to copy page"); err != nil {
+		return nil, err
+	}
+	info := result.info()
+	return &info, nil
+}
+
+// CopyPageTree copies a page and all its descendants as a child of
+// parentID, keeping their order, and returns the tree of copies. Titles of
+// descendants are rewritten with TitlePrefix and TitleSuffix. If a copy
+// fails, the pages copied so far are kept and returned with the error.
+func (c *Client) CopyPageTree(ctx context.Context, rootID, parentID string, opts CopyOptions) (*PageNode, error) {
+	info, err := c.GetPageInfo(ctx, rootID)
+	if err != nil {
+		return nil, err
+	}
+	title := opts.Title
+	if title == "" {
+		title = opts.TitlePrefix + info.Title + opts.TitleSuffix
+	}
+	t := &treeCopier{client: c, opts: opts, copied: map[string]bool{}}
+	return t.copy(ctx, rootID, parentID, title)
+}
+
+// treeCopier copies a page tree, tracking the copies it makes so that a
+// tree copied into itself is not copied again.
+type treeCopier struct {
+	client *Client
+	opts   CopyOptions
+	copied map[string]bool
+}
+
+func (t *treeCopier) copy(ctx context.Context, pageID, parentID, title string) (*PageNode, error) {
+	// List the children first: if the tree is copied into itself, the new
+	// copy becomes one of them.
+	children, err := t.client.GetChildren(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+	info, err := t.client.copyPage(ctx, pageID, parentID, title, t.opts)
+	if err != nil {
+		return nil, err
+	}
+	t.copied[info.ID] = true
+
+	node := &PageNode{PageInfo: *info}
+	for _, child := range children {
+		if t.copied[child.ID] {
+			continue
+		}
+		childNode, err := t.copy(ctx, child.ID, info.ID, t.opts.TitlePrefix+child.Title+t.opts.TitleSuffix)
+		if childNode != nil {
+			node.Children = append(node.Children, childNode)
+		}
+		if err != nil {
+			return node, err
+		}
+	}
+	return node, nil
+}
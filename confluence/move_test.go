@@ -0,0 +1,133 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMovePage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/rest/api/content/12345/move/before/678" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"pageId": "12345"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	if err := client.MovePage(context.Background(), "12345", MoveBefore, "678"); err != nil {
+		t.Errorf("MovePage() error = %v", err)
+	}
+	if err := client.MovePage(context.Background(), "12345", "below", "678"); err == nil {
+		t.Error("MovePage() with invalid position should fail")
+	}
+}
+
+// copyServer serves a page tree and copies pages within it. Pages 1 (Team),
+// 2 (Onboarding) and 3 (Tools) form the tree 1 > 2 > 3.
+type copyServer struct {
+	t        *testing.T
+	titles   map[string]string
+	children map[string][]string
+	payloads []map[string]interface{}
+	nextID   int
+}
+
+func newCopyServer(t *testing.T) *copyServer {
+	return &copyServer{
+		t:        t,
+		titles:   map[string]string{"1": "Team", "2": "Onboarding", "3": "Tools"},
+		children: map[string][]string{"1": {"2"}, "2": {"3"}},
+		nextID:   100,
+	}
+}
+
+func (s *copyServer) page(id string) map[string]interface{} {
+	return map[string]interface{}{"id": id, "type": "page", "title": s.titles[id], "space": map[string]string{"key": "TEAM"}}
+}
+
+func (s *copyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/rest/api/content/"), "/")
+	var out interface{}
+	switch {
+	case len(parts) == 2 && parts[1] == "copy":
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			panic(err)
+		}
+		s.payloads = append(s.payloads, payload)
+		id := fmt.Sprint(s.nextID)
+		s.nextID++
+		s.titles[id], _ = payload["pageTitle"].(string)
+		parent := payload["destination"].(map[string]interface{})["value"].(string)
+		s.children[parent] = append(s.children[parent], id)
+		out = s.page(id)
+	case len(parts) == 3 && parts[1] == "child":
+		results := []interface{}{}
+		for _, id := range s.children[parts[0]] {
+			results = append(results, s.page(id))
+		}
+		out = map[string]interface{}{"results": results}
+	case len(parts) == 1:
+		out = s.page(parts[0])
+	default:
+		s.t.Errorf("Unexpected path: %s", r.URL.Path)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		panic(err)
+	}
+}
+
+func TestCopyPage(t *testing.T) {
+	cs := newCopyServer(t)
+	server := httptest.NewServer(cs)
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	info, err := client.CopyPage(context.Background(), "2", "1", CopyOptions{TitlePrefix: "[Draft] ", Labels: true})
+	if err != nil {
+		t.Fatalf("CopyPage() error = %v", err)
+	}
+	if info.ID != "100" || info.Title != "[Draft] Onboarding" {
+		t.Errorf("CopyPage() = %+v", info)
+	}
+	payload := cs.payloads[0]
+	if payload["copyLabels"] != true || payload["copyAttachments"] != false {
+		t.Errorf("payload = %v", payload)
+	}
+	if dest := payload["destination"].(map[string]interface{}); dest["type"] != "parent_page" || dest["value"] != "1" {
+		t.Errorf("destination = %v", dest)
+	}
+}
+
+func TestCopyPageTree(t *testing.T) {
+	cs := newCopyServer(t)
+	server := httptest.NewServer(cs)
+	defer server.Close()
+
+	// Copy the tree into itself, below its own root.
+	client := NewClient(server.URL, BasicAuth{})
+	root, err := client.CopyPageTree(context.Background(), "1", "1", CopyOptions{Title: "Team 2025", TitleSuffix: " (2025)"})
+	if err != nil {
+		t.Fatalf("CopyPageTree() error = %v", err)
+	}
+	if root.Title != "Team 2025" || root.Count() != 3 {
+		t.Fatalf("CopyPageTree() = %+v, want 3 copies", root)
+	}
+	if got := root.Children[0].Title; got != "Onboarding (2025)" {
+		t.Errorf("child title = %q", got)
+	}
+	if got := root.Children[0].Children[0].Title; got != "Tools (2025)" {
+		t.Errorf("grandchild title = %q", got)
+	}
+	if len(cs.payloads) != 3 {
+		t.Errorf("made %d copies, want 3", len(cs.payloads))
+	}
+}
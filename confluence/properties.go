@@ -0,0 +1,147 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// propertyPageSize is the number of properties requested per page of results.
+const propertyPageSize = 100
+
+// ContentProperty is a JSON value stored on a page under a key. Properties
+// hold machine-readable metadata, such as a page's owner or review date,
+// without touching the page body. Version starts at 1 and increases with
+// each update.
+type ContentProperty struct {
+	Key     string          `json:"key"`
+	Value   json.RawMessage `json:"value"`
+	Version int             `json:"version"`
+}
+
+// propertyResult is a content property as returned by the REST API.
+type propertyResult struct {
+	Key     string          `json:"key"`
+	Value   json.RawMessage `json:"value"`
+	Version struct {
+		Number int `json:"number"`
+	} `json:"version"`
+}
+
+func (r propertyResult) property() ContentProperty {
+	return ContentProperty{Key: r.Key, Value: r.Value, Version: r.Version.Number}
+}
+
+// ListProperties lists the content properties of a page.
+func (c *Client) ListProperties(ctx context.Context, pageID string) ([]ContentProperty, error) {
+	properties := []ContentProperty{}
+	for start := 0; ; {
+		var result struct {
+			Results []propertyResult `json:"results"`
+			listLinks
+		}
+		path := fmt.Sprintf("/rest/api/content/%s/property?expand=version&start=%d&limit=%d", pageID, start, propertyPageSize)
+		if err := c.doJSON(ctx, "GET", path, nil, &result, "failed to list properties"); err != nil {
+			return nil, err
+		}
+		for _, r := range result.Results {
+			properties = append(properties, r.property())
+		}
+		if !result.hasNext() || len(result.Results) == 0 {
+			return properties, nil
+		}
+		start += len(result.Results)
+	}
+}
+
+// GetProperty returns a page's content property. A missing property is
+// reported as an *APIError with status 404.
+func (c *Client) GetProperty(ctx context.Context, pageID, key string) (*ContentProperty, error) {
+	var result propertyResult
+	if err := c.doJSON(ctx, "GET", propertyPath(pageID, key)+"?expand=version", nil, &result, "failed to get property"); err != nil {
+		return nil, err
+	}
+	property := result.property()
+	return &property, nil
+}
+
+// GetPropertyValue decodes a page's content property into v, as
+// json.Unmarshal does, and returns the property's version.
+func (c *Client) GetPropertyValue(ctx context.Context, pageID, key string, v interface{}) (int, error) {
+	property, err := c.GetProperty(ctx, pageID, key)
+	if err != nil {
+		return 0, err
+	}
+	if err := json.Unmarshal(property.Value, v); err != nil {
+		return 0, fmt.Errorf("decode property %q: %w", key, err)
+	}
+	return property.Version, nil
+}
+
+// SetProperty stores value, encoded as JSON, as a page's content property
+// and returns the stored property.
+//
+// version is the version of the property the caller last read. If it is
+// positive, the update fails with an *APIError with status 409 when the
+// property has changed since. If it is zero, the property is created or
+// overwritten whatever its current version.
+func (c *Client) SetProperty(ctx context.Context, pageID, key string, value interface{}, version int) (*ContentProperty, error) {
+	if key == "" {
+		return nil, fmt.Errorf("property key is required")
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("encode property %q: %w", key, err)
+	}
+
+	if version == 0 {
+		current, err := c.GetProperty(ctx, pageID, key)
+		var apiErr *APIError
+		switch {
+		case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+			return c.createProperty(ctx, pageID, key, data)
+		case err != nil:
+			return nil, err
+		}
+		version = current.Version
+	}
+
+	payload := map[string]interface{}{
+		"key":   key,
+		"value": json.RawMessage(data),
+		"version": map[string]interface{}{
+			"number":    version + 1,
+			"minorEdit": true,
+		},
+	}
+	var result propertyResult
+	if err := c.doJSON(ctx, "PUT", propertyPath(pageID, key), payload, &result, "failed to update property"); err != nil {
+		return nil, err
+	}
+	property := result.property()
+	return &property, nil
+}
+
+func (c *Client) createProperty(ctx context.Context, pageID, key string, value json.RawMessage) (*ContentProperty, error) {
+	payload := map[string]interface{}{"key": key, "value": value}
+	var result propertyResult
+	path := fmt.Sprintf("/rest/api/content/%s/property", pageID)
+	if err := c.doJSON(ctx, "POST", path, payload, &result, "failed to create property"); err != nil {
+		return nil, err
+	}
+	property := result.property()
+	return &property, nil
+}
+
+// DeleteProperty deletes a page's content property.
+func (c *Client) DeleteProperty(ctx context.Context, pageID, key string) error {
+	return c.doJSON(ctx, "DELETE", propertyPath(pageID, key), nil, nil, "failed to delete property")
+}
+
+// propertyPath returns the API path of a page's content property.
+func propertyPath(pageID, key string) string {
+	return fmt.Sprintf("/rest/api/content/%s/property/%s", pageID, url.PathEscape(key))
+}
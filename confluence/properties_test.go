@@ -0,0 +1,138 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// propertyServer stores the content properties of page 12345.
+type propertyServer struct {
+	t          *testing.T
+	properties map[string]propertyResult
+}
+
+func (s *propertyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	const prefix = "/rest/api/content/12345/property"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		s.t.Errorf("Unexpected path: %s", r.URL.Path)
+	}
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	current, exists := s.properties[key]
+
+	var out interface{}
+	switch {
+	case r.Method == "GET" && key == "":
+		// List one property per page, whatever the requested limit.
+		keys := make([]string, 0, len(s.properties))
+		for k := range s.properties {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		results, links := []propertyResult{}, map[string]interface{}{}
+		if start < len(keys) {
+			results = append(results, s.properties[keys[start]])
+		}
+		if start+1 < len(keys) {
+			links["next"] = prefix + "?start=" + strconv.Itoa(start+1)
+		}
+		out = map[string]interface{}{"results": results, "_links": links}
+	case r.Method == "GET" || r.Method == "DELETE":
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == "DELETE" {
+			delete(s.properties, key)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		out = current
+	default:
+		var payload propertyResult
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			panic(err)
+		}
+		if r.Method == "POST" {
+			payload.Version.Number = 1
+		} else if !exists || payload.Version.Number != current.Version.Number+1 {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		s.properties[payload.Key] = payload
+		out = payload
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		panic(err)
+	}
+}
+
+func TestContentProperties(t *testing.T) {
+	server := httptest.NewServer(&propertyServer{t: t, properties: map[string]propertyResult{}})
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	ctx := context.Background()
+
+	type owner struct {
+		Team   string `json:"team"`
+		Review string `json:"review"`
+	}
+	created, err := client.SetProperty(ctx, "12345", "page-owner", owner{Team: "payments", Review: "2025-01-01"}, 0)
+	if err != nil {
+		t.Fatalf("SetProperty() create error = %v", err)
+	}
+	if created.Version != 1 {
+		t.Errorf("created version = %d, want 1", created.Version)
+	}
+
+	updated, err := client.SetProperty(ctx, "12345", "page-owner", owner{Team: "payments", Review: "2025-07-01"}, 1)
+	if err != nil {
+		t.Fatalf("SetProperty() update error = %v", err)
+	}
+	if updated.Version != 2 {
+		t.Errorf("updated version = %d, want 2", updated.Version)
+	}
+
+	// A stale version is rejected, version 0 overwrites.
+	_, err = client.SetProperty(ctx, "12345", "page-owner", owner{Team: "ledger"}, 1)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict {
+		t.Errorf("SetProperty() with stale version error = %v, want 409", err)
+	}
+	if _, err := client.SetProperty(ctx, "12345", "page-owner", owner{Team: "ledger"}, 0); err != nil {
+		t.Fatalf("SetProperty() overwrite error = %v", err)
+	}
+
+	var got owner
+	version, err := client.GetPropertyValue(ctx, "12345", "page-owner", &got)
+	if err != nil {
+		t.Fatalf("GetPropertyValue() error = %v", err)
+	}
+	if got.Team != "ledger" || version != 3 {
+		t.Errorf("GetPropertyValue() = %+v, version %d", got, version)
+	}
+
+	if _, err := client.SetProperty(ctx, "12345", "review-state", "approved", 0); err != nil {
+		t.Fatalf("SetProperty() error = %v", err)
+	}
+	properties, err := client.ListProperties(ctx, "12345")
+	if err != nil || len(properties) != 2 || properties[0].Key != "page-owner" || properties[1].Key != "review-state" {
+		t.Errorf("ListProperties() = %+v, %v", properties, err)
+	}
+
+	if err := client.DeleteProperty(ctx, "12345", "page-owner"); err != nil {
+		t.Fatalf("DeleteProperty() error = %v", err)
+	}
+	if _, err := client.GetProperty(ctx, "12345", "page-owner"); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("GetProperty() after delete error = %v, want 404", err)
+	}
+}
@@ -0,0 +1,138 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+)
+
+// Operations that page restrictions apply to.
+const (
+	OperationRead   = "read"
+	OperationUpdate = "update"
+)
+
+// User is a Confluence Cloud user.
+type User struct {
+	AccountID   string `json:"accountId"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+// Restriction lists the users and groups an operation on a page is
+// restricted to. An empty Restriction leaves the operation unrestricted,
+// subject only to space permissions.
+type Restriction struct {
+	Users  []User   `json:"users"`
+	Groups []string `json:"groups"`
+}
+
+// Empty reports whether the restriction names no users or groups.
+func (r Restriction) Empty() bool {
+	return len(r.Users) == 0 && len(r.Groups) == 0
+}
+
+// Restrictions are the read and update restrictions of a page.
+type Restrictions struct {
+	Read   Restriction `json:"read"`
+	Update Restriction `json:"update"`
+}
+
+// restrictionResult is an operation's restrictions as returned by the REST
+// API.
+type restrictionResult struct {
+	Restrictions struct {
+		User struct {
+			Results []User `json:"results"`
+		} `json:"user"`
+		Group struct {
+			Results []struct {
+				Name string `json:"name"`
+			} `json:"results"`
+		} `json:"group"`
+	} `json:"restrictions"`
+}
+
+func (r restrictionResult) restriction() Restriction {
+	restriction := Restriction{Users: r.Restrictions.User.Results, Groups: []string{}}
+	if restriction.Users == nil {
+		restriction.Users = []User{}
+	}
+	for _, g := range r.Restrictions.Group.Results {
+		restriction.Groups = append(restriction.Groups, g.Name)
+	}
+	return restriction
+}
+
+// GetRestrictions returns the read and update restrictions set on a page
+// itself. Read restrictions inherited from ancestors are not included.
+func (c *Client) GetRestrictions(ctx context.Context, pageID string) (*Restrictions, error) {
+	var result map[string]restrictionResult
+	path := fmt.Sprintf("/rest/api/content/%s/restriction/byOperation?expand=restrictions.user,restrictions.group", pageID)
+	if err := c.doJSON(ctx, "GET", path, nil, &result, "failed to get restrictions"); err != nil {
+		return nil, err
+	}
+	return &Restrictions{
+		Read:   result[OperationRead].restriction(),
+		Update: result[OperationUpdate].restriction(),
+	}, nil
+}
+
+// SetRestrictions replaces the read and update restrictions of a page.
+// Users are identified by account ID, so this requires Confluence Cloud.
+// Confluence rejects restrictions that would lock out the current user.
+func (c *Client) SetRestrictions(ctx context.Context, pageID string, restrictions *Restrictions) error {
+	payload := []interface{}{
+		restrictionPayload(OperationRead, restrictions.Read),
+		restrictionPayload(OperationUpdate, restrictions.Update),
+	}
+	path := fmt.Sprintf("/rest/api/content/%s/restriction", pageID)
+	return c.doJSON(ctx, "PUT", path, payload, nil, "failed to set restrictions")
+}
+
+func restrictionPayload(operation string, r Restriction) map[string]interface{} {
+	users := make([]map[string]string, len(r.Users))
+	for i, u := range r.Users {
+		users[i] = map[string]string{"type": "known", "accountId": u.AccountID}
+	}
+	groups := make([]map[string]string, len(r.Groups))
+	for i, name := range r.Groups {
+		groups[i] = map[string]string{"type": "group", "name": name}
+	}
+	return map[string]interface{}{
+		"operation": operation,
+		"restrictions": map[string]interface{}{
+			"user":  users,
+			"group": groups,
+		},
+	}
+}
+
+// CurrentUser returns the user the client is authenticated as.
+func (c *Client) CurrentUser(ctx context.Context) (*User, error) {
+	var user User
+	if err := c.doJSON(ctx, "GET", "/rest/api/user/current", nil, &user, "failed to get current user"); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CanEdit reports whether the current user may edit a page, taking both
+// space permissions and page restrictions into account. It requires
+// Confluence Cloud.
+func (c *Client) CanEdit(ctx context.Context, pageID string) (bool, error) {
+	user, err := c.CurrentUser(ctx)
+	if err != nil {
+		return false, err
+	}
+	payload := map[string]interface{}{
+		"subject":   map[string]string{"type": "user", "identifier": user.AccountID},
+		"operation": OperationUpdate,
+	}
+	var result struct {
+		HasPermission bool `json:"hasPermission"`
+	}
+	path := fmt.Sprintf("/rest/api/content/%s/permission/check", pageID)
+	if err := c.doJSON(ctx, "POST", path, payload, &result, "failed to check permission"); err != nil {
+		return false, err
+	}
+	return result.HasPermission, nil
+}
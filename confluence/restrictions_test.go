@@ -0,0 +1,114 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetRestrictions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/content/12345/restriction/byOperation" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"read": {"operation": "read", "restrictions": {"user": {"results": []}, "group": {"results": []}}},
+			"update": {"operation": "update", "restrictions": {
+				"user": {"results": [{"type": "known", "accountId": "acc-1", "displayName": "Ada"}]},
+				"group": {"results": [{"type": "group", "name": "release-managers"}]}
+			}}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	restrictions, err := client.GetRestrictions(context.Background(), "12345")
+	if err != nil {
+		t.Fatalf("GetRestrictions() error = %v", err)
+	}
+	if !restrictions.Read.Empty() {
+		t.Errorf("Read = %+v, want empty", restrictions.Read)
+	}
+	update := restrictions.Update
+	if len(update.Users) != 1 || update.Users[0].AccountID != "acc-1" || update.Users[0].DisplayName != "Ada" {
+		t.Errorf("Update.Users = %+v", update.Users)
+	}
+	if len(update.Groups) != 1 || update.Groups[0] != "release-managers" {
+		t.Errorf("Update.Groups = %v", update.Groups)
+	}
+}
+
+func TestSetRestrictions(t *testing.T) {
+	var payload []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/rest/api/content/12345/restriction" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			panic(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	err := client.SetRestrictions(context.Background(), "12345", &Restrictions{
+		Update: Restriction{Users: []User{{AccountID: "acc-1"}}, Groups: []string{"release-managers"}},
+	})
+	if err != nil {
+		t.Fatalf("SetRestrictions() error = %v", err)
+	}
+	if len(payload) != 2 || payload[0]["operation"] != "read" || payload[1]["operation"] != "update" {
+		t.Fatalf("payload = %v", payload)
+	}
+	read := payload[0]["restrictions"].(map[string]interface{})
+	if users := read["user"].([]interface{}); len(users) != 0 {
+		t.Errorf("read users = %v, want none", users)
+	}
+	update := payload[1]["restrictions"].(map[string]interface{})
+	user := update["user"].([]interface{})[0].(map[string]interface{})
+	group := update["group"].([]interface{})[0].(map[string]interface{})
+	if user["accountId"] != "acc-1" || group["name"] != "release-managers" {
+		t.Errorf("update restrictions = %v", update)
+	}
+}
+
+func TestCanEdit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/rest/api/user/current":
+			_, _ = w.Write([]byte(`{"accountId": "acc-1", "displayName": "Ada"}`))
+		case "/rest/api/content/12345/permission/check":
+			var payload struct {
+				Subject struct {
+					Identifier string `json:"identifier"`
+				} `json:"subject"`
+				Operation string `json:"operation"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				panic(err)
+			}
+			if payload.Subject.Identifier != "acc-1" || payload.Operation != "update" {
+				t.Errorf("permission check payload = %+v", payload)
+			}
+			_, _ = w.Write([]byte(`{"hasPermission": false}`))
+		default:
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	canEdit, err := client.CanEdit(context.Background(), "12345")
+	if err != nil {
+		t.Fatalf("CanEdit() error = %v", err)
+	}
+	if canEdit {
+		t.Error("CanEdit() = true, want false")
+	}
+}
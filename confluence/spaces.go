@@ -0,0 +1,153 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// spacePageSize is the number of spaces requested per page of results.
+const spacePageSize = 100
+
+// spaceExpand is the expansion needed to fill in a SpaceInfo.
+const spaceExpand = "expand=description.plain,homepage"
+
+// SpaceInfo contains metadata about a Confluence space.
+type SpaceInfo struct {
+	ID          int    `json:"id"`
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Status      string `json:"status,omitempty"`
+	Description string `json:"description,omitempty"` // plain text
+	HomepageID  string `json:"homepageId,omitempty"`
+}
+
+// spaceResult is a space as returned by the REST API with spaceExpand.
+type spaceResult struct {
+	ID          int    `json:"id"`
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Status      string `json:"status"`
+	Description struct {
+		Plain struct {
+			Value string `json:"value"`
+		} `json:"plain"`
+	} `json:"description"`
+	Homepage struct {
+		ID string `json:"id"`
+	} `json:"homepage"`
+}
+
+func (r spaceResult) info() SpaceInfo {
+	return SpaceInfo{
+		ID:          r.ID,
+		Key:         r.Key,
+		Name:        r.Name,
+		Type:        r.Type,
+		Status:      r.Status,
+		Description: r.Description.Plain.Value,
+		HomepageID:  r.Homepage.ID,
+	}
+}
+
+// GetSpace retrieves information about a space.
+func (c *Client) GetSpace(ctx context.Context, spaceKey string) (*SpaceInfo, error) {
+	var result spaceResult
+	path := fmt.Sprintf("/rest/api/space/%s?%s", spaceKey, spaceExpand)
+	if err := c.doJSON(ctx, "GET", path, nil, &result, "failed to get space"); err != nil {
+		return nil, err
+	}
+	info := result.info()
+	return &info, nil
+}
+
+// SpaceFilter selects the spaces listed by ListSpaces. Empty fields match
+// all spaces.
+type SpaceFilter struct {
+	Type   string   // "global" or "personal"
+	Status string   // "current" or "archived"
+	Labels []string // spaces carrying any of these labels
+}
+
+func (f SpaceFilter) query() string {
+	q := url.Values{}
+	if f.Type != "" {
+		q.Set("type", f.Type)
+	}
+	if f.Status != "" {
+		q.Set("status", f.Status)
+	}
+	for _, label := range f.Labels {
+		q.Add("label", label)
+	}
+	if len(q) == 0 {
+		return ""
+	}
+	return q.Encode() + "&"
+}
+
+// ListSpaces lists the spaces matching filter, ordered by key. A limit of
+// zero or less lists them all.
+func (c *Client) ListSpaces(ctx context.Context, filter SpaceFilter, limit int) ([]SpaceInfo, error) {
+	spaces := []SpaceInfo{}
+	for start := 0; limit <= 0 || len(spaces) < limit; {
+		size := spacePageSize
+		if limit > 0 && limit-len(spaces) < size {
+			size = limit - len(spaces)
+		}
+		var result struct {
+			Results []spaceResult `json:"results"`
+			listLinks
+		}
+		path := fmt.Sprintf("/rest/api/space?%s%s&start=%d&limit=%d", filter.query(), spaceExpand, start, size)
+		if err := c.doJSON(ctx, "GET", path, nil, &result, "failed to list spaces"); err != nil {
+			return nil, err
+		}
+		for _, r := range result.Results {
+			spaces = append(spaces, r.info())
+		}
+		if !result.hasNext() || len(result.Results) == 0 {
+			break
+		}
+		start += len(result.Results)
+	}
+	return spaces, nil
+}
+
+// CreateSpace creates a global space with a plain-text description and
+// returns it. Confluence creates the space's homepage along with it.
+func (c *Client) CreateSpace(ctx context.Context, key, name, description string) (*SpaceInfo, error) {
+	if key == "" || name == "" {
+		return nil, fmt.Errorf("space key and name are required")
+	}
+	payload := map[string]interface{}{
+		"key":  key,
+		"name": name,
+	}
+	if description != "" {
+		payload["description"] = map[string]interface{}{
+			"plain": map[string]string{"value": description, "representation": "plain"},
+		}
+	}
+
+	var result spaceResult
+	if err := c.doJSON(ctx, "POST", "/rest/api/space?"+spaceExpand, payload, &result, "failed to create space"); err != nil {
+		return nil, err
+	}
+	info := result.info()
+	return &info, nil
+}
+
+// GetSpaceHomepage retrieves the homepage of a space.
+func (c *Client) GetSpaceHomepage(ctx context.Context, spaceKey string) (*PageInfo, error) {
+	space, err := c.GetSpace(ctx, spaceKey)
+	if err != nil {
+		return nil, err
+	}
+	if space.HomepageID == "" {
+		return nil, fmt.Errorf("space %s has no homepage", spaceKey)
+	}
+	return c.GetPageInfo(ctx, space.HomepageID)
+}
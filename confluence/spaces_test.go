@@ -0,0 +1,128 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// spaceJSON returns a space as the REST API returns it with spaceExpand.
+func spaceJSON(key string) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          1,
+		"key":         key,
+		"name":        key + " Space",
+		"type":        "global",
+		"status":      "current",
+		"description": map[string]interface{}{"plain": map[string]string{"value": "About " + key}},
+		"homepage":    map[string]string{"id": "98765"},
+	}
+}
+
+func TestListSpaces(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		q := r.URL.Query()
+		if r.URL.Path != "/rest/api/space" || q.Get("type") != "global" || len(q["label"]) != 2 {
+			t.Errorf("Unexpected request: %s", r.URL)
+		}
+
+		// Serve a short page that links to the next, then the last one.
+		count, links := 10, map[string]interface{}{"next": "/rest/api/space?start=10"}
+		if q.Get("start") != "0" {
+			count, links = 1, map[string]interface{}{}
+		}
+		results := make([]interface{}, count)
+		for i := range results {
+			results[i] = spaceJSON(fmt.Sprintf("S%d", i))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]interface{}{"results": results, "_links": links}); err != nil {
+			panic(err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	filter := SpaceFilter{Type: "global", Labels: []string{"team", "docs"}}
+	spaces, err := client.ListSpaces(context.Background(), filter, 0)
+	if err != nil {
+		t.Fatalf("ListSpaces() error = %v", err)
+	}
+	if len(spaces) != 11 || requests != 2 {
+		t.Errorf("ListSpaces() = %d spaces in %d requests", len(spaces), requests)
+	}
+	want := SpaceInfo{ID: 1, Key: "S0", Name: "S0 Space", Type: "global", Status: "current", Description: "About S0", HomepageID: "98765"}
+	if spaces[0] != want {
+		t.Errorf("ListSpaces()[0] = %+v, want %+v", spaces[0], want)
+	}
+}
+
+func TestCreateSpace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/rest/api/space" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		var payload struct {
+			Key         string `json:"key"`
+			Name        string `json:"name"`
+			Description struct {
+				Plain struct {
+					Value string `json:"value"`
+				} `json:"plain"`
+			} `json:"description"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			panic(err)
+		}
+		if payload.Key != "OPS" || payload.Description.Plain.Value != "About OPS" {
+			t.Errorf("payload = %+v", payload)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(spaceJSON(payload.Key)); err != nil {
+			panic(err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	space, err := client.CreateSpace(context.Background(), "OPS", "OPS Space", "About OPS")
+	if err != nil {
+		t.Fatalf("CreateSpace() error = %v", err)
+	}
+	if space.Key != "OPS" || space.HomepageID != "98765" {
+		t.Errorf("CreateSpace() = %+v", space)
+	}
+}
+
+func TestGetSpaceHomepage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var out interface{}
+		switch r.URL.Path {
+		case "/rest/api/space/OPS":
+			out = spaceJSON("OPS")
+		case "/rest/api/content/98765":
+			out = map[string]interface{}{"id": "98765", "title": "OPS Home", "space": map[string]string{"key": "OPS"}}
+		default:
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(out); err != nil {
+			panic(err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	home, err := client.GetSpaceHomepage(context.Background(), "OPS")
+	if err != nil {
+		t.Fatalf("GetSpaceHomepage() error = %v", err)
+	}
+	if home.ID != "98765" || home.Title != "OPS Home" {
+		t.Errorf("GetSpaceHomepage() = %+v", home)
+	}
+}
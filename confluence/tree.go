@@ -0,0 +1,196 @@
+package confluence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// pageListSize is the number of pages requested per page of results.
+const pageListSize = 100
+
+// maxTreePages is the most pages GetPageTree and GetSpaceTree retrieve.
+// Each page below the requested depth costs a request, so unbounded trees
+// of large spaces would take very long to fetch.
+var maxTreePages = 1000
+
+// ErrTreeTruncated is returned with a partial tree by GetPageTree and
+// GetSpaceTree when the tree has more pages than they retrieve.
+var ErrTreeTruncated = errors.New("page tree truncated: too many pages; request a smaller depth or a subtree")
+
+// pageResult is a page as returned by the content endpoints with version
+// and space expanded.
+type pageResult struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Title   string `json:"title"`
+	Version struct {
+		Number int `json:"number"`
+	} `json:"version"`
+	Space struct {
+		Key string `json:"key"`
+	} `json:"space"`
+}
+
+func (r pageResult) info() PageInfo {
+	return PageInfo{
+		ID:       r.ID,
+		Type:     r.Type,
+		Status:   r.Status,
+		Title:    r.Title,
+		Version:  r.Version.Number,
+		SpaceKey: r.Space.Key,
+	}
+}
+
+// listPages fetches every page of results from a paginated content
+// listing. path must already contain a query string.
+func (c *Client) listPages(ctx context.Context, path, message string) ([]PageInfo, error) {
+	pages := []PageInfo{}
+	for start := 0; ; {
+		var result struct {
+			Results []pageResult `json:"results"`
+			listLinks
+		}
+		if err := c.doJSON(ctx, "GET", fmt.Sprintf("%s&start=%d&limit=%d", path, start, pageListSize), nil, &result, message); err != nil {
+			return nil, err
+		}
+		for _, r := range result.Results {
+			pages = append(pages, r.info())
+		}
+		if !result.hasNext() || len(result.Results) == 0 {
+			return pages, nil
+		}
+		start += len(result.Results)
+	}
+}
+
+// GetPageInfo retrieves a page's metadata without its body.
+func (c *Client) GetPageInfo(ctx context.Context, pageID string) (*PageInfo, error) {
+	var result pageResult
+	if err := c.doJSON(ctx, "GET", "/rest/api/content/"+pageID+"?expand=version,space", nil, &result, "failed to get page"); err != nil {
+		return nil, err
+	}
+	info := result.info()
+	return &info, nil
+}
+
+// GetChildren lists the direct child pages of a page, in the order they
+// appear in the page tree.
+func (c *Client) GetChildren(ctx context.Context, pageID string) ([]PageInfo, error) {
+	return c.listPages(ctx, fmt.Sprintf("/rest/api/content/%s/child/page?expand=version,space", pageID), "failed to get child pages")
+}
+
+// GetDescendants lists all pages below a page, at any depth.
+func (c *Client) GetDescendants(ctx context.Context, pageID string) ([]PageInfo, error) {
+	return c.listPages(ctx, fmt.Sprintf("/rest/api/content/%s/descendant/page?expand=version,space", pageID), "failed to get descendant pages")
+}
+
+// GetAncestors lists the pages above a page, from the top of the space
+// down to its parent.
+func (c *Client) GetAncestors(ctx context.Context, pageID string) ([]PageInfo, error) {
+	var result struct {
+		Ancestors []pageResult `json:"ancestors"`
+	}
+	if err := c.doJSON(ctx, "GET", "/rest/api/content/"+pageID+"?expand=ancestors", nil, &result, "failed to get ancestors"); err != nil {
+		return nil, err
+	}
+	ancestors := make([]PageInfo, len(result.Ancestors))
+	for i, r := range result.Ancestors {
+		ancestors[i] = r.info()
+	}
+	return ancestors, nil
+}
+
+// GetSpaceRootPages lists the top-level pages of a space.
+func (c *Client) GetSpaceRootPages(ctx context.Context, spaceKey string) ([]PageInfo, error) {
+	return c.listPages(ctx, fmt.Sprintf("/rest/api/space/%s/content/page?depth=root&expand=version,space", spaceKey), "failed to get space pages")
+}
+
+// PageNode is a page in a page tree.
+type PageNode struct {
+	PageInfo
+	Children []*PageNode `json:"children,omitempty"`
+}
+
+// Count returns the number of pages in the tree rooted at n.
+func (n *PageNode) Count() int {
+	count := 1
+	for _, child := range n.Children {
+		count += child.Count()
+	}
+	return count
+}
+
+// GetPageTree retrieves a page and its descendants down to depth levels
+// below it. A negative depth includes all descendants. Trees of more than
+// 1000 pages are cut off: GetPageTree then returns the pages it retrieved
+// with ErrTreeTruncated.
+func (c *Client) GetPageTree(ctx context.Context, rootID string, depth int) (*PageNode, error) {
+	info, err := c.GetPageInfo(ctx, rootID)
+	if err != nil {
+		return nil, err
+	}
+	root := &PageNode{PageInfo: *info}
+	remaining := maxTreePages - 1
+	if err := c.fillTree(ctx, root, depth, &remaining); err != nil {
+		if errors.Is(err, ErrTreeTruncated) {
+			return root, err
+		}
+		return nil, err
+	}
+	return root, nil
+}
+
+// GetSpaceTree retrieves the page trees of a space's top-level pages,
+// down to depth levels below them. A negative depth includes all pages.
+// Like GetPageTree, it returns at most 1000 pages, with ErrTreeTruncated
+// if there are more.
+func (c *Client) GetSpaceTree(ctx context.Context, spaceKey string, depth int) ([]*PageNode, error) {
+	pages, err := c.GetSpaceRootPages(ctx, spaceKey)
+	if err != nil {
+		return nil, err
+	}
+	roots := []*PageNode{}
+	remaining := maxTreePages
+	for _, p := range pages {
+		if remaining == 0 {
+			return roots, ErrTreeTruncated
+		}
+		remaining--
+		root := &PageNode{PageInfo: p}
+		roots = append(roots, root)
+		if err := c.fillTree(ctx, root, depth, &remaining); err != nil {
+			if errors.Is(err, ErrTreeTruncated) {
+				return roots, err
+			}
+			return nil, err
+		}
+	}
+	return roots, nil
+}
+
+// fillTree adds the descendants of node down to depth levels, taking no
+// more than *remaining pages and counting them off.
+func (c *Client) fillTree(ctx context.Context, node *PageNode, depth int, remaining *int) error {
+	if depth == 0 {
+		return nil
+	}
+	children, err := c.GetChildren(ctx, node.ID)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		if *remaining == 0 {
+			return ErrTreeTruncated
+		}
+		*remaining--
+		childNode := &PageNode{PageInfo: child}
+		node.Children = append(node.Children, childNode)
+		if err := c.fillTree(ctx, childNode, depth-1, remaining); err != nil {
+			return err
+		}
+	}
+	return nil
+}
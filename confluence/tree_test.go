@@ -0,0 +1,197 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// treeServer serves a space DOCS with this tree:
+//
+//	1 Home
+//	  2 Guides
+//	    4 Install
+//	  3 Reference
+//	5 Archive
+func treeServer(t *testing.T) *httptest.Server {
+	titles := map[string]string{"1": "Home", "2": "Guides", "3": "Reference", "4": "Install", "5": "Archive"}
+	children := map[string][]string{"1": {"2", "3"}, "2": {"4"}}
+	page := func(id string) map[string]interface{} {
+		return map[string]interface{}{
+			"id":      id,
+			"type":    "page",
+			"title":   titles[id],
+			"version": map[string]interface{}{"number": 1},
+			"space":   map[string]interface{}{"key": "DOCS"},
+		}
+	}
+	list := func(ids []string) map[string]interface{} {
+		results := []interface{}{}
+		for _, id := range ids {
+			results = append(results, page(id))
+		}
+		return map[string]interface{}{"results": results}
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/rest/api/"), "/")
+		var out interface{}
+		switch {
+		case r.URL.Path == "/rest/api/space/DOCS/content/page":
+			if r.URL.Query().Get("depth") != "root" {
+				t.Errorf("depth = %q, want root", r.URL.Query().Get("depth"))
+			}
+			out = list([]string{"1", "5"})
+		case len(parts) == 4 && parts[2] == "child":
+			out = list(children[parts[1]])
+		case len(parts) == 4 && parts[2] == "descendant":
+			out = list([]string{"2", "3", "4"})
+		case len(parts) == 2 && r.URL.Query().Get("expand") == "ancestors":
+			out = map[string]interface{}{"id": parts[1], "ancestors": []interface{}{page("1"), page("2")}}
+		case len(parts) == 2:
+			out = page(parts[1])
+		default:
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(out); err != nil {
+			panic(err)
+		}
+	}))
+}
+
+func titlesOf(pages []PageInfo) string {
+	titles := make([]string, len(pages))
+	for i, p := range pages {
+		titles[i] = p.Title
+	}
+	return strings.Join(titles, ",")
+}
+
+func TestGetChildren(t *testing.T) {
+	server := treeServer(t)
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	children, err := client.GetChildren(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("GetChildren() error = %v", err)
+	}
+	if got := titlesOf(children); got != "Guides,Reference" {
+		t.Errorf("GetChildren() = %s", got)
+	}
+	if children[0].SpaceKey != "DOCS" || children[0].Version != 1 {
+		t.Errorf("GetChildren()[0] = %+v", children[0])
+	}
+
+	descendants, err := client.GetDescendants(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("GetDescendants() error = %v", err)
+	}
+	if got := titlesOf(descendants); got != "Guides,Reference,Install" {
+		t.Errorf("GetDescendants() = %s", got)
+	}
+
+	ancestors, err := client.GetAncestors(context.Background(), "4")
+	if err != nil {
+		t.Fatalf("GetAncestors() error = %v", err)
+	}
+	if got := titlesOf(ancestors); got != "Home,Guides" {
+		t.Errorf("GetAncestors() = %s", got)
+	}
+}
+
+func TestListPagesPagination(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		// A short first page that links to the next one.
+		count, links := 25, map[string]interface{}{"next": "/rest/api/content/1/descendant/page?start=25"}
+		if r.URL.Query().Get("start") != "0" {
+			count, links = 3, map[string]interface{}{}
+		}
+		results := make([]interface{}, count)
+		for i := range results {
+			results[i] = map[string]interface{}{"id": fmt.Sprint(i), "title": fmt.Sprintf("Page %d", i)}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]interface{}{"results": results, "_links": links}); err != nil {
+			panic(err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	pages, err := client.GetDescendants(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("GetDescendants() error = %v", err)
+	}
+	if len(pages) != 28 || requests != 2 {
+		t.Errorf("GetDescendants() = %d pages in %d requests", len(pages), requests)
+	}
+}
+
+func TestGetPageTree(t *testing.T) {
+	server := treeServer(t)
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	root, err := client.GetPageTree(context.Background(), "1", -1)
+	if err != nil {
+		t.Fatalf("GetPageTree() error = %v", err)
+	}
+	if root.Title != "Home" || root.Count() != 4 || len(root.Children) != 2 || root.Children[0].Children[0].Title != "Install" {
+		t.Errorf("GetPageTree() = %+v", root)
+	}
+
+	root, err = client.GetPageTree(context.Background(), "1", 1)
+	if err != nil {
+		t.Fatalf("GetPageTree() error = %v", err)
+	}
+	if root.Count() != 3 {
+		t.Errorf("GetPageTree(depth 1) has %d pages, want 3", root.Count())
+	}
+
+	roots, err := client.GetSpaceTree(context.Background(), "DOCS", 0)
+	if err != nil {
+		t.Fatalf("GetSpaceTree() error = %v", err)
+	}
+	if len(roots) != 2 || roots[1].Title != "Archive" || len(roots[0].Children) != 0 {
+		t.Errorf("GetSpaceTree(depth 0) = %+v", roots)
+	}
+}
+
+func TestGetPageTree_Truncated(t *testing.T) {
+	server := treeServer(t)
+	defer server.Close()
+	defer func(n int) { maxTreePages = n }(maxTreePages)
+	maxTreePages = 3
+
+	client := NewClient(server.URL, BasicAuth{})
+	root, err := client.GetPageTree(context.Background(), "1", -1)
+	if !errors.Is(err, ErrTreeTruncated) {
+		t.Fatalf("GetPageTree() error = %v, want ErrTreeTruncated", err)
+	}
+	if root == nil || root.Count() != 3 || root.Children[0].Children[0].Title != "Install" {
+		t.Errorf("GetPageTree() = %+v, want the first 3 pages", root)
+	}
+
+	maxTreePages = 4
+	roots, err := client.GetSpaceTree(context.Background(), "DOCS", -1)
+	if !errors.Is(err, ErrTreeTruncated) {
+		t.Fatalf("GetSpaceTree() error = %v, want ErrTreeTruncated", err)
+	}
+	if len(roots) != 1 || roots[0].Count() != 4 {
+		t.Errorf("GetSpaceTree() = %d roots, want 1 with 4 pages", len(roots))
+	}
+
+	maxTreePages = 5
+	if _, err := client.GetSpaceTree(context.Background(), "DOCS", -1); err != nil {
+		t.Errorf("GetSpaceTree() with room for every page error = %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+)
+
+// versionPageSize is the number of versions requested per page of results.
+const versionPageSize = 100
+
+// PageVersion describes one version in a page's history.
+type PageVersion struct {
+	Number    int    `json:"number"`
+	Author    string `json:"author,omitempty"`
+	When      string `json:"when"`
+	Message   string `json:"message,omitempty"`
+	MinorEdit bool   `json:"minorEdit"`
+}
+
+// versionResult is a version as returned by the REST API.
+type versionResult struct {
+	Number    int    `json:"number"`
+	When      string `json:"when"`
+	Message   string `json:"message"`
+	MinorEdit bool   `json:"minorEdit"`
+	By        struct {
+		DisplayName string `json:"displayName"`
+	} `json:"by"`
+}
+
+func (r versionResult) version() PageVersion {
+	return PageVersion{
+		Number:    r.Number,
+		Author:    r.By.DisplayName,
+		When:      r.When,
+		Message:   r.Message,
+		MinorEdit: r.MinorEdit,
+	}
+}
+
+// ListVersions lists a page's versions, newest first. A limit of zero or
+// less lists them all.
+func (c *Client) ListVersions(ctx context.Context, pageID string, limit int) ([]PageVersion, error) {
+	versions := []PageVersion{}
+	for start := 0; limit <= 0 || len(versions) < limit; {
+		size := versionPageSize
+		if limit > 0 && limit-len(versions) < size {
+			size = limit - len(versions)
+		}
+		var result struct {
+			Results []versionResult `json:"results"`
+			listLinks
+		}
+		path := fmt.Sprintf("/rest/api/content/%s/version?start=%d&limit=%d", pageID, start, size)
+		if err := c.doJSON(ctx, "GET", path, nil, &result, "failed to list versions"); err != nil {
+			return nil, err
+		}
+		for _, r := range result.Results {
+			versions = append(versions, r.version())
+		}
+		if !result.hasNext() || len(result.Results) == 0 {
+			break
+		}
+		start += len(result.Results)
+	}
+	return versions, nil
+}
+
+// RestoreVersion makes a copy of an old version the page's current
+// version, with message as the new version's comment.
+func (c *Client) RestoreVersion(ctx context.Context, pageID string, version int, message string) (*PageVersion, error) {
+	payload := map[string]interface{}{
+		"operationKey": "restore",
+		"params": map[string]interface{}{
+			"versionNumber": version,
+			"message":       message,
+			"restoreTitle":  true,
+		},
+	}
+
+	var result versionResult
+	path := fmt.Sprintf("/rest/api/content/%s/version", pageID)
+	if err := c.doJSON(ctx, "POST", path, payload, &result, "failed to restore version"); err != nil {
+		return nil, err
+	}
+	v := result.version()
+	return &v, nil
+}
@@ -0,0 +1,104 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestListVersions(t *testing.T) {
+	const total = versionPageSize + 20
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/rest/api/content/12345/version" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		// Like Confluence, return at most 50 results whatever the limit.
+		limit = min(limit, 50)
+
+		results := []interface{}{}
+		for n := total - start; n > 0 && len(results) < limit; n-- {
+			results = append(results, map[string]interface{}{
+				"number":  n,
+				"when":    "2024-05-01T10:00:00.000Z",
+				"message": "edit " + strconv.Itoa(n),
+				"by":      map[string]interface{}{"displayName": "Ana"},
+			})
+		}
+
+		links := map[string]interface{}{}
+		if start+len(results) < total {
+			links["next"] = "/rest/api/content/12345/version?start=" + strconv.Itoa(start+len(results))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]interface{}{"results": results, "_links": links}); err != nil {
+			panic(err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	versions, err := client.ListVersions(context.Background(), "12345", 0)
+	if err != nil {
+		t.Fatalf("ListVersions() error = %v", err)
+	}
+	if len(versions) != total || requests != 3 {
+		t.Errorf("ListVersions() = %d versions in %d requests", len(versions), requests)
+	}
+	want := PageVersion{Number: total, Author: "Ana", When: "2024-05-01T10:00:00.000Z", Message: "edit 120"}
+	if versions[0] != want {
+		t.Errorf("ListVersions()[0] = %+v, want %+v", versions[0], want)
+	}
+
+	requests = 0
+	versions, err = client.ListVersions(context.Background(), "12345", 5)
+	if err != nil {
+		t.Fatalf("ListVersions() error = %v", err)
+	}
+	if len(versions) != 5 || requests != 1 || versions[4].Number != total-4 {
+		t.Errorf("ListVersions(limit 5) = %+v in %d requests", versions, requests)
+	}
+}
+
+func TestRestoreVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/rest/api/content/12345/version" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		var payload struct {
+			OperationKey string `json:"operationKey"`
+			Params       struct {
+				VersionNumber int    `json:"versionNumber"`
+				Message       string `json:"message"`
+			} `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			panic(err)
+		}
+		if payload.OperationKey != "restore" || payload.Params.VersionNumber != 3 || payload.Params.Message != "Undo bad edit" {
+			t.Errorf("Unexpected payload: %+v", payload)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]interface{}{"number": 8, "message": payload.Params.Message}); err != nil {
+			panic(err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BasicAuth{})
+	version, err := client.RestoreVersion(context.Background(), "12345", 3, "Undo bad edit")
+	if err != nil {
+		t.Fatalf("RestoreVersion() error = %v", err)
+	}
+	if version.Number != 8 || version.Message != "Undo bad edit" {
+		t.Errorf("RestoreVersion() = %+v", version)
+	}
+}
@@ -0,0 +1,367 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+)
+
+// maxInlineAttachmentSize limits attachments returned as base64; larger
+// files must be downloaded to a path.
+const maxInlineAttachmentSize = 10 << 20
+
+// attachmentTools returns tools that manage page attachments.
+func attachmentTools() []Tool {
+	return []Tool{
+		{
+			Name:        "confluence_list_attachments",
+			Description: "List the files attached to a Confluence page",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The Confluence page ID",
+					},
+				},
+				"required": []string{"page_id"},
+			},
+		},
+		{
+			Name:        "confluence_upload_attachment",
+			Description: "Attach a file to a Confluence page from base64 content or a file in the server's attachment directory. If the page already has a file with that name, a new version is uploaded. Returns metadata including an image block and a link for referencing the file in page content.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The Confluence page ID",
+					},
+					"filename": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the attachment (default: base name of path)",
+					},
+					"content_base64": map[string]interface{}{
+						"type":        "string",
+						"description": "File content, base64 encoded",
+					},
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "File in the server's attachment directory to upload, instead of content_base64. Relative paths are resolved against the attachment directory.",
+					},
+					"comment": map[string]interface{}{
+						"type":        "string",
+						"description": "Comment for this version of the attachment",
+					},
+				},
+				"required": []string{"page_id"},
+			},
+		},
+		{
+			Name:        "confluence_download_attachment",
+			Description: "Download a file attached to a Confluence page, returning it base64 encoded or saving it to a new file in the server's attachment directory",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The Confluence page ID",
+					},
+					"filename": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the attachment",
+					},
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "File in the server's attachment directory to write. Relative paths are resolved against the attachment directory. Without it the content is returned base64 encoded (up to 10 MiB).",
+					},
+					"overwrite": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Replace path if it already exists (default false)",
+					},
+				},
+				"required": []string{"page_id", "filename"},
+			},
+		},
+		{
+			Name:        "confluence_delete_attachment",
+			Description: "Delete a file attached to a Confluence page, moving it to the space's trash",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The Confluence page ID",
+					},
+					"filename": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the attachment",
+					},
+				},
+				"required": []string{"page_id", "filename"},
+			},
+		},
+	}
+}
+
+// attachmentMetadata returns an attachment's metadata together with the
+// link span and, for images, the image block that reference it.
+func attachmentMetadata(a *confluence.Attachment) map[string]interface{} {
+	m := map[string]interface{}{
+		"id":           a.ID,
+		"title":        a.Title,
+		"media_type":   a.MediaType,
+		"file_size":    a.FileSize,
+		"version":      a.Version,
+		"download_url": a.DownloadURL,
+		"link":         map[string]interface{}{"text": a.Title, "link": a.DownloadURL},
+	}
+	if a.Comment != "" {
+		m["comment"] = a.Comment
+	}
+	if strings.HasPrefix(a.MediaType, "image/") {
+		m["image_block"] = map[string]interface{}{"type": "image", "attachment": a.Title}
+	}
+	return m
+}
+
+func (s *Server) handleListAttachments(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	if pageID == "" {
+		return nil, fmt.Errorf("page_id is required")
+	}
+
+	attachments, err := s.client.ListAttachments(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]map[string]interface{}, len(attachments))
+	for i := range attachments {
+		results[i] = attachmentMetadata(&attachments[i])
+	}
+
+	return map[string]interface{}{
+		"page_id":     pageID,
+		"attachments": results,
+		"count":       len(results),
+	}, nil
+}
+
+func (s *Server) handleUploadAttachment(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	filename, _ := input["filename"].(string)
+	content, _ := input["content_base64"].(string)
+	path, _ := input["path"].(string)
+	comment, _ := input["comment"].(string)
+
+	if pageID == "" {
+		return nil, fmt.Errorf("page_id is required")
+	}
+	if (content == "") == (path == "") {
+		return nil, fmt.Errorf("exactly one of content_base64 or path is required")
+	}
+	if err := s.requireEdit(ctx, pageID); err != nil {
+		return nil, err
+	}
+
+	var r io.Reader
+	if path != "" {
+		local, err := s.localPath(path)
+		if err != nil {
+			return nil, err
+		}
+		f, err := os.Open(local)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+		if filename == "" {
+			filename = filepath.Base(path)
+		}
+	} else {
+		data, err := base64.StdEncoding.DecodeString(content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid content_base64: %w", err)
+		}
+		r = bytes.NewReader(data)
+	}
+	if filename == "" {
+		return nil, fmt.Errorf("filename is required")
+	}
+
+	// Upload a new version if the page already has a file with this name.
+	existing, err := s.client.FindAttachment(ctx, pageID, filename)
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+
+	status := "created"
+	var attachment *confluence.Attachment
+	if existing != nil {
+		status = "updated"
+		attachment, err = s.client.UpdateAttachmentData(ctx, pageID, existing.ID, filename, r, comment)
+	} else {
+		attachment, err = s.client.UploadAttachment(ctx, pageID, filename, r, comment)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := attachmentMetadata(attachment)
+	result["status"] = status
+	result["page_id"] = pageID
+	return result, nil
+}
+
+func (s *Server) handleDownloadAttachment(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	filename, _ := input["filename"].(string)
+	path, _ := input["path"].(string)
+	overwrite, _ := input["overwrite"].(bool)
+
+	if pageID == "" {
+		return nil, fmt.Errorf("page_id is required")
+	}
+	if filename == "" {
+		return nil, fmt.Errorf("filename is required")
+	}
+
+	attachment, err := s.client.FindAttachment(ctx, pageID, filename)
+	if err != nil {
+		return nil, err
+	}
+	result := attachmentMetadata(attachment)
+	result["page_id"] = pageID
+
+	if path != "" {
+		local, err := s.localPath(path)
+		if err != nil {
+			return nil, err
+		}
+		flag := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+		if overwrite {
+			flag = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		}
+		f, err := os.OpenFile(local, flag, 0o600)
+		if errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("%s already exists; set overwrite to replace it", path)
+		}
+		if err != nil {
+			return nil, err
+		}
+		n, err := s.client.DownloadAttachment(ctx, attachment, f)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, err
+		}
+		result["path"] = path
+		result["bytes"] = n
+		return result, nil
+	}
+
+	if attachment.FileSize > maxInlineAttachmentSize {
+		return nil, fmt.Errorf("attachment is %d bytes, more than %d; use path to download it", attachment.FileSize, maxInlineAttachmentSize)
+	}
+	var buf bytes.Buffer
+	n, err := s.client.DownloadAttachment(ctx, attachment, &buf)
+	if err != nil {
+		return nil, err
+	}
+	result["bytes"] = n
+	result["content_base64"] = base64.StdEncoding.EncodeToString(buf.Bytes())
+	return result, nil
+}
+
+func (s *Server) handleDeleteAttachment(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	filename, _ := input["filename"].(string)
+
+	if pageID == "" {
+		return nil, fmt.Errorf("page_id is required")
+	}
+	if filename == "" {
+		return nil, fmt.Errorf("filename is required")
+	}
+
+	if err := s.requireEdit(ctx, pageID); err != nil {
+		return nil, err
+	}
+	attachment, err := s.client.FindAttachment(ctx, pageID, filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.client.DeleteAttachment(ctx, attachment.ID); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"status":        "deleted",
+		"page_id":       pageID,
+		"filename":      filename,
+		"attachment_id": attachment.ID,
+	}, nil
+}
+
+// localPath resolves path against the attachment directory and checks
+// that it stays inside the directory once symbolic links are followed.
+// The file itself must not be a symbolic link.
+func (s *Server) localPath(path string) (string, error) {
+	if s.attachmentDir == "" {
+		return "", fmt.Errorf("local paths are disabled: no attachment directory is configured (CONFLUENCE_ATTACHMENT_DIR); use content_base64")
+	}
+	root, err := filepath.Abs(s.attachmentDir)
+	if err != nil {
+		return "", err
+	}
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return "", err
+	}
+
+	full := path
+	if !filepath.IsAbs(full) {
+		full = filepath.Join(root, full)
+	}
+	full = filepath.Clean(full)
+
+	// The file may not exist yet, so resolve links in its directory, then
+	// in the file itself if it exists.
+	dir, err := filepath.EvalSymlinks(filepath.Dir(full))
+	if err != nil {
+		return "", err
+	}
+	full = filepath.Join(dir, filepath.Base(full))
+	resolved, err := filepath.EvalSymlinks(full)
+	if errors.Is(err, fs.ErrNotExist) {
+		resolved = full
+	} else if err != nil {
+		return "", err
+	}
+	if !insideDir(root, full) || !insideDir(root, resolved) {
+		return "", fmt.Errorf("%s is outside the attachment directory", path)
+	}
+	// A dangling link resolves to itself above, but writing through it
+	// would create its target, wherever that is.
+	if fi, err := os.Lstat(full); err == nil && fi.Mode()&fs.ModeSymlink != 0 {
+		return "", fmt.Errorf("%s is a symbolic link", path)
+	}
+	return full, nil
+}
+
+// insideDir reports whether path is below dir.
+func insideDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,326 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+)
+
+// attachmentServer serves the attachment API of page 12345 from memory.
+func attachmentServer() *httptest.Server {
+	type file struct {
+		id, mediaType string
+		version       int
+		data          []byte
+	}
+	files := map[string]*file{}
+	metadata := func(name string, f *file) map[string]interface{} {
+		return map[string]interface{}{
+			"id":         f.id,
+			"title":      name,
+			"version":    map[string]interface{}{"number": f.version},
+			"extensions": map[string]interface{}{"mediaType": f.mediaType, "fileSize": len(f.data)},
+			"_links":     map[string]interface{}{"download": "/download/attachments/12345/" + name},
+		}
+	}
+
+	return httptest.NewServer(withEditPermission(true, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var out interface{}
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/download/attachments/12345/"):
+			f := files[strings.TrimPrefix(r.URL.Path, "/download/attachments/12345/")]
+			if f == nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(f.data)
+			return
+		case r.Method == "DELETE":
+			for name, f := range files {
+				if "/rest/api/content/"+f.id == r.URL.Path {
+					delete(files, name)
+				}
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		case r.Method == "POST":
+			part, header, err := r.FormFile("file")
+			if err != nil {
+				panic(err)
+			}
+			data, err := io.ReadAll(part)
+			if err != nil {
+				panic(err)
+			}
+			f := files[header.Filename]
+			if f == nil {
+				f = &file{id: "att" + header.Filename}
+				files[header.Filename] = f
+			}
+			f.version++
+			f.mediaType = header.Header.Get("Content-Type")
+			f.data = data
+			if strings.HasSuffix(r.URL.Path, "/data") {
+				out = metadata(header.Filename, f)
+			} else {
+				out = map[string]interface{}{"results": []interface{}{metadata(header.Filename, f)}}
+			}
+		default:
+			results := []interface{}{}
+			for name, f := range files {
+				if filename := r.URL.Query().Get("filename"); filename == "" || filename == name {
+					results = append(results, metadata(name, f))
+				}
+			}
+			out = map[string]interface{}{"results": results}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(out); err != nil {
+			panic(err)
+		}
+	})))
+}
+
+func TestHandleAttachments(t *testing.T) {
+	httpServer := attachmentServer()
+	defer httpServer.Close()
+
+	dir := t.TempDir()
+	server := New(confluence.NewClient(httpServer.URL, confluence.BasicAuth{}), WithAttachmentDir(dir))
+	ctx := context.Background()
+
+	png := base64.StdEncoding.EncodeToString([]byte("png data"))
+	result, err := server.HandleTool(ctx, "confluence_upload_attachment", map[string]interface{}{
+		"page_id":        "12345",
+		"filename":       "diagram.png",
+		"content_base64": png,
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	response := decodeResult(t, result)
+	if response["status"] != "created" || response["version"] != float64(1) {
+		t.Errorf("upload response = %v", response)
+	}
+	image, _ := response["image_block"].(map[string]interface{})
+	if image["type"] != "image" || image["attachment"] != "diagram.png" {
+		t.Errorf("upload image_block = %v", response["image_block"])
+	}
+
+	// Uploading the same name again adds a version.
+	result, err = server.HandleTool(ctx, "confluence_upload_attachment", map[string]interface{}{
+		"page_id":        "12345",
+		"filename":       "diagram.png",
+		"content_base64": png,
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if response := decodeResult(t, result); response["status"] != "updated" || response["version"] != float64(2) {
+		t.Errorf("re-upload response = %v", response)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "report.csv"), []byte("a,b\n1,2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	result, err = server.HandleTool(ctx, "confluence_upload_attachment", map[string]interface{}{
+		"page_id": "12345",
+		"path":    "report.csv",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	response = decodeResult(t, result)
+	if response["title"] != "report.csv" || response["image_block"] != nil {
+		t.Errorf("upload from path response = %v", response)
+	}
+
+	result, err = server.HandleTool(ctx, "confluence_list_attachments", map[string]interface{}{
+		"page_id": "12345",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if response := decodeResult(t, result); response["count"] != float64(2) {
+		t.Errorf("list response = %v", response)
+	}
+
+	result, err = server.HandleTool(ctx, "confluence_download_attachment", map[string]interface{}{
+		"page_id":  "12345",
+		"filename": "diagram.png",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if response := decodeResult(t, result); response["content_base64"] != png {
+		t.Errorf("download response = %v", response)
+	}
+
+	dst := filepath.Join(dir, "copy.csv")
+	result, err = server.HandleTool(ctx, "confluence_download_attachment", map[string]interface{}{
+		"page_id":  "12345",
+		"filename": "report.csv",
+		"path":     dst,
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if response := decodeResult(t, result); response["bytes"] != float64(8) {
+		t.Errorf("download to path response = %v", response)
+	}
+	if data, err := os.ReadFile(dst); err != nil || string(data) != "a,b\n1,2\n" {
+		t.Errorf("downloaded file = %q, %v", data, err)
+	}
+
+	result, err = server.HandleTool(ctx, "confluence_delete_attachment", map[string]interface{}{
+		"page_id":  "12345",
+		"filename": "report.csv",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if response := decodeResult(t, result); response["status"] != "deleted" {
+		t.Errorf("delete response = %v", response)
+	}
+
+	result, err = server.HandleTool(ctx, "confluence_download_attachment", map[string]interface{}{
+		"page_id":  "12345",
+		"filename": "report.csv",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError {
+		t.Error("download of deleted attachment should fail")
+	}
+
+	result, err = server.HandleTool(ctx, "confluence_upload_attachment", map[string]interface{}{
+		"page_id":  "12345",
+		"filename": "empty.txt",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError {
+		t.Error("upload without content should fail")
+	}
+}
+
+func TestHandleAttachments_LocalPaths(t *testing.T) {
+	httpServer := attachmentServer()
+	defer httpServer.Close()
+	client := confluence.NewClient(httpServer.URL, confluence.BasicAuth{})
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	outside := t.TempDir()
+	secret := filepath.Join(outside, "secret.txt")
+	if err := os.WriteFile(secret, []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(secret, filepath.Join(dir, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "out")); err != nil {
+		t.Fatal(err)
+	}
+	server := New(client, WithAttachmentDir(dir))
+
+	for _, path := range []string{secret, "../" + filepath.Base(outside) + "/secret.txt", "link.txt", "out/secret.txt"} {
+		result, err := server.HandleTool(ctx, "confluence_upload_attachment", map[string]interface{}{
+			"page_id": "12345",
+			"path":    path,
+		})
+		if err != nil {
+			t.Fatalf("HandleTool() error = %v", err)
+		}
+		if !result.IsError || !strings.Contains(result.Content[0].Text, "outside the attachment directory") {
+			t.Errorf("upload from %s = %v, want outside error", path, result.Content)
+		}
+	}
+
+	result, err := server.HandleTool(ctx, "confluence_upload_attachment", map[string]interface{}{
+		"page_id":        "12345",
+		"filename":       "notes.txt",
+		"content_base64": base64.StdEncoding.EncodeToString([]byte("new")),
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("upload = %v, %v", result, err)
+	}
+
+	// Downloads never replace files unless asked to.
+	existing := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(existing, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	download := map[string]interface{}{
+		"page_id":  "12345",
+		"filename": "notes.txt",
+		"path":     "notes.txt",
+	}
+	result, err = server.HandleTool(ctx, "confluence_download_attachment", download)
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "already exists") {
+		t.Errorf("download over existing file = %v, want exists error", result.Content)
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "old" {
+		t.Errorf("existing file = %q, want it unchanged", data)
+	}
+
+	download["overwrite"] = true
+	result, err = server.HandleTool(ctx, "confluence_download_attachment", download)
+	if err != nil || result.IsError {
+		t.Fatalf("download with overwrite = %v, %v", result, err)
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "new" {
+		t.Errorf("overwritten file = %q, want %q", data, "new")
+	}
+
+	download["path"] = "out/copy.txt"
+	result, err = server.HandleTool(ctx, "confluence_download_attachment", download)
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError {
+		t.Error("download through a symlink out of the directory should fail")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "copy.txt")); err == nil {
+		t.Error("download wrote outside the attachment directory")
+	}
+
+	// A dangling link would create its target outside the directory.
+	if err := os.Symlink(filepath.Join(outside, "planted.txt"), filepath.Join(dir, "dangling.txt")); err != nil {
+		t.Fatal(err)
+	}
+	download["path"] = "dangling.txt"
+	result, err = server.HandleTool(ctx, "confluence_download_attachment", download)
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "symbolic link") {
+		t.Errorf("download through a dangling symlink = %v, want symlink error", result.Content)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "planted.txt")); err == nil {
+		t.Error("download created the target of a dangling symlink")
+	}
+
+	// Without a directory, only base64 content is accepted.
+	result, err = New(client).HandleTool(ctx, "confluence_download_attachment", download)
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "CONFLUENCE_ATTACHMENT_DIR") {
+		t.Errorf("download without directory = %v, want disabled error", result.Content)
+	}
+}
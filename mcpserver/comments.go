@@ -0,0 +1,289 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+	"github.com/agentplexus/mcp-confluence/storage"
+)
+
+// commentTools returns tools that read and write page comments.
+func commentTools() []Tool {
+	return []Tool{
+		{
+			Name:        "confluence_list_comments",
+			Description: "List the footer and inline comments of a Confluence page as threads with replies. Inline comments include the page text they are anchored to and whether they are resolved. Use it to read review feedback.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The Confluence page ID",
+					},
+					"location": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"footer", "inline"},
+						"description": "Only list threads at this location (default both)",
+					},
+					"unresolved_only": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Skip resolved inline comment threads (default false)",
+					},
+					"format": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"blocks", "markdown", "text"},
+						"description": "Comment body format (default markdown)",
+					},
+				},
+				"required": []string{"page_id"},
+			},
+		},
+		{
+			Name:        "confluence_add_comment",
+			Description: "Add a footer comment to a Confluence page, or reply to an existing comment. Provide the body as blocks or Markdown.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The Confluence page ID",
+					},
+					"parent_id": map[string]interface{}{
+						"type":        "string",
+						"description": "ID of the comment to reply to",
+					},
+					"blocks": map[string]interface{}{
+						"type":        "array",
+						"description": "Comment body as blocks",
+						"items":       map[string]interface{}{"type": "object"},
+					},
+					"markdown": map[string]interface{}{
+						"type":        "string",
+						"description": "Comment body as Markdown",
+					},
+				},
+				"required": []string{"page_id"},
+			},
+		},
+		{
+			Name:        "confluence_resolve_comment",
+			Description: "Resolve an inline comment, or reopen it. Requires Confluence Cloud.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"comment_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The inline comment ID",
+					},
+					"reopen": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Reopen the comment instead of resolving it (default false)",
+					},
+				},
+				"required": []string{"comment_id"},
+			},
+		},
+		{
+			Name:        "confluence_delete_comment",
+			Description: "Delete a comment from a Confluence page",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"comment_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The comment ID",
+					},
+				},
+				"required": []string{"comment_id"},
+			},
+		},
+	}
+}
+
+// commentToJSON converts a comment and its replies, with bodies in format.
+func commentToJSON(c *confluence.Comment, format string) (map[string]interface{}, error) {
+	m := map[string]interface{}{
+		"id":       c.ID,
+		"location": c.Location,
+		"author":   c.Author,
+		"created":  c.Created,
+		"version":  c.Version,
+	}
+	if c.ParentID != "" {
+		m["parent_id"] = c.ParentID
+	}
+	if c.Resolution != "" {
+		m["resolution"] = c.Resolution
+	}
+	if c.Selection != "" {
+		m["selection"] = c.Selection
+	}
+	if err := setContent(m, format, c.Body); err != nil {
+		return nil, err
+	}
+	if len(c.Replies) > 0 {
+		replies := make([]interface{}, len(c.Replies))
+		for i, r := range c.Replies {
+			reply, err := commentToJSON(r, format)
+			if err != nil {
+				return nil, err
+			}
+			replies[i] = reply
+		}
+		m["replies"] = replies
+	}
+	return m, nil
+}
+
+// countComments returns the number of comments in threads, including
+// replies.
+func countComments(threads []*confluence.Comment) int {
+	n := len(threads)
+	for _, c := range threads {
+		n += countComments(c.Replies)
+	}
+	return n
+}
+
+func (s *Server) handleListComments(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	location, _ := input["location"].(string)
+	unresolvedOnly, _ := input["unresolved_only"].(bool)
+	format, _ := input["format"].(string)
+
+	if pageID == "" {
+		return nil, fmt.Errorf("page_id is required")
+	}
+	if format == "" {
+		format = "markdown"
+	}
+	if _, ok := blockSizers[format]; !ok {
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+
+	threads, err := s.client.ListComments(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	var kept []*confluence.Comment
+	results := []interface{}{}
+	for _, c := range threads {
+		if location != "" && c.Location != location {
+			continue
+		}
+		if unresolvedOnly && c.Resolution == "resolved" {
+			continue
+		}
+		thread, err := commentToJSON(c, format)
+		if err != nil {
+			return nil, err
+		}
+		kept = append(kept, c)
+		results = append(results, thread)
+	}
+
+	return map[string]interface{}{
+		"page_id":  pageID,
+		"threads":  results,
+		"comments": countComments(kept),
+	}, nil
+}
+
+func (s *Server) handleAddComment(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	parentID, _ := input["parent_id"].(string)
+	blocksRaw, hasBlocks := input["blocks"].([]interface{})
+	markdown, hasMarkdown := input["markdown"].(string)
+
+	if pageID == "" {
+		return nil, fmt.Errorf("page_id is required")
+	}
+	if hasBlocks == hasMarkdown {
+		return nil, fmt.Errorf("exactly one of blocks or markdown is required")
+	}
+
+	body := storage.ParseMarkdown(markdown)
+	if hasBlocks {
+		var err error
+		if body, err = parseBlocks(blocksRaw); err != nil {
+			return nil, fmt.Errorf("invalid blocks: %w", err)
+		}
+	}
+	if len(body.Blocks) == 0 {
+		return nil, fmt.Errorf("comment body is empty")
+	}
+
+	if err := s.requireEdit(ctx, pageID); err != nil {
+		return nil, err
+	}
+	var comment *confluence.Comment
+	var err error
+	if parentID != "" {
+		comment, err = s.client.ReplyToComment(ctx, pageID, parentID, body)
+	} else {
+		comment, err = s.client.AddComment(ctx, pageID, body)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"status":     "created",
+		"page_id":    pageID,
+		"comment_id": comment.ID,
+	}
+	if parentID != "" {
+		result["parent_id"] = parentID
+	}
+	return result, nil
+}
+
+func (s *Server) handleResolveComment(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	commentID, _ := input["comment_id"].(string)
+	reopen, _ := input["reopen"].(bool)
+
+	if commentID == "" {
+		return nil, fmt.Errorf("comment_id is required")
+	}
+
+	if err := s.requireEdit(ctx, commentID); err != nil {
+		return nil, err
+	}
+	status := "resolved"
+	var err error
+	if reopen {
+		status = "reopened"
+		err = s.client.ReopenComment(ctx, commentID)
+	} else {
+		err = s.client.ResolveComment(ctx, commentID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"status":     status,
+		"comment_id": commentID,
+	}, nil
+}
+
+func (s *Server) handleDeleteComment(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	commentID, _ := input["comment_id"].(string)
+	if commentID == "" {
+		return nil, fmt.Errorf("comment_id is required")
+	}
+
+	if err := s.requireEdit(ctx, commentID); err != nil {
+		return nil, err
+	}
+	if err := s.client.DeleteComment(ctx, commentID); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"status":     "deleted",
+		"comment_id": commentID,
+	}, nil
+}
@@ -0,0 +1,130 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+)
+
+func TestHandleComments(t *testing.T) {
+	comment := func(id, body, location, resolution string, ancestors ...string) map[string]interface{} {
+		ancestorList := []interface{}{}
+		for _, a := range ancestors {
+			ancestorList = append(ancestorList, map[string]interface{}{"id": a})
+		}
+		return map[string]interface{}{
+			"id":        id,
+			"body":      map[string]interface{}{"storage": map[string]interface{}{"value": body}},
+			"version":   map[string]interface{}{"number": 1, "by": map[string]interface{}{"displayName": "Ana"}},
+			"ancestors": ancestorList,
+			"extensions": map[string]interface{}{
+				"location":   location,
+				"resolution": map[string]interface{}{"status": resolution},
+			},
+		}
+	}
+
+	var created map[string]interface{}
+	httpServer := httptest.NewServer(withEditPermission(true, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var out interface{}
+		switch {
+		case r.Method == "POST":
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				panic(err)
+			}
+			out = comment("9", "<p>Done.</p>", "footer", "")
+		case r.Method == "DELETE":
+			w.WriteHeader(http.StatusNoContent)
+			return
+		default:
+			out = map[string]interface{}{"results": []interface{}{
+				comment("1", "<p>Please add a <strong>rollback</strong> section.</p>", "footer", ""),
+				comment("2", "<p>Typo</p>", "inline", "resolved"),
+				comment("3", "<p>Will do.</p>", "footer", "", "1"),
+			}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(out); err != nil {
+			panic(err)
+		}
+	})))
+	defer httpServer.Close()
+
+	server := New(confluence.NewClient(httpServer.URL, confluence.BasicAuth{}))
+	ctx := context.Background()
+
+	result, err := server.HandleTool(ctx, "confluence_list_comments", map[string]interface{}{
+		"page_id":         "12345",
+		"unresolved_only": true,
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	response := decodeResult(t, result)
+	threads, _ := response["threads"].([]interface{})
+	if len(threads) != 1 || response["comments"] != float64(2) {
+		t.Fatalf("list_comments response = %v", response)
+	}
+	thread := threads[0].(map[string]interface{})
+	if thread["markdown"] != "Please add a **rollback** section.\n" || thread["author"] != "Ana" {
+		t.Errorf("thread = %v", thread)
+	}
+	if replies, _ := thread["replies"].([]interface{}); len(replies) != 1 {
+		t.Errorf("thread replies = %v", thread["replies"])
+	}
+
+	result, err = server.HandleTool(ctx, "confluence_list_comments", map[string]interface{}{
+		"page_id":  "12345",
+		"location": "inline",
+		"format":   "text",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	response = decodeResult(t, result)
+	threads, _ = response["threads"].([]interface{})
+	if len(threads) != 1 || threads[0].(map[string]interface{})["resolution"] != "resolved" {
+		t.Errorf("inline list_comments response = %v", response)
+	}
+
+	result, err = server.HandleTool(ctx, "confluence_add_comment", map[string]interface{}{
+		"page_id":   "12345",
+		"parent_id": "1",
+		"markdown":  "Added in **v3**.",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if response := decodeResult(t, result); response["comment_id"] != "9" {
+		t.Errorf("add_comment response = %v", response)
+	}
+	body, _ := created["body"].(map[string]interface{})["storage"].(map[string]interface{})["value"].(string)
+	if !strings.Contains(body, "<strong>v3</strong>") || created["ancestors"] == nil {
+		t.Errorf("created comment = %v", created)
+	}
+
+	result, err = server.HandleTool(ctx, "confluence_add_comment", map[string]interface{}{
+		"page_id": "12345",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError {
+		t.Error("add_comment without a body should fail")
+	}
+
+	result, err = server.HandleTool(ctx, "confluence_delete_comment", map[string]interface{}{
+		"comment_id": "3",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if response := decodeResult(t, result); response["status"] != "deleted" {
+		t.Errorf("delete_comment response = %v", response)
+	}
+}
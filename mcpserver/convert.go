@@ -42,10 +42,14 @@ func blockToJSON(block storage.Block) map[string]interface{} {
 			"rows":    rows,
 		}
 	case *storage.Paragraph:
-		return map[string]interface{}{
+		m := map[string]interface{}{
 			"type": "paragraph",
 			"text": b.Text,
 		}
+		if len(b.Spans) > 0 {
+			m["spans"] = b.Spans
+		}
+		return m
 	case *storage.Heading:
 		return map[string]interface{}{
 			"type":  "heading",
@@ -53,23 +57,53 @@ func blockToJSON(block storage.Block) map[string]interface{} {
 			"text":  b.Text,
 		}
 	case *storage.BulletList:
-		items := make([]string, len(b.Items))
-		for i, item := range b.Items {
-			items[i] = item.Text
-		}
 		return map[string]interface{}{
 			"type":  "bullet_list",
-			"items": items,
+			"items": itemsToJSON(b.Items),
 		}
 	case *storage.NumberedList:
-		items := make([]string, len(b.Items))
+		return map[string]interface{}{
+			"type":  "numbered_list",
+			"items": itemsToJSON(b.Items),
+		}
+	case *storage.TaskList:
+		items := make([]interface{}, len(b.Items))
 		for i, item := range b.Items {
-			items[i] = item.Text
+			m := map[string]interface{}{
+				"text": item.Text,
+				"done": item.Done,
+			}
+			if len(item.Spans) > 0 {
+				m["spans"] = item.Spans
+			}
+			if len(item.Children) > 0 {
+				m["children"] = blocksToJSON(item.Children)
+			}
+			items[i] = m
 		}
 		return map[string]interface{}{
-			"type":  "numbered_list",
+			"type":  "task_list",
 			"items": items,
 		}
+	case *storage.Blockquote:
+		return map[string]interface{}{
+			"type":   "blockquote",
+			"blocks": blocksToJSON(b.Blocks),
+		}
+	case *storage.Image:
+		m := map[string]interface{}{
+			"type": "image",
+		}
+		if b.URL != "" {
+			m["url"] = b.URL
+		}
+		if b.Attachment != "" {
+			m["attachment"] = b.Attachment
+		}
+		if b.Alt != "" {
+			m["alt"] = b.Alt
+		}
+		return m
 	case *storage.Macro:
 		return map[string]interface{}{
 			"type":   "macro",
@@ -94,6 +128,29 @@ func blockToJSON(block storage.Block) map[string]interface{} {
 	}
 }
 
+// itemsToJSON converts list items to JSON. Plain items are strings; items
+// with formatting or nested blocks are objects.
+func itemsToJSON(items []storage.ListItem) []interface{} {
+	result := make([]interface{}, len(items))
+	for i, item := range items {
+		if len(item.Spans) == 0 && len(item.Children) == 0 {
+			result[i] = item.Text
+			continue
+		}
+		m := map[string]interface{}{
+			"text": item.Text,
+		}
+		if len(item.Spans) > 0 {
+			m["spans"] = item.Spans
+		}
+		if len(item.Children) > 0 {
+			m["children"] = blocksToJSON(item.Children)
+		}
+		result[i] = m
+	}
+	return result
+}
+
 // parseBlocks converts JSON input to a storage.Page.
 func parseBlocks(blocksRaw []interface{}) (*storage.Page, error) {
 	page := &storage.Page{Blocks: []storage.Block{}}
@@ -123,7 +180,7 @@ func parseBlock(raw interface{}) (storage.Block, error) {
 		return parseTableBlock(m), nil
 	case "paragraph":
 		text, _ := m["text"].(string)
-		return &storage.Paragraph{Text: text}, nil
+		return &storage.Paragraph{Text: text, Spans: parseSpans(m["spans"])}, nil
 	case "heading":
 		level := 1
 		if l, ok := m["level"].(float64); ok {
@@ -132,9 +189,35 @@ func parseBlock(raw interface{}) (storage.Block, error) {
 		text, _ := m["text"].(string)
 		return &storage.Heading{Level: level, Text: text}, nil
 	case "bullet_list":
-		return parseBulletListBlock(m), nil
+		items, err := parseListItems(m)
+		if err != nil {
+			return nil, err
+		}
+		return &storage.BulletList{Items: items}, nil
 	case "numbered_list":
-		return parseNumberedListBlock(m), nil
+		items, err := parseListItems(m)
+		if err != nil {
+			return nil, err
+		}
+		return &storage.NumberedList{Items: items}, nil
+	case "task_list":
+		return parseTaskListBlock(m)
+	case "blockquote":
+		blocksRaw, _ := m["blocks"].([]interface{})
+		page, err := parseBlocks(blocksRaw)
+		if err != nil {
+			return nil, err
+		}
+		return &storage.Blockquote{Blocks: page.Blocks}, nil
+	case "image":
+		img := &storage.Image{}
+		img.URL, _ = m["url"].(string)
+		img.Attachment, _ = m["attachment"].(string)
+		img.Alt, _ = m["alt"].(string)
+		if img.URL == "" && img.Attachment == "" {
+			return nil, fmt.Errorf("image requires url or attachment")
+		}
+		return img, nil
 	case "macro":
 		return parseMacroBlock(m), nil
 	case "code_block":
@@ -178,28 +261,84 @@ func parseTableBlock(m map[string]interface{}) *storage.Table {
 	return table
 }
 
-func parseBulletListBlock(m map[string]interface{}) *storage.BulletList {
-	list := &storage.BulletList{Items: []storage.ListItem{}}
-	if items, ok := m["items"].([]interface{}); ok {
-		for _, item := range items {
-			if s, ok := item.(string); ok {
-				list.Items = append(list.Items, storage.ListItem{Text: s})
+// parseListItems converts a list block's items, each either a string or an
+// object with text, spans and children.
+func parseListItems(m map[string]interface{}) ([]storage.ListItem, error) {
+	items := []storage.ListItem{}
+	raw, _ := m["items"].([]interface{})
+	for _, itemRaw := range raw {
+		switch v := itemRaw.(type) {
+		case string:
+			items = append(items, storage.ListItem{Text: v})
+		case map[string]interface{}:
+			text, _ := v["text"].(string)
+			children, err := parseChildren(v)
+			if err != nil {
+				return nil, err
 			}
+			items = append(items, storage.ListItem{Text: text, Spans: parseSpans(v["spans"]), Children: children})
 		}
 	}
-	return list
+	return items, nil
 }
 
-func parseNumberedListBlock(m map[string]interface{}) *storage.NumberedList {
-	list := &storage.NumberedList{Items: []storage.ListItem{}}
-	if items, ok := m["items"].([]interface{}); ok {
-		for _, item := range items {
-			if s, ok := item.(string); ok {
-				list.Items = append(list.Items, storage.ListItem{Text: s})
-			}
+func parseTaskListBlock(m map[string]interface{}) (*storage.TaskList, error) {
+	list := &storage.TaskList{Items: []storage.TaskItem{}}
+	raw, _ := m["items"].([]interface{})
+	for _, itemRaw := range raw {
+		v, ok := itemRaw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("task items must be objects")
+		}
+		item := storage.TaskItem{Spans: parseSpans(v["spans"])}
+		item.Text, _ = v["text"].(string)
+		item.Done, _ = v["done"].(bool)
+		children, err := parseChildren(v)
+		if err != nil {
+			return nil, err
+		}
+		item.Children = children
+		list.Items = append(list.Items, item)
+	}
+	return list, nil
+}
+
+func parseChildren(m map[string]interface{}) ([]storage.Block, error) {
+	raw, ok := m["children"].([]interface{})
+	if !ok {
+		return nil, nil
+	}
+	page, err := parseBlocks(raw)
+	if err != nil {
+		return nil, err
+	}
+	return page.Blocks, nil
+}
+
+// parseSpans converts a JSON array of span objects to storage.Span values.
+func parseSpans(raw interface{}) []storage.Span {
+	list, ok := raw.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil
+	}
+	spans := make([]storage.Span, 0, len(list))
+	for _, spanRaw := range list {
+		v, ok := spanRaw.(map[string]interface{})
+		if !ok {
+			continue
 		}
+		var span storage.Span
+		span.Text, _ = v["text"].(string)
+		span.Bold, _ = v["bold"].(bool)
+		span.Italic, _ = v["italic"].(bool)
+		span.Code, _ = v["code"].(bool)
+		span.Strike, _ = v["strike"].(bool)
+		span.Link, _ = v["link"].(string)
+		span.Page, _ = v["page"].(string)
+		span.Space, _ = v["space"].(string)
+		spans = append(spans, span)
 	}
-	return list
+	return spans
 }
 
 func parseMacroBlock(m map[string]interface{}) *storage.Macro {
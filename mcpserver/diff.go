@@ -0,0 +1,82 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/agentplexus/mcp-confluence/storage"
+)
+
+// diffTools returns tools for previewing changes before they are published.
+func diffTools() []Tool {
+	return []Tool{
+		{
+			Name:        "confluence_diff_page",
+			Description: "Compare a Confluence page's current content with proposed content blocks without publishing. Returns block-level edits (insert, delete, modify, move) with nested table cell and list item changes, as a unified text diff or JSON.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The Confluence page ID",
+					},
+					"blocks": map[string]interface{}{
+						"type":        "array",
+						"description": "Proposed array of content blocks",
+						"items": map[string]interface{}{
+							"type": "object",
+						},
+					},
+					"format": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"unified", "json"},
+						"description": "Diff output format (default unified)",
+					},
+				},
+				"required": []string{"page_id", "blocks"},
+			},
+		},
+	}
+}
+
+func (s *Server) handleDiffPage(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	blocksRaw, _ := input["blocks"].([]interface{})
+	format, _ := input["format"].(string)
+
+	if pageID == "" {
+		return nil, fmt.Errorf("page_id is required")
+	}
+	if format == "" {
+		format = "unified"
+	}
+	if format != "unified" && format != "json" {
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+
+	proposed, err := parseBlocks(blocksRaw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid blocks: %w", err)
+	}
+
+	current, info, err := s.client.GetPageStorage(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := storage.Diff(current, proposed)
+
+	result := map[string]interface{}{
+		"page_id":    info.ID,
+		"title":      info.Title,
+		"version":    info.Version,
+		"changed":    !diff.Empty(),
+		"edit_count": len(diff.Edits),
+	}
+	if format == "json" {
+		result["edits"] = diff.Edits
+	} else {
+		result["diff"] = diff.Unified()
+	}
+	return result, nil
+}
@@ -0,0 +1,86 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+)
+
+func TestHandleDiffPage(t *testing.T) {
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("Expected GET request, got %s", r.Method)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(pageResponse("12345", "Test Page", "<h1>Title</h1><p>Old</p>", 3)); err != nil {
+			panic(err)
+		}
+	}))
+	defer httpServer.Close()
+
+	client := confluence.NewClient(httpServer.URL, confluence.BasicAuth{})
+	server := New(client)
+
+	blocks := []interface{}{
+		map[string]interface{}{"type": "heading", "level": float64(1), "text": "Title"},
+		map[string]interface{}{"type": "paragraph", "text": "New"},
+	}
+
+	result, err := server.HandleTool(context.Background(), "confluence_diff_page", map[string]interface{}{
+		"page_id": "12345",
+		"blocks":  blocks,
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	response := decodeResult(t, result)
+
+	if response["changed"] != true {
+		t.Errorf("Response changed = %v, want true", response["changed"])
+	}
+	diff, _ := response["diff"].(string)
+	if !strings.Contains(diff, "- Old") || !strings.Contains(diff, "+ New") {
+		t.Errorf("Response diff = %q", diff)
+	}
+
+	result, err = server.HandleTool(context.Background(), "confluence_diff_page", map[string]interface{}{
+		"page_id": "12345",
+		"blocks":  blocks,
+		"format":  "json",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	response = decodeResult(t, result)
+
+	edits, ok := response["edits"].([]interface{})
+	if !ok || len(edits) != 1 {
+		t.Fatalf("Response edits = %v, want one edit", response["edits"])
+	}
+	edit := edits[0].(map[string]interface{})
+	if edit["op"] != "modify" {
+		t.Errorf("edit op = %v, want modify", edit["op"])
+	}
+}
+
+func TestHandleDiffPage_InvalidFormat(t *testing.T) {
+	client := confluence.NewClient("http://example.com", confluence.BasicAuth{})
+	server := New(client)
+
+	result, err := server.HandleTool(context.Background(), "confluence_diff_page", map[string]interface{}{
+		"page_id": "12345",
+		"blocks":  []interface{}{},
+		"format":  "xml",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError {
+		t.Error("HandleTool() should return error result for unsupported format")
+	}
+}
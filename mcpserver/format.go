@@ -0,0 +1,66 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/agentplexus/mcp-confluence/storage"
+)
+
+// formatTools returns tools for normalizing and pretty-printing Storage XHTML.
+func formatTools() []Tool {
+	return []Tool{
+		{
+			Name:        "confluence_normalize_xhtml",
+			Description: "Normalize Storage Format XHTML to a canonical form (sorted attributes, collapsed whitespace, decoded entities, self-closing empty tags) so that rendered and stored content can be compared. Accepts raw XHTML or a page ID, and can pretty-print the result for review.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"xhtml": map[string]interface{}{
+						"type":        "string",
+						"description": "Storage Format XHTML to normalize (use this or page_id)",
+					},
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Confluence page ID whose body should be normalized (use this or xhtml)",
+					},
+					"pretty": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Indent block elements on their own lines (default false)",
+					},
+				},
+			},
+		},
+	}
+}
+
+func (s *Server) handleNormalizeXHTML(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	xhtml, _ := input["xhtml"].(string)
+	pageID, _ := input["page_id"].(string)
+	pretty, _ := input["pretty"].(bool)
+
+	if xhtml == "" && pageID == "" {
+		return nil, fmt.Errorf("xhtml or page_id is required")
+	}
+
+	if xhtml == "" {
+		raw, _, err := s.client.GetPageStorageRaw(ctx, pageID)
+		if err != nil {
+			return nil, err
+		}
+		xhtml = raw
+	}
+
+	format := storage.Normalize
+	if pretty {
+		format = storage.Pretty
+	}
+	result, err := format(xhtml)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"xhtml": result,
+	}, nil
+}
@@ -0,0 +1,108 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+)
+
+func TestHandleNormalizeXHTML(t *testing.T) {
+	client := confluence.NewClient("http://example.com", confluence.BasicAuth{})
+	server := New(client)
+
+	tests := []struct {
+		name  string
+		input map[string]interface{}
+		want  string
+	}{
+		{
+			name:  "normalize",
+			input: map[string]interface{}{"xhtml": "<p>\n  Hello  world\n</p>"},
+			want:  "<p>Hello world</p>",
+		},
+		{
+			name:  "pretty",
+			input: map[string]interface{}{"xhtml": "<ul><li>A</li></ul>", "pretty": true},
+			want:  "<ul>\n  <li>A</li>\n</ul>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := server.HandleTool(context.Background(), "confluence_normalize_xhtml", tt.input)
+			if err != nil {
+				t.Fatalf("HandleTool() error = %v", err)
+			}
+			if result.IsError {
+				t.Fatalf("HandleTool() returned error: %v", result.Content)
+			}
+
+			var response map[string]interface{}
+			if err := json.Unmarshal([]byte(result.Content[0].Text), &response); err != nil {
+				t.Fatalf("Failed to parse response JSON: %v", err)
+			}
+			if response["xhtml"] != tt.want {
+				t.Errorf("Response xhtml = %q, want %q", response["xhtml"], tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleNormalizeXHTML_PageID(t *testing.T) {
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := map[string]interface{}{
+			"id":    "12345",
+			"title": "Test Page",
+			"body": map[string]interface{}{
+				"storage": map[string]string{
+					"value": `<table ac:local-id="x"><tbody><tr><td><p>Cell</p></td></tr></tbody></table>`,
+				},
+			},
+			"version": map[string]int{"number": 1},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			panic(err)
+		}
+	}))
+	defer httpServer.Close()
+
+	client := confluence.NewClient(httpServer.URL, confluence.BasicAuth{})
+	server := New(client)
+
+	result, err := server.HandleTool(context.Background(), "confluence_normalize_xhtml", map[string]interface{}{
+		"page_id": "12345",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("HandleTool() returned error: %v", result.Content)
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &response); err != nil {
+		t.Fatalf("Failed to parse response JSON: %v", err)
+	}
+	want := "<table><tbody><tr><td>Cell</td></tr></tbody></table>"
+	if response["xhtml"] != want {
+		t.Errorf("Response xhtml = %q, want %q", response["xhtml"], want)
+	}
+}
+
+func TestHandleNormalizeXHTML_MissingInput(t *testing.T) {
+	client := confluence.NewClient("http://example.com", confluence.BasicAuth{})
+	server := New(client)
+
+	result, err := server.HandleTool(context.Background(), "confluence_normalize_xhtml", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError {
+		t.Error("HandleTool() should return error result when xhtml and page_id are missing")
+	}
+}
@@ -2,8 +2,13 @@ package mcpserver
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"unicode/utf8"
 
+	"github.com/agentplexus/mcp-confluence/confluence"
 	"github.com/agentplexus/mcp-confluence/storage"
 )
 
@@ -12,19 +17,113 @@ func (s *Server) handleReadPage(ctx context.Context, input map[string]interface{
 	if !ok || pageID == "" {
 		return nil, fmt.Errorf("page_id is required")
 	}
+	format, _ := input["format"].(string)
+	if format == "" {
+		format = "blocks"
+	}
+	size, ok := blockSizers[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+
+	offset, maxBlocks, maxChars := 0, 0, 0
+	if o, ok := input["offset"].(float64); ok {
+		offset = int(o)
+	}
+	if m, ok := input["max_blocks"].(float64); ok {
+		maxBlocks = int(m)
+	}
+	if m, ok := input["max_chars"].(float64); ok {
+		maxChars = int(m)
+	}
 
 	page, info, err := s.client.GetPageStorage(ctx, pageID)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
-		"page_id":   info.ID,
-		"title":     info.Title,
-		"version":   info.Version,
-		"space_key": info.SpaceKey,
-		"blocks":    blocksToJSON(page.Blocks),
-	}, nil
+	total := len(page.Blocks)
+	if offset < 0 || offset > total {
+		return nil, fmt.Errorf("offset %d out of range (page has %d blocks)", offset, total)
+	}
+	end, err := windowEnd(page.Blocks, offset, maxBlocks, maxChars, size)
+	if err != nil {
+		return nil, err
+	}
+	window := &storage.Page{Blocks: page.Blocks[offset:end]}
+
+	result := map[string]interface{}{
+		"page_id":      info.ID,
+		"title":        info.Title,
+		"version":      info.Version,
+		"space_key":    info.SpaceKey,
+		"offset":       offset,
+		"total_blocks": total,
+	}
+	if end < total {
+		result["next_offset"] = end
+	}
+	if err := setContent(result, format, window); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// setContent adds page to result in format, under a key named after the
+// format.
+func setContent(result map[string]interface{}, format string, page *storage.Page) error {
+	switch format {
+	case "markdown":
+		md, err := storage.RenderMarkdown(page)
+		if err != nil {
+			return err
+		}
+		result["markdown"] = md
+	case "text":
+		result["text"] = storage.RenderText(page)
+	default:
+		result["blocks"] = blocksToJSON(page.Blocks)
+	}
+	return nil
+}
+
+// blockSizers measure a block in characters as returned in each read format.
+var blockSizers = map[string]func(storage.Block) (int, error){
+	"blocks": func(b storage.Block) (int, error) {
+		data, err := json.Marshal(blockToJSON(b))
+		return utf8.RuneCount(data), err
+	},
+	"markdown": func(b storage.Block) (int, error) {
+		md, err := storage.RenderBlockMarkdown(b)
+		return utf8.RuneCountInString(md) + 2, err
+	},
+	"text": func(b storage.Block) (int, error) {
+		return utf8.RuneCountInString(storage.RenderBlockText(b)) + 2, nil
+	},
+}
+
+// windowEnd returns the end of the window of blocks starting at offset that
+// fits within maxBlocks and maxChars (zero means no limit). The window holds
+// at least one block so that paging always makes progress.
+func windowEnd(blocks []storage.Block, offset, maxBlocks, maxChars int, size func(storage.Block) (int, error)) (int, error) {
+	end, chars := offset, 0
+	for end < len(blocks) {
+		if maxBlocks > 0 && end-offset >= maxBlocks {
+			break
+		}
+		if maxChars > 0 {
+			n, err := size(blocks[end])
+			if err != nil {
+				return 0, err
+			}
+			if chars+n > maxChars && end > offset {
+				break
+			}
+			chars += n
+		}
+		end++
+	}
+	return end, nil
 }
 
 func (s *Server) handleReadPageXHTML(ctx context.Context, input map[string]interface{}) (interface{}, error) {
@@ -64,13 +163,58 @@ func (s *Server) handleUpdatePage(ctx context.Context, input map[string]interfac
 		return nil, fmt.Errorf("invalid blocks: %w", err)
 	}
 
+	return s.updatePage(ctx, input, pageID, title, page)
+}
+
+// defaultVersionMessage attributes edits made through the server in the
+// page history.
+const defaultVersionMessage = "Edited with mcp-confluence"
+
+// withVersionOptions adds the version_message and minor_edit inputs of
+// tools that publish page updates to properties.
+func withVersionOptions(properties map[string]interface{}) map[string]interface{} {
+	properties["version_message"] = map[string]interface{}{
+		"type":        "string",
+		"description": "Message for the new version in the page history (default \"" + defaultVersionMessage + "\")",
+	}
+	properties["minor_edit"] = map[string]interface{}{
+		"type":        "boolean",
+		"description": "Publish as a minor edit, which does not notify page watchers (default false)",
+	}
+	return properties
+}
+
+// updateOptions returns the update options chosen by the version_message
+// and minor_edit inputs.
+func updateOptions(input map[string]interface{}) []confluence.UpdateOption {
+	message, ok := input["version_message"].(string)
+	if !ok {
+		message = defaultVersionMessage
+	}
+	minorEdit, _ := input["minor_edit"].(bool)
+	return []confluence.UpdateOption{
+		confluence.WithVersionMessage(message),
+		confluence.WithMinorEdit(minorEdit),
+	}
+}
+
+// updatePage publishes page, merging against base_version when the input
+// provides one.
+func (s *Server) updatePage(ctx context.Context, input map[string]interface{}, pageID, title string, page *storage.Page) (interface{}, error) {
+	if err := s.requireEdit(ctx, pageID); err != nil {
+		return nil, err
+	}
+	if baseVersion, ok := input["base_version"].(float64); ok {
+		return s.updatePageFromBase(ctx, pageID, title, page, int(baseVersion), updateOptions(input)...)
+	}
+
 	// Get current version
 	_, info, err := s.client.GetPageStorageRaw(ctx, pageID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current version: %w", err)
 	}
 
-	if err := s.client.UpdatePageStorage(ctx, pageID, page, info.Version, title); err != nil {
+	if err := s.client.UpdatePageStorage(ctx, pageID, page, info.Version, title, updateOptions(input)...); err != nil {
 		return nil, err
 	}
 
@@ -82,6 +226,48 @@ func (s *Server) handleUpdatePage(ctx context.Context, input map[string]interfac
 	}, nil
 }
 
+// updatePageFromBase publishes page as an edit of baseVersion, merging any
+// changes made since. Conflicts are reported as a result rather than an
+// error so the caller can resolve them and retry.
+func (s *Server) updatePageFromBase(ctx context.Context, pageID, title string, page *storage.Page, baseVersion int, opts ...confluence.UpdateOption) (interface{}, error) {
+	result, err := s.client.UpdatePageStorageFromBase(ctx, pageID, page, baseVersion, title, opts...)
+	var conflictErr *confluence.MergeConflictError
+	if errors.As(err, &conflictErr) {
+		conflicts := make([]interface{}, len(conflictErr.Conflicts))
+		for i, c := range conflictErr.Conflicts {
+			conflicts[i] = map[string]interface{}{
+				"base_start": c.BaseStart,
+				"base_end":   c.BaseEnd,
+				"base":       blocksToJSON(c.Base),
+				"theirs":     blocksToJSON(c.Theirs),
+				"ours":       blocksToJSON(c.Ours),
+			}
+		}
+		result := map[string]interface{}{
+			"status":          "conflict",
+			"page_id":         pageID,
+			"base_version":    conflictErr.BaseVersion,
+			"current_version": conflictErr.CurrentVersion,
+			"conflicts":       conflicts,
+		}
+		if conflictErr.Unmergeable != nil {
+			result["reason"] = conflictErr.Unmergeable.Error()
+		}
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"status":  "updated",
+		"page_id": pageID,
+		"title":   title,
+		"version": result.Version,
+		"merged":  result.Merged,
+	}, nil
+}
+
 func (s *Server) handleUpdatePageXHTML(ctx context.Context, input map[string]interface{}) (interface{}, error) {
 	pageID, _ := input["page_id"].(string)
 	title, _ := input["title"].(string)
@@ -96,6 +282,9 @@ func (s *Server) handleUpdatePageXHTML(ctx context.Context, input map[string]int
 	if xhtml == "" {
 		return nil, fmt.Errorf("xhtml is required")
 	}
+	if err := s.requireEdit(ctx, pageID); err != nil {
+		return nil, err
+	}
 
 	// Get current version
 	_, info, err := s.client.GetPageStorageRaw(ctx, pageID)
@@ -103,7 +292,7 @@ func (s *Server) handleUpdatePageXHTML(ctx context.Context, input map[string]int
 		return nil, fmt.Errorf("failed to get current version: %w", err)
 	}
 
-	if err := s.client.UpdatePageStorageRaw(ctx, pageID, xhtml, info.Version, title); err != nil {
+	if err := s.client.UpdatePageStorageRaw(ctx, pageID, xhtml, info.Version, title, updateOptions(input)...); err != nil {
 		return nil, err
 	}
 
@@ -133,6 +322,10 @@ func (s *Server) handleCreatePage(ctx context.Context, input map[string]interfac
 		return nil, fmt.Errorf("invalid blocks: %w", err)
 	}
 
+	return s.createPage(ctx, spaceKey, title, page, parentID)
+}
+
+func (s *Server) createPage(ctx context.Context, spaceKey, title string, page *storage.Page, parentID string) (interface{}, error) {
 	pageID, err := s.client.CreatePage(ctx, spaceKey, title, page, parentID)
 	if err != nil {
 		return nil, err
@@ -201,6 +394,9 @@ func (s *Server) handleDeletePage(ctx context.Context, input map[string]interfac
 		return nil, fmt.Errorf("page_id is required")
 	}
 
+	if err := s.requireEdit(ctx, pageID); err != nil {
+		return nil, err
+	}
 	if err := s.client.DeletePage(ctx, pageID); err != nil {
 		return nil, err
 	}
@@ -242,3 +438,9 @@ func (s *Server) handleSearchPages(ctx context.Context, input map[string]interfa
 		"results": results,
 	}, nil
 }
+
+// isNotFound reports whether err is an API error with status 404.
+func isNotFound(err error) bool {
+	var apiErr *confluence.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,138 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+)
+
+// labelTools returns tools that manage page labels.
+func labelTools() []Tool {
+	return []Tool{
+		{
+			Name:        "confluence_list_labels",
+			Description: "List the labels of a Confluence page",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The Confluence page ID",
+					},
+				},
+				"required": []string{"page_id"},
+			},
+		},
+		{
+			Name:        "confluence_add_labels",
+			Description: "Add labels to a Confluence page. Labels are stored in lower case and must not contain spaces; labels the page already has are kept.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The Confluence page ID",
+					},
+					"labels": map[string]interface{}{
+						"type":        "array",
+						"description": "Labels to add",
+						"items":       map[string]string{"type": "string"},
+					},
+				},
+				"required": []string{"page_id", "labels"},
+			},
+		},
+		{
+			Name:        "confluence_remove_label",
+			Description: "Remove a label from a Confluence page",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The Confluence page ID",
+					},
+					"label": map[string]interface{}{
+						"type":        "string",
+						"description": "The label to remove",
+					},
+				},
+				"required": []string{"page_id", "label"},
+			},
+		},
+	}
+}
+
+func (s *Server) handleListLabels(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	if pageID == "" {
+		return nil, fmt.Errorf("page_id is required")
+	}
+
+	labels, err := s.client.GetLabels(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"page_id": pageID,
+		"labels":  labels,
+		"count":   len(labels),
+	}, nil
+}
+
+func (s *Server) handleAddLabels(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	labelsRaw, _ := input["labels"].([]interface{})
+
+	if pageID == "" {
+		return nil, fmt.Errorf("page_id is required")
+	}
+	names := make([]string, 0, len(labelsRaw))
+	for _, l := range labelsRaw {
+		if name, ok := l.(string); ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("labels is required")
+	}
+
+	if err := s.requireEdit(ctx, pageID); err != nil {
+		return nil, err
+	}
+	labels, err := s.client.AddLabels(ctx, pageID, names...)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"status":  "updated",
+		"page_id": pageID,
+		"labels":  labels,
+	}, nil
+}
+
+func (s *Server) handleRemoveLabel(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	label, _ := input["label"].(string)
+
+	if pageID == "" {
+		return nil, fmt.Errorf("page_id is required")
+	}
+	if label == "" {
+		return nil, fmt.Errorf("label is required")
+	}
+
+	if err := s.requireEdit(ctx, pageID); err != nil {
+		return nil, err
+	}
+	if err := s.client.RemoveLabel(ctx, pageID, label); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"status":  "removed",
+		"page_id": pageID,
+		"label":   label,
+	}, nil
+}
@@ -0,0 +1,88 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+)
+
+func TestHandleLabels(t *testing.T) {
+	labels := []confluence.Label{{ID: "1", Prefix: "global", Name: "team-platform"}}
+	httpServer := httptest.NewServer(withEditPermission(true, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			var payload []confluence.Label
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				panic(err)
+			}
+			labels = append(labels, payload...)
+		case "DELETE":
+			name := r.URL.Query().Get("name")
+			kept := labels[:0]
+			for _, l := range labels {
+				if l.Name != name {
+					kept = append(kept, l)
+				}
+			}
+			labels = kept
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]interface{}{"results": labels}); err != nil {
+			panic(err)
+		}
+	})))
+	defer httpServer.Close()
+
+	server := New(confluence.NewClient(httpServer.URL, confluence.BasicAuth{}))
+	ctx := context.Background()
+
+	result, err := server.HandleTool(ctx, "confluence_add_labels", map[string]interface{}{
+		"page_id": "12345",
+		"labels":  []interface{}{"lifecycle-active"},
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if response := decodeResult(t, result); len(response["labels"].([]interface{})) != 2 {
+		t.Errorf("add_labels response = %v", response)
+	}
+
+	result, err = server.HandleTool(ctx, "confluence_remove_label", map[string]interface{}{
+		"page_id": "12345",
+		"label":   "team-platform",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if response := decodeResult(t, result); response["status"] != "removed" {
+		t.Errorf("remove_label response = %v", response)
+	}
+
+	result, err = server.HandleTool(ctx, "confluence_list_labels", map[string]interface{}{
+		"page_id": "12345",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	response := decodeResult(t, result)
+	list, _ := response["labels"].([]interface{})
+	if len(list) != 1 || list[0].(map[string]interface{})["name"] != "lifecycle-active" {
+		t.Errorf("list_labels response = %v", response)
+	}
+
+	result, err = server.HandleTool(ctx, "confluence_add_labels", map[string]interface{}{
+		"page_id": "12345",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError {
+		t.Error("add_labels without labels should fail")
+	}
+}
@@ -0,0 +1,97 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/agentplexus/mcp-confluence/storage"
+)
+
+// markdownTools returns tools that accept page content as Markdown.
+func markdownTools() []Tool {
+	return []Tool{
+		{
+			Name:        "confluence_create_page_markdown",
+			Description: "Create a new Confluence page from Markdown (CommonMark with GFM tables, task lists and strikethrough). Headings, formatted paragraphs, nested lists, tables, fenced code, blockquotes, links, images and horizontal rules are converted to Confluence Storage XHTML.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"space_key": map[string]interface{}{
+						"type":        "string",
+						"description": "The space key where the page will be created",
+					},
+					"title": map[string]interface{}{
+						"type":        "string",
+						"description": "The page title",
+					},
+					"markdown": map[string]interface{}{
+						"type":        "string",
+						"description": "The page content as Markdown",
+					},
+					"parent_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional parent page ID",
+					},
+				},
+				"required": []string{"space_key", "title", "markdown"},
+			},
+		},
+		{
+			Name:        "confluence_update_page_markdown",
+			Description: "Replace a Confluence page's content with Markdown (CommonMark with GFM tables, task lists and strikethrough), converted to Confluence Storage XHTML.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": withVersionOptions(map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The Confluence page ID",
+					},
+					"title": map[string]interface{}{
+						"type":        "string",
+						"description": "The page title",
+					},
+					"markdown": map[string]interface{}{
+						"type":        "string",
+						"description": "The page content as Markdown",
+					},
+					"base_version": map[string]interface{}{
+						"type":        "integer",
+						"description": "The page version the content was based on. If the page changed since, non-overlapping edits are merged and overlapping edits are returned as conflicts.",
+					},
+				}),
+				"required": []string{"page_id", "title", "markdown"},
+			},
+		},
+	}
+}
+
+func (s *Server) handleCreatePageMarkdown(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	spaceKey, _ := input["space_key"].(string)
+	title, _ := input["title"].(string)
+	parentID, _ := input["parent_id"].(string)
+	markdown, _ := input["markdown"].(string)
+
+	if spaceKey == "" {
+		return nil, fmt.Errorf("space_key is required")
+	}
+	if title == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+
+	return s.createPage(ctx, spaceKey, title, storage.ParseMarkdown(markdown), parentID)
+}
+
+func (s *Server) handleUpdatePageMarkdown(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	title, _ := input["title"].(string)
+	markdown, _ := input["markdown"].(string)
+
+	if pageID == "" {
+		return nil, fmt.Errorf("page_id is required")
+	}
+	if title == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+
+	return s.updatePage(ctx, input, pageID, title, storage.ParseMarkdown(markdown))
+}
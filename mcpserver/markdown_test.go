@@ -0,0 +1,140 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+)
+
+func TestHandleCreatePageMarkdown(t *testing.T) {
+	var body string
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("Expected POST request, got %s", r.Method)
+		}
+		var payload struct {
+			Body struct {
+				Storage struct {
+					Value string `json:"value"`
+				} `json:"storage"`
+			} `json:"body"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			panic(err)
+		}
+		body = payload.Body.Storage.Value
+
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write([]byte(`{"id": "67890"}`)); err != nil {
+			panic(err)
+		}
+	}))
+	defer httpServer.Close()
+
+	client := confluence.NewClient(httpServer.URL, confluence.BasicAuth{})
+	server := New(client)
+
+	result, err := server.HandleTool(context.Background(), "confluence_create_page_markdown", map[string]interface{}{
+		"space_key": "TEST",
+		"title":     "Notes",
+		"markdown":  "# Notes\n\n- [ ] **Review** design\n\n| A | B |\n|---|---|\n| 1 | 2 |",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+
+	response := decodeResult(t, result)
+	if response["page_id"] != "67890" {
+		t.Errorf("Response page_id = %v, want 67890", response["page_id"])
+	}
+
+	want := "<h1>Notes</h1>" +
+		"<ac:task-list><ac:task><ac:task-status>incomplete</ac:task-status><ac:task-body><strong>Review</strong> design</ac:task-body></ac:task></ac:task-list>" +
+		"<table><tbody><tr><th>A</th><th>B</th></tr><tr><td>1</td><td>2</td></tr></tbody></table>"
+	if body != want {
+		t.Errorf("Created body = %s, want %s", body, want)
+	}
+}
+
+func TestHandleUpdatePageMarkdown(t *testing.T) {
+	var body string
+	httpServer := httptest.NewServer(withEditPermission(true, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(pageResponse("12345", "Notes", "<p>Old</p>", 2)); err != nil {
+				panic(err)
+			}
+		case "PUT":
+			var payload struct {
+				Body struct {
+					Storage struct {
+						Value string `json:"value"`
+					} `json:"storage"`
+				} `json:"body"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				panic(err)
+			}
+			body = payload.Body.Storage.Value
+			w.Header().Set("Content-Type", "application/json")
+			if _, err := w.Write([]byte(`{"id": "12345"}`)); err != nil {
+				panic(err)
+			}
+		}
+	})))
+	defer httpServer.Close()
+
+	client := confluence.NewClient(httpServer.URL, confluence.BasicAuth{})
+	server := New(client)
+
+	result, err := server.HandleTool(context.Background(), "confluence_update_page_markdown", map[string]interface{}{
+		"page_id":  "12345",
+		"title":    "Notes",
+		"markdown": "New *content*",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+
+	response := decodeResult(t, result)
+	if response["version"] != float64(3) {
+		t.Errorf("Response version = %v, want 3", response["version"])
+	}
+	if body != "<p>New <em>content</em></p>" {
+		t.Errorf("Updated body = %s", body)
+	}
+}
+
+func TestParseBlockRichContent(t *testing.T) {
+	block, err := parseBlock(map[string]interface{}{
+		"type": "bullet_list",
+		"items": []interface{}{
+			"plain",
+			map[string]interface{}{
+				"text":  "formatted",
+				"spans": []interface{}{map[string]interface{}{"text": "formatted", "bold": true}},
+				"children": []interface{}{
+					map[string]interface{}{"type": "numbered_list", "items": []interface{}{"nested"}},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("parseBlock() error = %v", err)
+	}
+
+	got := blockToJSON(block)
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `{"items":["plain",{"children":[{"items":["nested"],"type":"numbered_list"}],"spans":[{"text":"formatted","bold":true}],"text":"formatted"}],"type":"bullet_list"}`
+	if string(data) != want {
+		t.Errorf("blockToJSON() = %s, want %s", data, want)
+	}
+}
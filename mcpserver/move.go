@@ -0,0 +1,160 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+)
+
+// moveTools returns tools that reorganise the page tree.
+func moveTools() []Tool {
+	return []Tool{
+		{
+			Name:        "confluence_move_page",
+			Description: "Move a Confluence page, with its descendants, under a new parent or next to a sibling",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The page to move",
+					},
+					"target_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The page to move it relative to",
+					},
+					"position": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{confluence.MoveAppend, confluence.MoveBefore, confluence.MoveAfter},
+						"description": "append makes the page the target's last child; before and after make it the target's sibling (default append)",
+					},
+				},
+				"required": []string{"page_id", "target_id"},
+			},
+		},
+		{
+			Name:        "confluence_copy_page",
+			Description: "Copy a Confluence page, or a whole subtree, under a parent page. Copies need titles that are unique in the destination space, so set title or a title prefix or suffix when copying within a space. Requires Confluence Cloud.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The page to copy",
+					},
+					"parent_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The parent page of the copy, in any space",
+					},
+					"title": map[string]interface{}{
+						"type":        "string",
+						"description": "Title of the copy of page_id",
+					},
+					"title_prefix": map[string]interface{}{
+						"type":        "string",
+						"description": "Text added before the titles of copies",
+					},
+					"title_suffix": map[string]interface{}{
+						"type":        "string",
+						"description": "Text added after the titles of copies",
+					},
+					"include_children": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Copy all descendants too (default false)",
+					},
+					"copy_attachments": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Copy attachments (default true)",
+					},
+					"copy_labels": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Copy labels (default true)",
+					},
+				},
+				"required": []string{"page_id", "parent_id"},
+			},
+		},
+	}
+}
+
+func (s *Server) handleMovePage(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	targetID, _ := input["target_id"].(string)
+	position, _ := input["position"].(string)
+
+	if pageID == "" {
+		return nil, fmt.Errorf("page_id is required")
+	}
+	if targetID == "" {
+		return nil, fmt.Errorf("target_id is required")
+	}
+	if position == "" {
+		position = confluence.MoveAppend
+	}
+
+	if err := s.requireEdit(ctx, pageID); err != nil {
+		return nil, err
+	}
+	if err := s.client.MovePage(ctx, pageID, position, targetID); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"status":    "moved",
+		"page_id":   pageID,
+		"position":  position,
+		"target_id": targetID,
+	}, nil
+}
+
+func (s *Server) handleCopyPage(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	parentID, _ := input["parent_id"].(string)
+	includeChildren, _ := input["include_children"].(bool)
+
+	if pageID == "" {
+		return nil, fmt.Errorf("page_id is required")
+	}
+	if parentID == "" {
+		return nil, fmt.Errorf("parent_id is required")
+	}
+
+	opts := confluence.CopyOptions{Attachments: true, Labels: true}
+	opts.Title, _ = input["title"].(string)
+	opts.TitlePrefix, _ = input["title_prefix"].(string)
+	opts.TitleSuffix, _ = input["title_suffix"].(string)
+	if v, ok := input["copy_attachments"].(bool); ok {
+		opts.Attachments = v
+	}
+	if v, ok := input["copy_labels"].(bool); ok {
+		opts.Labels = v
+	}
+
+	var root *confluence.PageNode
+	if includeChildren {
+		var err error
+		root, err = s.client.CopyPageTree(ctx, pageID, parentID, opts)
+		if err != nil {
+			if root != nil {
+				return nil, fmt.Errorf("copied %d pages under %s before failing: %w", root.Count(), root.ID, err)
+			}
+			return nil, err
+		}
+	} else {
+		info, err := s.client.CopyPage(ctx, pageID, parentID, opts)
+		if err != nil {
+			return nil, err
+		}
+		root = &confluence.PageNode{PageInfo: *info}
+	}
+
+	result := map[string]interface{}{
+		"status":    "copied",
+		"page_id":   root.ID,
+		"title":     root.Title,
+		"space_key": root.SpaceKey,
+	}
+	treeResult(result, []*confluence.PageNode{root})
+	return result, nil
+}
@@ -0,0 +1,96 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+)
+
+func TestHandleMovePage(t *testing.T) {
+	var moved string
+	httpServer := httptest.NewServer(withEditPermission(true, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		moved = r.Method + " " + r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"pageId": "12345"}`))
+	})))
+	defer httpServer.Close()
+
+	server := New(confluence.NewClient(httpServer.URL, confluence.BasicAuth{}))
+	result, err := server.HandleTool(context.Background(), "confluence_move_page", map[string]interface{}{
+		"page_id":   "12345",
+		"target_id": "678",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if response := decodeResult(t, result); response["status"] != "moved" {
+		t.Errorf("move_page response = %v", response)
+	}
+	if moved != "PUT /rest/api/content/12345/move/append/678" {
+		t.Errorf("request = %s", moved)
+	}
+}
+
+func TestHandleCopyPage(t *testing.T) {
+	titles := map[string]string{"1": "Template", "2": "Checklist"}
+	children := map[string][]string{"1": {"2"}}
+	var payloads []map[string]interface{}
+	page := func(id string) map[string]interface{} {
+		return map[string]interface{}{"id": id, "title": titles[id], "space": map[string]string{"key": "OPS"}}
+	}
+
+	httpServer := httptest.NewServer(withEditPermission(true, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/rest/api/content/"), "/")
+		var out interface{}
+		switch {
+		case len(parts) == 2 && parts[1] == "copy":
+			var payload map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				panic(err)
+			}
+			payloads = append(payloads, payload)
+			id := fmt.Sprint(100 + len(payloads))
+			titles[id], _ = payload["pageTitle"].(string)
+			out = page(id)
+		case len(parts) == 3:
+			results := []interface{}{}
+			for _, id := range children[parts[0]] {
+				results = append(results, page(id))
+			}
+			out = map[string]interface{}{"results": results}
+		default:
+			out = page(parts[0])
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(out); err != nil {
+			panic(err)
+		}
+	})))
+	defer httpServer.Close()
+
+	server := New(confluence.NewClient(httpServer.URL, confluence.BasicAuth{}))
+	result, err := server.HandleTool(context.Background(), "confluence_copy_page", map[string]interface{}{
+		"page_id":          "1",
+		"parent_id":        "50",
+		"title_prefix":     "Q3 ",
+		"include_children": true,
+		"copy_attachments": false,
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	response := decodeResult(t, result)
+	want := "- Q3 Template (101)\n  - Q3 Checklist (102)\n"
+	if response["pages"] != float64(2) || response["tree"] != want || response["page_id"] != "101" {
+		t.Errorf("copy_page response = %v, want tree %q", response, want)
+	}
+	if len(payloads) != 2 || payloads[0]["copyAttachments"] != false || payloads[0]["copyLabels"] != true {
+		t.Errorf("copy payloads = %v", payloads)
+	}
+}
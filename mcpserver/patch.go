@@ -0,0 +1,180 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+	"github.com/agentplexus/mcp-confluence/storage"
+)
+
+// patchTools returns tools for targeted edits of existing pages.
+func patchTools() []Tool {
+	return []Tool{
+		{
+			Name:        "confluence_patch_page",
+			Description: "Apply targeted edits to a Confluence page without resending its full content. Reads the page, applies the operations in order, validates and publishes the result in one call. Operations: insert, replace, remove (path /blocks/N), set_cell (/blocks/N/rows/R/cells/C or /blocks/N/headers/C), append_item (/blocks/N/items or /blocks/N/items/K), replace_text (/blocks/N with old and new, also in nested list items, tasks and quotes). In place of /blocks/N, paths from confluence_select_blocks address nested blocks, e.g. /blocks/5/items/0/children/1. Refuses pages it could not republish without losing markup.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": withVersionOptions(map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The Confluence page ID",
+					},
+					"ops": map[string]interface{}{
+						"type":        "array",
+						"description": "Patch operations, applied in order",
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"op": map[string]interface{}{
+									"type": "string",
+									"enum": []string{
+										storage.PatchInsert, storage.PatchReplace, storage.PatchRemove,
+										storage.PatchSetCell, storage.PatchAppendItem, storage.PatchReplaceText,
+									},
+								},
+								"path":  map[string]string{"type": "string"},
+								"block": map[string]string{"type": "object"},
+								"cell":  map[string]interface{}{"oneOf": []map[string]string{{"type": "string"}, {"type": "object"}}},
+								"item":  map[string]string{"type": "string"},
+								"old":   map[string]string{"type": "string"},
+								"new":   map[string]string{"type": "string"},
+							},
+							"required": []string{"op", "path"},
+						},
+					},
+					"title": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional new page title (defaults to the current title)",
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Return the resulting diff without publishing (default false)",
+					},
+				}),
+				"required": []string{"page_id", "ops"},
+			},
+		},
+	}
+}
+
+func (s *Server) handlePatchPage(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	opsRaw, _ := input["ops"].([]interface{})
+	title, _ := input["title"].(string)
+	dryRun, _ := input["dry_run"].(bool)
+
+	if pageID == "" {
+		return nil, fmt.Errorf("page_id is required")
+	}
+	if len(opsRaw) == 0 {
+		return nil, fmt.Errorf("ops is required")
+	}
+
+	patch, err := parsePatch(opsRaw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ops: %w", err)
+	}
+
+	current, info, err := s.getPageForEdit(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := storage.Apply(current, patch)
+	if err != nil {
+		return nil, err
+	}
+	if title == "" {
+		title = info.Title
+	}
+
+	return s.publishEdit(ctx, pageID, title, current, patched, info.Version, dryRun, updateOptions(input)...)
+}
+
+// getPageForEdit fetches a page to be edited through the block model and
+// republished. It refuses pages containing markup the block model does not
+// preserve, such as layouts, mentions or inline comments, since
+// republishing them would delete that markup from the page. Every tool that
+// edits the parsed page and republishes it fetches the page here.
+func (s *Server) getPageForEdit(ctx context.Context, pageID string) (*storage.Page, *confluence.PageInfo, error) {
+	xhtml, info, err := s.client.GetPageStorageRaw(ctx, pageID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := storage.CheckRoundTrip(xhtml); err != nil {
+		return nil, nil, fmt.Errorf("refusing to edit page %s: %w; edit it with confluence_update_page_xhtml instead", pageID, err)
+	}
+	page, err := storage.Parse(xhtml)
+	if err != nil {
+		return nil, nil, err
+	}
+	return page, info, nil
+}
+
+// publishEdit publishes edited as the next version of a page read at
+// version, returning the block diff from current. With dryRun it only
+// returns the diff.
+func (s *Server) publishEdit(ctx context.Context, pageID, title string, current, edited *storage.Page, version int, dryRun bool, opts ...confluence.UpdateOption) (map[string]interface{}, error) {
+	diff := storage.Diff(current, edited).Unified()
+	if dryRun {
+		return map[string]interface{}{
+			"status":  "dry_run",
+			"page_id": pageID,
+			"title":   title,
+			"version": version,
+			"diff":    diff,
+		}, nil
+	}
+
+	if err := s.requireEdit(ctx, pageID); err != nil {
+		return nil, err
+	}
+	if err := s.client.UpdatePageStorage(ctx, pageID, edited, version, title, opts...); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"status":  "updated",
+		"page_id": pageID,
+		"title":   title,
+		"version": version + 1,
+		"diff":    diff,
+	}, nil
+}
+
+// parsePatch converts JSON operations to a storage.Patch.
+func parsePatch(opsRaw []interface{}) (storage.Patch, error) {
+	patch := make(storage.Patch, 0, len(opsRaw))
+	for i, raw := range opsRaw {
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("op %d must be an object", i)
+		}
+
+		op := storage.PatchOp{}
+		op.Op, _ = m["op"].(string)
+		op.Path, _ = m["path"].(string)
+		op.Old, _ = m["old"].(string)
+		op.New, _ = m["new"].(string)
+
+		if blockRaw, ok := m["block"]; ok {
+			block, err := parseBlock(blockRaw)
+			if err != nil {
+				return nil, fmt.Errorf("op %d: %w", i, err)
+			}
+			op.Block = block
+		}
+		if cellRaw, ok := m["cell"]; ok {
+			cell := parseCell(cellRaw)
+			op.Cell = &cell
+		}
+		if item, ok := m["item"].(string); ok {
+			op.Item = &storage.ListItem{Text: item}
+		}
+
+		patch = append(patch, op)
+	}
+	return patch, nil
+}
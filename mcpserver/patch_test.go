@@ -0,0 +1,183 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+)
+
+const patchTestXHTML = `<h1>Services</h1>` +
+	`<table><tbody><tr><th>Service</th><th>Owner</th></tr><tr><td>Auth</td><td>Alice</td></tr></tbody></table>`
+
+func TestHandlePatchPage(t *testing.T) {
+	var published string
+	httpServer := httptest.NewServer(withEditPermission(true, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case "GET":
+			if err := json.NewEncoder(w).Encode(pageResponse("12345", "Services", patchTestXHTML, 7)); err != nil {
+				panic(err)
+			}
+		case "PUT":
+			var payload struct {
+				Title string `json:"title"`
+				Body  struct {
+					Storage struct {
+						Value string `json:"value"`
+					} `json:"storage"`
+				} `json:"body"`
+				Version struct {
+					Number    int    `json:"number"`
+					Message   string `json:"message"`
+					MinorEdit bool   `json:"minorEdit"`
+				} `json:"version"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				panic(err)
+			}
+			if payload.Version.Number != 8 {
+				t.Errorf("Update version = %d, want 8", payload.Version.Number)
+			}
+			if payload.Version.Message != "Reassign owner" || !payload.Version.MinorEdit {
+				t.Errorf("Update version = %+v, want message and minor edit", payload.Version)
+			}
+			if payload.Title != "Services" {
+				t.Errorf("Update title = %q, want current title", payload.Title)
+			}
+			published = payload.Body.Storage.Value
+			if _, err := w.Write([]byte(`{"id": "12345"}`)); err != nil {
+				panic(err)
+			}
+		}
+	})))
+	defer httpServer.Close()
+
+	client := confluence.NewClient(httpServer.URL, confluence.BasicAuth{})
+	server := New(client)
+
+	result, err := server.HandleTool(context.Background(), "confluence_patch_page", map[string]interface{}{
+		"page_id":         "12345",
+		"version_message": "Reassign owner",
+		"minor_edit":      true,
+		"ops": []interface{}{
+			map[string]interface{}{"op": "set_cell", "path": "/blocks/1/rows/0/cells/1", "cell": "Carol"},
+			map[string]interface{}{
+				"op":    "insert",
+				"path":  "/blocks/-",
+				"block": map[string]interface{}{"type": "paragraph", "text": "Updated weekly."},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	response := decodeResult(t, result)
+
+	if response["status"] != "updated" {
+		t.Errorf("Response status = %v, want updated", response["status"])
+	}
+	if !strings.Contains(published, "<td>Carol</td>") || !strings.Contains(published, "<p>Updated weekly.</p>") {
+		t.Errorf("Published XHTML = %q", published)
+	}
+	if strings.Contains(published, "Alice") {
+		t.Errorf("Published XHTML still contains replaced cell: %q", published)
+	}
+}
+
+func TestHandlePatchPage_DryRun(t *testing.T) {
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("dry run made a %s request", r.Method)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(pageResponse("12345", "Services", patchTestXHTML, 7)); err != nil {
+			panic(err)
+		}
+	}))
+	defer httpServer.Close()
+
+	client := confluence.NewClient(httpServer.URL, confluence.BasicAuth{})
+	server := New(client)
+
+	result, err := server.HandleTool(context.Background(), "confluence_patch_page", map[string]interface{}{
+		"page_id": "12345",
+		"dry_run": true,
+		"ops": []interface{}{
+			map[string]interface{}{"op": "replace_text", "path": "/blocks/0", "old": "Services", "new": "Systems"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	response := decodeResult(t, result)
+
+	if response["status"] != "dry_run" {
+		t.Errorf("Response status = %v, want dry_run", response["status"])
+	}
+	if diff, _ := response["diff"].(string); !strings.Contains(diff, "+ # Systems") {
+		t.Errorf("Response diff = %q", diff)
+	}
+}
+
+func TestHandlePatchPage_InvalidOp(t *testing.T) {
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("failed patch made a %s request", r.Method)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(pageResponse("12345", "Services", patchTestXHTML, 7)); err != nil {
+			panic(err)
+		}
+	}))
+	defer httpServer.Close()
+
+	client := confluence.NewClient(httpServer.URL, confluence.BasicAuth{})
+	server := New(client)
+
+	result, err := server.HandleTool(context.Background(), "confluence_patch_page", map[string]interface{}{
+		"page_id": "12345",
+		"ops": []interface{}{
+			map[string]interface{}{"op": "set_cell", "path": "/blocks/0/rows/0/cells/0", "cell": "x"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError {
+		t.Error("HandleTool() should return error result when an op fails")
+	}
+}
+
+func TestHandlePatchPage_LossyPage(t *testing.T) {
+	xhtml := `<ac:layout><ac:layout-section ac:type="single"><ac:layout-cell>` + patchTestXHTML +
+		`</ac:layout-cell></ac:layout-section></ac:layout>`
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("patch of a lossy page made a %s request", r.Method)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(pageResponse("12345", "Services", xhtml, 7)); err != nil {
+			panic(err)
+		}
+	}))
+	defer httpServer.Close()
+
+	server := New(confluence.NewClient(httpServer.URL, confluence.BasicAuth{}))
+	result, err := server.HandleTool(context.Background(), "confluence_patch_page", map[string]interface{}{
+		"page_id": "12345",
+		"ops": []interface{}{
+			map[string]interface{}{"op": "replace_text", "path": "/blocks/0", "old": "Services", "new": "Systems"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "ac:layout") {
+		t.Errorf("patch of a page with a layout = %+v, want error naming ac:layout", result)
+	}
+}
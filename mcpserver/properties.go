@@ -0,0 +1,186 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+)
+
+// propertyTools returns tools that manage page content properties.
+func propertyTools() []Tool {
+	return []Tool{
+		{
+			Name:        "confluence_get_properties",
+			Description: "Read the content properties of a Confluence page: JSON metadata such as owner or review date, stored apart from the page body",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The Confluence page ID",
+					},
+					"keys": map[string]interface{}{
+						"type":        "array",
+						"description": "Property keys to read (default all)",
+						"items":       map[string]string{"type": "string"},
+					},
+				},
+				"required": []string{"page_id"},
+			},
+		},
+		{
+			Name:        "confluence_set_property",
+			Description: "Create or update a content property of a Confluence page. Pass the version you read to fail if someone else changed the property since.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The Confluence page ID",
+					},
+					"key": map[string]interface{}{
+						"type":        "string",
+						"description": "The property key",
+					},
+					"value": map[string]interface{}{
+						"description": "The property value: any JSON value",
+					},
+					"version": map[string]interface{}{
+						"type":        "integer",
+						"description": "The property version last read. Omit to create the property or overwrite it unconditionally.",
+					},
+				},
+				"required": []string{"page_id", "key", "value"},
+			},
+		},
+		{
+			Name:        "confluence_delete_property",
+			Description: "Delete a content property of a Confluence page",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The Confluence page ID",
+					},
+					"key": map[string]interface{}{
+						"type":        "string",
+						"description": "The property key",
+					},
+				},
+				"required": []string{"page_id", "key"},
+			},
+		},
+	}
+}
+
+func (s *Server) handleGetProperties(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	keysRaw, _ := input["keys"].([]interface{})
+
+	if pageID == "" {
+		return nil, fmt.Errorf("page_id is required")
+	}
+
+	properties := []confluence.ContentProperty{}
+	var missing []string
+	if len(keysRaw) == 0 {
+		var err error
+		properties, err = s.client.ListProperties(ctx, pageID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, k := range keysRaw {
+		key, _ := k.(string)
+		if key == "" {
+			continue
+		}
+		property, err := s.client.GetProperty(ctx, pageID, key)
+		if isNotFound(err) {
+			missing = append(missing, key)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		properties = append(properties, *property)
+	}
+
+	result := map[string]interface{}{
+		"page_id":    pageID,
+		"properties": properties,
+		"count":      len(properties),
+	}
+	if len(missing) > 0 {
+		result["missing"] = missing
+	}
+	return result, nil
+}
+
+func (s *Server) handleSetProperty(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	key, _ := input["key"].(string)
+	value, hasValue := input["value"]
+	version, _ := input["version"].(float64)
+
+	if pageID == "" {
+		return nil, fmt.Errorf("page_id is required")
+	}
+	if key == "" {
+		return nil, fmt.Errorf("key is required")
+	}
+	if !hasValue {
+		return nil, fmt.Errorf("value is required")
+	}
+
+	if err := s.requireEdit(ctx, pageID); err != nil {
+		return nil, err
+	}
+	property, err := s.client.SetProperty(ctx, pageID, key, value, int(version))
+	var apiErr *confluence.APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict {
+		return nil, fmt.Errorf("property %q has changed since version %d; read it again and retry", key, int(version))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	status := "updated"
+	if property.Version == 1 {
+		status = "created"
+	}
+	return map[string]interface{}{
+		"status":   status,
+		"page_id":  pageID,
+		"property": property,
+	}, nil
+}
+
+func (s *Server) handleDeleteProperty(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	key, _ := input["key"].(string)
+
+	if pageID == "" {
+		return nil, fmt.Errorf("page_id is required")
+	}
+	if key == "" {
+		return nil, fmt.Errorf("key is required")
+	}
+
+	if err := s.requireEdit(ctx, pageID); err != nil {
+		return nil, err
+	}
+	if err := s.client.DeleteProperty(ctx, pageID, key); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"status":  "deleted",
+		"page_id": pageID,
+		"key":     key,
+	}, nil
+}
@@ -0,0 +1,96 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+)
+
+func TestHandleProperties(t *testing.T) {
+	var updates []map[string]interface{}
+	httpServer := httptest.NewServer(withEditPermission(true, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/rest/api/content/12345/property/")
+		var out interface{}
+		switch {
+		case r.Method == "PUT":
+			var payload map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				panic(err)
+			}
+			updates = append(updates, payload)
+			if payload["version"].(map[string]interface{})["number"] != float64(3) {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			out = payload
+		case key == "owner":
+			out = map[string]interface{}{
+				"key":     "owner",
+				"value":   map[string]string{"team": "payments"},
+				"version": map[string]int{"number": 2},
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(out); err != nil {
+			panic(err)
+		}
+	})))
+	defer httpServer.Close()
+
+	server := New(confluence.NewClient(httpServer.URL, confluence.BasicAuth{}))
+	ctx := context.Background()
+
+	result, err := server.HandleTool(ctx, "confluence_get_properties", map[string]interface{}{
+		"page_id": "12345",
+		"keys":    []interface{}{"owner", "review-date"},
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	response := decodeResult(t, result)
+	properties, _ := response["properties"].([]interface{})
+	missing, _ := response["missing"].([]interface{})
+	if len(properties) != 1 || len(missing) != 1 || missing[0] != "review-date" {
+		t.Fatalf("get_properties response = %v", response)
+	}
+	if value := properties[0].(map[string]interface{})["value"]; value.(map[string]interface{})["team"] != "payments" {
+		t.Errorf("property value = %v", value)
+	}
+
+	result, err = server.HandleTool(ctx, "confluence_set_property", map[string]interface{}{
+		"page_id": "12345",
+		"key":     "owner",
+		"value":   map[string]interface{}{"team": "ledger"},
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if response := decodeResult(t, result); response["status"] != "updated" {
+		t.Errorf("set_property response = %v", response)
+	}
+
+	// The property is at version 2, so an update based on version 1 is stale.
+	result, err = server.HandleTool(ctx, "confluence_set_property", map[string]interface{}{
+		"page_id": "12345",
+		"key":     "owner",
+		"value":   "ledger",
+		"version": float64(1),
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "changed since version 1") {
+		t.Errorf("set_property with stale version = %+v", result)
+	}
+	if len(updates) != 2 {
+		t.Errorf("sent %d updates, want 2", len(updates))
+	}
+}
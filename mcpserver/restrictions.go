@@ -0,0 +1,140 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+)
+
+// restrictionTools returns tools that inspect and change page restrictions.
+func restrictionTools() []Tool {
+	stringList := func(description string) map[string]interface{} {
+		return map[string]interface{}{
+			"type":        "array",
+			"description": description,
+			"items":       map[string]string{"type": "string"},
+		}
+	}
+	return []Tool{
+		{
+			Name:        "confluence_get_restrictions",
+			Description: "Report who a Confluence page's viewing and editing is restricted to, and whether the current user can edit it (can_edit is null where this cannot be checked, as on Data Center). Use before editing or sharing a page.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The Confluence page ID",
+					},
+				},
+				"required": []string{"page_id"},
+			},
+		},
+		{
+			Name:        "confluence_set_restrictions",
+			Description: "Restrict viewing or editing of a Confluence page to users and groups. Lists you omit keep their current value; pass an empty list to lift a restriction. Refused unless the current user can edit the page. Requires Confluence Cloud.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The Confluence page ID",
+					},
+					"read_users":    stringList("Account IDs of users who may view the page"),
+					"read_groups":   stringList("Names of groups whose members may view the page"),
+					"update_users":  stringList("Account IDs of users who may edit the page"),
+					"update_groups": stringList("Names of groups whose members may edit the page"),
+				},
+				"required": []string{"page_id"},
+			},
+		},
+	}
+}
+
+func (s *Server) handleGetRestrictions(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	if pageID == "" {
+		return nil, fmt.Errorf("page_id is required")
+	}
+
+	restrictions, err := s.client.GetRestrictions(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+	// The permission check needs Confluence Cloud; elsewhere report
+	// can_edit as unknown.
+	var canEdit interface{}
+	if ok, err := s.client.CanEdit(ctx, pageID); err == nil {
+		canEdit = ok
+	}
+
+	return map[string]interface{}{
+		"page_id":    pageID,
+		"read":       restrictions.Read,
+		"update":     restrictions.Update,
+		"restricted": !restrictions.Read.Empty() || !restrictions.Update.Empty(),
+		"can_edit":   canEdit,
+	}, nil
+}
+
+func (s *Server) handleSetRestrictions(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	if pageID == "" {
+		return nil, fmt.Errorf("page_id is required")
+	}
+
+	if err := s.requireEdit(ctx, pageID); err != nil {
+		return nil, err
+	}
+	restrictions, err := s.client.GetRestrictions(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+	setRestriction(&restrictions.Read, input, "read")
+	setRestriction(&restrictions.Update, input, "update")
+
+	if err := s.client.SetRestrictions(ctx, pageID, restrictions); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"status":  "updated",
+		"page_id": pageID,
+		"read":    restrictions.Read,
+		"update":  restrictions.Update,
+	}, nil
+}
+
+// setRestriction replaces the users and groups of r with the
+// <operation>_users and <operation>_groups inputs that are present.
+func setRestriction(r *confluence.Restriction, input map[string]interface{}, operation string) {
+	if users, ok := input[operation+"_users"].([]interface{}); ok {
+		r.Users = []confluence.User{}
+		for _, u := range users {
+			if id, ok := u.(string); ok && id != "" {
+				r.Users = append(r.Users, confluence.User{AccountID: id})
+			}
+		}
+	}
+	if groups, ok := input[operation+"_groups"].([]interface{}); ok {
+		r.Groups = []string{}
+		for _, g := range groups {
+			if name, ok := g.(string); ok && name != "" {
+				r.Groups = append(r.Groups, name)
+			}
+		}
+	}
+}
+
+// requireEdit returns an error if the current user is not permitted to
+// edit the content, a page or a comment. Every tool that changes content
+// calls it before writing. Where the permission check is unavailable, as
+// on Confluence Data Center, the write is left for Confluence to refuse.
+func (s *Server) requireEdit(ctx context.Context, contentID string) error {
+	canEdit, err := s.client.CanEdit(ctx, contentID)
+	if err != nil || canEdit {
+		return nil
+	}
+	return fmt.Errorf("the current user is not permitted to edit %s", contentID)
+}
@@ -0,0 +1,156 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+)
+
+func TestHandleRestrictions(t *testing.T) {
+	canEdit := true
+	var saved []map[string]interface{}
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		var out interface{}
+		switch {
+		case r.URL.Path == "/rest/api/user/current":
+			out = map[string]string{"accountId": "acc-1"}
+		case strings.HasSuffix(r.URL.Path, "/permission/check"):
+			out = map[string]bool{"hasPermission": canEdit}
+		case r.Method == "PUT":
+			if err := json.NewDecoder(r.Body).Decode(&saved); err != nil {
+				panic(err)
+			}
+			out = map[string]string{}
+		default:
+			out = map[string]interface{}{
+				"read": map[string]interface{}{"restrictions": map[string]interface{}{
+					"group": map[string]interface{}{"results": []interface{}{map[string]string{"name": "staff"}}},
+				}},
+			}
+		}
+		if err := json.NewEncoder(w).Encode(out); err != nil {
+			panic(err)
+		}
+	}))
+	defer httpServer.Close()
+
+	server := New(confluence.NewClient(httpServer.URL, confluence.BasicAuth{}))
+	ctx := context.Background()
+
+	result, err := server.HandleTool(ctx, "confluence_get_restrictions", map[string]interface{}{
+		"page_id": "12345",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	response := decodeResult(t, result)
+	if response["restricted"] != true || response["can_edit"] != true {
+		t.Errorf("get_restrictions response = %v", response)
+	}
+
+	// Only the update restriction is given, so the read restriction is kept.
+	result, err = server.HandleTool(ctx, "confluence_set_restrictions", map[string]interface{}{
+		"page_id":      "12345",
+		"update_users": []interface{}{"acc-1"},
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if response := decodeResult(t, result); response["status"] != "updated" {
+		t.Errorf("set_restrictions response = %v", response)
+	}
+	read := saved[0]["restrictions"].(map[string]interface{})
+	update := saved[1]["restrictions"].(map[string]interface{})
+	if len(read["group"].([]interface{})) != 1 || len(update["user"].([]interface{})) != 1 {
+		t.Errorf("saved restrictions = %v", saved)
+	}
+
+	canEdit = false
+	saved = nil
+	result, err = server.HandleTool(ctx, "confluence_set_restrictions", map[string]interface{}{
+		"page_id":     "12345",
+		"read_groups": []interface{}{},
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError || saved != nil {
+		t.Errorf("set_restrictions without edit permission = %+v", result)
+	}
+}
+
+func TestWritesRequireEdit(t *testing.T) {
+	httpServer := httptest.NewServer(withEditPermission(false, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("refused write made a %s request", r.Method)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(pageResponse("12345", "Page", "<h1>Intro</h1><p>Text</p>", 2)); err != nil {
+			panic(err)
+		}
+	})))
+	defer httpServer.Close()
+
+	server := New(confluence.NewClient(httpServer.URL, confluence.BasicAuth{}))
+	writes := map[string]map[string]interface{}{
+		"confluence_update_page":          {"page_id": "12345", "title": "Page", "blocks": []interface{}{}},
+		"confluence_update_page_xhtml":    {"page_id": "12345", "title": "Page", "xhtml": "<p>x</p>"},
+		"confluence_update_page_markdown": {"page_id": "12345", "title": "Page", "markdown": "x"},
+		"confluence_replace_section":      {"page_id": "12345", "section": "Intro", "markdown": "x"},
+		"confluence_patch_page": {"page_id": "12345", "ops": []interface{}{
+			map[string]interface{}{"op": "remove", "path": "/blocks/1"},
+		}},
+		"confluence_restore_version":   {"page_id": "12345", "version": float64(1)},
+		"confluence_move_page":         {"page_id": "12345", "target_id": "67890"},
+		"confluence_delete_page":       {"page_id": "12345"},
+		"confluence_add_labels":        {"page_id": "12345", "labels": []interface{}{"draft"}},
+		"confluence_remove_label":      {"page_id": "12345", "label": "draft"},
+		"confluence_set_property":      {"page_id": "12345", "key": "owner", "value": "payments"},
+		"confluence_delete_property":   {"page_id": "12345", "key": "owner"},
+		"confluence_upload_attachment": {"page_id": "12345", "filename": "a.txt", "content_base64": "eA=="},
+		"confluence_delete_attachment": {"page_id": "12345", "filename": "a.txt"},
+		"confluence_add_comment":       {"page_id": "12345", "markdown": "x"},
+		"confluence_resolve_comment":   {"comment_id": "777"},
+		"confluence_delete_comment":    {"comment_id": "777"},
+	}
+	for name, input := range writes {
+		result, err := server.HandleTool(context.Background(), name, input)
+		if err != nil {
+			t.Fatalf("HandleTool(%s) error = %v", name, err)
+		}
+		if !result.IsError || !strings.Contains(result.Content[0].Text, "not permitted to edit") {
+			t.Errorf("%s without edit permission = %v", name, result.Content)
+		}
+	}
+}
+
+func TestHandleGetRestrictions_NoPermissionCheck(t *testing.T) {
+	// Data Center has no permission check endpoint.
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/restriction/byOperation") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer httpServer.Close()
+
+	server := New(confluence.NewClient(httpServer.URL, confluence.BasicAuth{}))
+	result, err := server.HandleTool(context.Background(), "confluence_get_restrictions", map[string]interface{}{
+		"page_id": "12345",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	response := decodeResult(t, result)
+	if canEdit, ok := response["can_edit"]; !ok || canEdit != nil {
+		t.Errorf("can_edit = %v, want null", response["can_edit"])
+	}
+}
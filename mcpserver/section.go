@@ -0,0 +1,189 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/agentplexus/mcp-confluence/storage"
+)
+
+// sectionTools returns tools that navigate a page by its headings.
+func sectionTools() []Tool {
+	return []Tool{
+		{
+			Name:        "confluence_get_page_outline",
+			Description: "Get the heading outline of a Confluence page without its content: each section's path, block index range, approximate size in characters, and table and macro counts. Use it to decide which sections to read on long pages.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The Confluence page ID",
+					},
+				},
+				"required": []string{"page_id"},
+			},
+		},
+		{
+			Name:        "confluence_read_section",
+			Description: "Read one section of a Confluence page: a heading and everything under it up to the next heading of the same or higher level, including subsections. Address the section by heading text or by a path of headings such as \"Design/Risks\".",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The Confluence page ID",
+					},
+					"section": map[string]interface{}{
+						"type":        "string",
+						"description": "Heading text, or headings separated by \"/\" (case-insensitive; each may be nested at any depth below the previous). Write a \"/\" inside a heading as \"\\/\".",
+					},
+					"format": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"blocks", "markdown", "text"},
+						"description": "Content format (default blocks)",
+					},
+				},
+				"required": []string{"page_id", "section"},
+			},
+		},
+		{
+			Name:        "confluence_replace_section",
+			Description: "Replace the content of one section of a Confluence page, keeping its heading and leaving the rest of the page untouched. The section's subsections are replaced too. Provide the new content as blocks or as Markdown. The whole page is republished, so it fails on pages whose markup would not survive that.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": withVersionOptions(map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The Confluence page ID",
+					},
+					"section": map[string]interface{}{
+						"type":        "string",
+						"description": "Heading text or heading path, as for confluence_read_section",
+					},
+					"blocks": map[string]interface{}{
+						"type":        "array",
+						"description": "New section content as blocks (same format as confluence_update_page)",
+						"items":       map[string]string{"type": "object"},
+					},
+					"markdown": map[string]interface{}{
+						"type":        "string",
+						"description": "New section content as Markdown, instead of blocks",
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Return the resulting diff without publishing (default false)",
+					},
+				}),
+				"required": []string{"page_id", "section"},
+			},
+		},
+	}
+}
+
+func (s *Server) handleGetPageOutline(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	if pageID == "" {
+		return nil, fmt.Errorf("page_id is required")
+	}
+
+	page, info, err := s.client.GetPageStorage(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	outline := storage.Outline(page)
+	return map[string]interface{}{
+		"page_id":      info.ID,
+		"title":        info.Title,
+		"version":      info.Version,
+		"total_blocks": outline.Blocks,
+		"chars":        outline.Chars,
+		"tables":       outline.Tables,
+		"macros":       outline.Macros,
+		"sections":     outline.Sections,
+	}, nil
+}
+
+func (s *Server) handleReadSection(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	path, _ := input["section"].(string)
+	format, _ := input["format"].(string)
+
+	if pageID == "" {
+		return nil, fmt.Errorf("page_id is required")
+	}
+	if path == "" {
+		return nil, fmt.Errorf("section is required")
+	}
+	if format == "" {
+		format = "blocks"
+	}
+	if _, ok := blockSizers[format]; !ok {
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+
+	page, info, err := s.client.GetPageStorage(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	section, err := storage.FindSection(page, path)
+	if err != nil {
+		return nil, err
+	}
+
+	subsections := []string{}
+	section.Walk(func(sub *storage.Section) { subsections = append(subsections, sub.Path) })
+
+	result := map[string]interface{}{
+		"page_id":     info.ID,
+		"title":       info.Title,
+		"version":     info.Version,
+		"section":     section.Path,
+		"level":       section.Heading.Level,
+		"subsections": subsections,
+	}
+	if err := setContent(result, format, &storage.Page{Blocks: page.Blocks[section.Start:section.End]}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Server) handleReplaceSection(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	path, _ := input["section"].(string)
+	blocksRaw, hasBlocks := input["blocks"].([]interface{})
+	markdown, hasMarkdown := input["markdown"].(string)
+	dryRun, _ := input["dry_run"].(bool)
+
+	if pageID == "" {
+		return nil, fmt.Errorf("page_id is required")
+	}
+	if path == "" {
+		return nil, fmt.Errorf("section is required")
+	}
+	if hasBlocks == hasMarkdown {
+		return nil, fmt.Errorf("exactly one of blocks or markdown is required")
+	}
+
+	content := storage.ParseMarkdown(markdown)
+	if hasBlocks {
+		var err error
+		if content, err = parseBlocks(blocksRaw); err != nil {
+			return nil, fmt.Errorf("invalid blocks: %w", err)
+		}
+	}
+
+	current, info, err := s.getPageForEdit(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	replaced, err := storage.ReplaceSection(current, path, content.Blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.publishEdit(ctx, pageID, info.Title, current, replaced, info.Version, dryRun, updateOptions(input)...)
+}
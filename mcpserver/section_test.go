@@ -0,0 +1,186 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+)
+
+const sectionTestXHTML = `<h1>Design</h1><p>Overview</p><h2>Risks</h2><p>Old risks</p><h1>Rollout</h1><p>Plan</p>`
+
+func TestHandleGetPageOutline(t *testing.T) {
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(pageResponse("12345", "Spec", sectionTestXHTML, 4)); err != nil {
+			panic(err)
+		}
+	}))
+	defer httpServer.Close()
+
+	client := confluence.NewClient(httpServer.URL, confluence.BasicAuth{})
+	server := New(client)
+
+	result, err := server.HandleTool(context.Background(), "confluence_get_page_outline", map[string]interface{}{
+		"page_id": "12345",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+
+	response := decodeResult(t, result)
+	if response["total_blocks"] != float64(6) || response["version"] != float64(4) {
+		t.Errorf("Response total_blocks = %v, version = %v", response["total_blocks"], response["version"])
+	}
+	sections, ok := response["sections"].([]interface{})
+	if !ok || len(sections) != 2 {
+		t.Fatalf("Response sections = %v", response["sections"])
+	}
+	design := sections[0].(map[string]interface{})
+	if design["path"] != "Design" || design["start"] != float64(0) || design["end"] != float64(4) {
+		t.Errorf("Design section = %v", design)
+	}
+	children, ok := design["children"].([]interface{})
+	if !ok || len(children) != 1 || children[0].(map[string]interface{})["path"] != "Design/Risks" {
+		t.Errorf("Design children = %v", design["children"])
+	}
+}
+
+func TestHandleReadSection(t *testing.T) {
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(pageResponse("12345", "Spec", sectionTestXHTML, 4)); err != nil {
+			panic(err)
+		}
+	}))
+	defer httpServer.Close()
+
+	client := confluence.NewClient(httpServer.URL, confluence.BasicAuth{})
+	server := New(client)
+
+	result, err := server.HandleTool(context.Background(), "confluence_read_section", map[string]interface{}{
+		"page_id": "12345",
+		"section": "design",
+		"format":  "text",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+
+	response := decodeResult(t, result)
+	if response["text"] != "Design\n\nOverview\n\nRisks\n\nOld risks\n" {
+		t.Errorf("Response text = %q", response["text"])
+	}
+	if response["section"] != "Design" || response["level"] != float64(1) {
+		t.Errorf("Response section = %v, level = %v", response["section"], response["level"])
+	}
+	if subs, ok := response["subsections"].([]interface{}); !ok || len(subs) != 1 || subs[0] != "Design/Risks" {
+		t.Errorf("Response subsections = %v", response["subsections"])
+	}
+
+	result, err = server.HandleTool(context.Background(), "confluence_read_section", map[string]interface{}{
+		"page_id": "12345",
+		"section": "Testing",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "Design/Risks") {
+		t.Errorf("HandleTool() should list available sections, got %v", result.Content)
+	}
+}
+
+func TestHandleReplaceSection(t *testing.T) {
+	var body string
+	httpServer := httptest.NewServer(withEditPermission(true, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case "GET":
+			if err := json.NewEncoder(w).Encode(pageResponse("12345", "Spec", sectionTestXHTML, 4)); err != nil {
+				panic(err)
+			}
+		case "PUT":
+			var payload struct {
+				Body struct {
+					Storage struct {
+						Value string `json:"value"`
+					} `json:"storage"`
+				} `json:"body"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				panic(err)
+			}
+			body = payload.Body.Storage.Value
+			if _, err := w.Write([]byte(`{"id": "12345"}`)); err != nil {
+				panic(err)
+			}
+		}
+	})))
+	defer httpServer.Close()
+
+	client := confluence.NewClient(httpServer.URL, confluence.BasicAuth{})
+	server := New(client)
+
+	result, err := server.HandleTool(context.Background(), "confluence_replace_section", map[string]interface{}{
+		"page_id":  "12345",
+		"section":  "Design/Risks",
+		"markdown": "- None *known*",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+
+	response := decodeResult(t, result)
+	if response["version"] != float64(5) {
+		t.Errorf("Response version = %v, want 5", response["version"])
+	}
+	want := `<h1>Design</h1><p>Overview</p><h2>Risks</h2><ul><li>None <em>known</em></li></ul><h1>Rollout</h1><p>Plan</p>`
+	if body != want {
+		t.Errorf("Updated body = %s, want %s", body, want)
+	}
+
+	result, err = server.HandleTool(context.Background(), "confluence_replace_section", map[string]interface{}{
+		"page_id":  "12345",
+		"section":  "Rollout",
+		"markdown": "x",
+		"blocks":   []interface{}{},
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError {
+		t.Error("HandleTool() should reject both blocks and markdown")
+	}
+}
+
+func TestHandleReplaceSection_LossyPage(t *testing.T) {
+	// The mention is outside the replaced section but would still be lost.
+	xhtml := sectionTestXHTML + `<p>Owner: <ac:link><ri:user ri:account-id="abc123" /></ac:link></p>`
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("replace_section on a lossy page made a %s request", r.Method)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(pageResponse("12345", "Spec", xhtml, 4)); err != nil {
+			panic(err)
+		}
+	}))
+	defer httpServer.Close()
+
+	server := New(confluence.NewClient(httpServer.URL, confluence.BasicAuth{}))
+	result, err := server.HandleTool(context.Background(), "confluence_replace_section", map[string]interface{}{
+		"page_id":  "12345",
+		"section":  "Design/Risks",
+		"markdown": "None",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "ri:user") {
+		t.Errorf("replace_section on a page with a mention = %+v, want error naming ri:user", result)
+	}
+}
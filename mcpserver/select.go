@@ -0,0 +1,76 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/agentplexus/mcp-confluence/storage"
+)
+
+// selectTools returns tools that find blocks with selectors.
+func selectTools() []Tool {
+	return []Tool{
+		{
+			Name:        "confluence_select_blocks",
+			Description: "Find blocks in a Confluence page with a selector, including blocks nested in lists, quotes and table cells. Returns each matched block with its path (usable in confluence_patch_page paths) and enclosing section. Example: table[header=Owner]:section(Owners):nth(2) is the second table with an Owner column under the heading Owners.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The Confluence page ID",
+					},
+					"selector": map[string]interface{}{
+						"type":        "string",
+						"description": "type[attr=value][attr*=value]:section(path):top:nth(N), every part optional. type is a block type or *. Attributes: text, header, name (macro), level (heading), language (code); = matches the whole value and *= a substring, ignoring case. :section limits matches to a heading section, :top to top-level blocks, :nth(N) keeps the Nth match.",
+					},
+				},
+				"required": []string{"page_id", "selector"},
+			},
+		},
+	}
+}
+
+func (s *Server) handleSelectBlocks(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	selector, _ := input["selector"].(string)
+
+	if pageID == "" {
+		return nil, fmt.Errorf("page_id is required")
+	}
+	if selector == "" {
+		return nil, fmt.Errorf("selector is required")
+	}
+
+	sel, err := storage.ParseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	page, info, err := s.client.GetPageStorage(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := sel.Select(page)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]interface{}, len(matches))
+	for i, m := range matches {
+		results[i] = map[string]interface{}{
+			"path":    m.Path,
+			"section": m.Section,
+			"block":   blockToJSON(m.Block),
+		}
+	}
+
+	return map[string]interface{}{
+		"page_id": info.ID,
+		"title":   info.Title,
+		"version": info.Version,
+		"count":   len(matches),
+		"matches": results,
+	}, nil
+}
@@ -0,0 +1,70 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+)
+
+func TestHandleSelectBlocks(t *testing.T) {
+	xhtml := `<h1>Owners</h1><table><tbody><tr><th>Team</th></tr><tr><td>API</td></tr></tbody></table>` +
+		`<table><tbody><tr><th>Owner</th></tr><tr><td>Ana</td></tr></tbody></table>` +
+		`<h1>Links</h1><ul><li>docs<ul><li>nested</li></ul></li></ul>`
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(pageResponse("12345", "Team", xhtml, 2)); err != nil {
+			panic(err)
+		}
+	}))
+	defer httpServer.Close()
+
+	client := confluence.NewClient(httpServer.URL, confluence.BasicAuth{})
+	server := New(client)
+
+	result, err := server.HandleTool(context.Background(), "confluence_select_blocks", map[string]interface{}{
+		"page_id":  "12345",
+		"selector": "table:section(Owners):nth(2)",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+
+	response := decodeResult(t, result)
+	matches, ok := response["matches"].([]interface{})
+	if !ok || len(matches) != 1 {
+		t.Fatalf("Response matches = %v", response["matches"])
+	}
+	match := matches[0].(map[string]interface{})
+	block := match["block"].(map[string]interface{})
+	if match["path"] != "/blocks/2" || match["section"] != "Owners" || block["type"] != "table" {
+		t.Errorf("Match = %v", match)
+	}
+
+	result, err = server.HandleTool(context.Background(), "confluence_select_blocks", map[string]interface{}{
+		"page_id":  "12345",
+		"selector": "bullet_list",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	response = decodeResult(t, result)
+	if response["count"] != float64(2) {
+		t.Errorf("Response count = %v, want nested list included", response["count"])
+	}
+
+	result, err = server.HandleTool(context.Background(), "confluence_select_blocks", map[string]interface{}{
+		"page_id":  "12345",
+		"selector": "tabel",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "unknown block type") {
+		t.Errorf("HandleTool() should reject an invalid selector, got %v", result.Content)
+	}
+}
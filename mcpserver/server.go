@@ -13,12 +13,29 @@ import (
 
 // Server is the MCP server for Confluence.
 type Server struct {
-	client *confluence.Client
+	client        *confluence.Client
+	attachmentDir string
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithAttachmentDir lets the attachment tools upload files from and
+// download files to dir and its subdirectories. Without it, attachment
+// content is only exchanged base64 encoded.
+func WithAttachmentDir(dir string) Option {
+	return func(s *Server) {
+		s.attachmentDir = dir
+	}
 }
 
 // New creates a new MCP server with the given Confluence client.
-func New(client *confluence.Client) *Server {
-	return &Server{client: client}
+func New(client *confluence.Client, opts ...Option) *Server {
+	s := &Server{client: client}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // Tool represents an MCP tool definition.
@@ -65,6 +82,82 @@ func (s *Server) HandleTool(ctx context.Context, name string, input map[string]i
 		result, err = s.handleDeletePage(ctx, input)
 	case "confluence_search_pages":
 		result, err = s.handleSearchPages(ctx, input)
+	case "confluence_normalize_xhtml":
+		result, err = s.handleNormalizeXHTML(ctx, input)
+	case "confluence_diff_page":
+		result, err = s.handleDiffPage(ctx, input)
+	case "confluence_patch_page":
+		result, err = s.handlePatchPage(ctx, input)
+	case "confluence_create_page_markdown":
+		result, err = s.handleCreatePageMarkdown(ctx, input)
+	case "confluence_update_page_markdown":
+		result, err = s.handleUpdatePageMarkdown(ctx, input)
+	case "confluence_get_page_outline":
+		result, err = s.handleGetPageOutline(ctx, input)
+	case "confluence_read_section":
+		result, err = s.handleReadSection(ctx, input)
+	case "confluence_replace_section":
+		result, err = s.handleReplaceSection(ctx, input)
+	case "confluence_select_blocks":
+		result, err = s.handleSelectBlocks(ctx, input)
+	case "confluence_export_table":
+		result, err = s.handleExportTable(ctx, input)
+	case "confluence_import_table":
+		result, err = s.handleImportTable(ctx, input)
+	case "confluence_edit_table":
+		result, err = s.handleEditTable(ctx, input)
+	case "confluence_list_templates":
+		result, err = s.handleListTemplates(ctx, input)
+	case "confluence_create_page_from_template":
+		result, err = s.handleCreatePageFromTemplate(ctx, input)
+	case "confluence_list_labels":
+		result, err = s.handleListLabels(ctx, input)
+	case "confluence_add_labels":
+		result, err = s.handleAddLabels(ctx, input)
+	case "confluence_remove_label":
+		result, err = s.handleRemoveLabel(ctx, input)
+	case "confluence_list_attachments":
+		result, err = s.handleListAttachments(ctx, input)
+	case "confluence_upload_attachment":
+		result, err = s.handleUploadAttachment(ctx, input)
+	case "confluence_download_attachment":
+		result, err = s.handleDownloadAttachment(ctx, input)
+	case "confluence_delete_attachment":
+		result, err = s.handleDeleteAttachment(ctx, input)
+	case "confluence_list_comments":
+		result, err = s.handleListComments(ctx, input)
+	case "confluence_add_comment":
+		result, err = s.handleAddComment(ctx, input)
+	case "confluence_resolve_comment":
+		result, err = s.handleResolveComment(ctx, input)
+	case "confluence_delete_comment":
+		result, err = s.handleDeleteComment(ctx, input)
+	case "confluence_list_versions":
+		result, err = s.handleListVersions(ctx, input)
+	case "confluence_read_page_version":
+		result, err = s.handleReadPageVersion(ctx, input)
+	case "confluence_restore_version":
+		result, err = s.handleRestoreVersion(ctx, input)
+	case "confluence_get_page_tree":
+		result, err = s.handleGetPageTree(ctx, input)
+	case "confluence_move_page":
+		result, err = s.handleMovePage(ctx, input)
+	case "confluence_copy_page":
+		result, err = s.handleCopyPage(ctx, input)
+	case "confluence_get_properties":
+		result, err = s.handleGetProperties(ctx, input)
+	case "confluence_set_property":
+		result, err = s.handleSetProperty(ctx, input)
+	case "confluence_delete_property":
+		result, err = s.handleDeleteProperty(ctx, input)
+	case "confluence_get_restrictions":
+		result, err = s.handleGetRestrictions(ctx, input)
+	case "confluence_set_restrictions":
+		result, err = s.handleSetRestrictions(ctx, input)
+	case "confluence_list_spaces":
+		result, err = s.handleListSpaces(ctx, input)
+	case "confluence_get_space":
+		result, err = s.handleGetSpace(ctx, input)
 	default:
 		return nil, fmt.Errorf("unknown tool: %s", name)
 	}
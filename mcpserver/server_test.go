@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/agentplexus/mcp-confluence/confluence"
@@ -39,6 +40,44 @@ func TestTools(t *testing.T) {
 		"confluence_create_table",
 		"confluence_delete_page",
 		"confluence_search_pages",
+		"confluence_normalize_xhtml",
+		"confluence_diff_page",
+		"confluence_patch_page",
+		"confluence_create_page_markdown",
+		"confluence_update_page_markdown",
+		"confluence_get_page_outline",
+		"confluence_read_section",
+		"confluence_replace_section",
+		"confluence_select_blocks",
+		"confluence_export_table",
+		"confluence_import_table",
+		"confluence_edit_table",
+		"confluence_list_templates",
+		"confluence_create_page_from_template",
+		"confluence_list_labels",
+		"confluence_add_labels",
+		"confluence_remove_label",
+		"confluence_list_attachments",
+		"confluence_upload_attachment",
+		"confluence_download_attachment",
+		"confluence_delete_attachment",
+		"confluence_list_comments",
+		"confluence_add_comment",
+		"confluence_resolve_comment",
+		"confluence_delete_comment",
+		"confluence_list_versions",
+		"confluence_read_page_version",
+		"confluence_restore_version",
+		"confluence_get_page_tree",
+		"confluence_move_page",
+		"confluence_copy_page",
+		"confluence_get_properties",
+		"confluence_set_property",
+		"confluence_delete_property",
+		"confluence_get_restrictions",
+		"confluence_set_restrictions",
+		"confluence_list_spaces",
+		"confluence_get_space",
 	}
 
 	if len(tools) != len(expectedTools) {
@@ -156,6 +195,122 @@ func TestHandleGetPage(t *testing.T) {
 	}
 }
 
+func TestHandleGetPage_MarkdownFormat(t *testing.T) {
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		xhtml := `<h1>Title</h1><p>Some <strong>bold</strong> text</p>` +
+			`<ac:structured-macro ac:name="info"><ac:rich-text-body><p>Note this</p></ac:rich-text-body></ac:structured-macro>`
+		if err := json.NewEncoder(w).Encode(pageResponse("12345", "Test Page", xhtml, 5)); err != nil {
+			panic(err)
+		}
+	}))
+	defer httpServer.Close()
+
+	client := confluence.NewClient(httpServer.URL, confluence.BasicAuth{})
+	server := New(client)
+
+	result, err := server.HandleTool(context.Background(), "confluence_read_page", map[string]interface{}{
+		"page_id": "12345",
+		"format":  "markdown",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+
+	response := decodeResult(t, result)
+	want := "# Title\n\nSome **bold** text\n\n> [!NOTE]\n> Note this\n"
+	if response["markdown"] != want {
+		t.Errorf("Response markdown = %q, want %q", response["markdown"], want)
+	}
+	if _, ok := response["blocks"]; ok {
+		t.Error("Response should not include blocks in markdown format")
+	}
+
+	result, err = server.HandleTool(context.Background(), "confluence_read_page", map[string]interface{}{
+		"page_id": "12345",
+		"format":  "html",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError {
+		t.Error("HandleTool() should return error for unsupported format")
+	}
+}
+
+func TestHandleGetPage_Paging(t *testing.T) {
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		xhtml := `<h1>Title</h1><p>First paragraph</p><p>Second paragraph</p><p>Third paragraph</p>`
+		if err := json.NewEncoder(w).Encode(pageResponse("12345", "Test Page", xhtml, 5)); err != nil {
+			panic(err)
+		}
+	}))
+	defer httpServer.Close()
+
+	client := confluence.NewClient(httpServer.URL, confluence.BasicAuth{})
+	server := New(client)
+
+	tests := []struct {
+		name  string
+		input map[string]interface{}
+		text  string
+		next  interface{}
+	}{
+		{
+			name:  "max_blocks",
+			input: map[string]interface{}{"max_blocks": float64(2)},
+			text:  "Title\n\nFirst paragraph\n",
+			next:  float64(2),
+		},
+		{
+			name:  "max_chars from offset",
+			input: map[string]interface{}{"offset": float64(1), "max_chars": float64(40)},
+			text:  "First paragraph\n\nSecond paragraph\n",
+			next:  float64(3),
+		},
+		{
+			name:  "oversized block still returned",
+			input: map[string]interface{}{"offset": float64(3), "max_chars": float64(1)},
+			text:  "Third paragraph\n",
+			next:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.input["page_id"] = "12345"
+			tt.input["format"] = "text"
+			result, err := server.HandleTool(context.Background(), "confluence_read_page", tt.input)
+			if err != nil {
+				t.Fatalf("HandleTool() error = %v", err)
+			}
+
+			response := decodeResult(t, result)
+			if response["text"] != tt.text {
+				t.Errorf("Response text = %q, want %q", response["text"], tt.text)
+			}
+			if response["next_offset"] != tt.next {
+				t.Errorf("Response next_offset = %v, want %v", response["next_offset"], tt.next)
+			}
+			if response["total_blocks"] != float64(4) {
+				t.Errorf("Response total_blocks = %v, want 4", response["total_blocks"])
+			}
+		})
+	}
+
+	result, err := server.HandleTool(context.Background(), "confluence_read_page", map[string]interface{}{
+		"page_id": "12345",
+		"offset":  float64(5),
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError {
+		t.Error("HandleTool() should return error for offset past the last block")
+	}
+}
+
 func TestHandleGetPage_MissingPageID(t *testing.T) {
 	client := confluence.NewClient("http://example.com", confluence.BasicAuth{})
 	server := New(client)
@@ -239,7 +394,7 @@ func TestHandleCreateTable_WithMacro(t *testing.T) {
 
 func TestHandleUpdatePage(t *testing.T) {
 	callCount := 0
-	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	httpServer := httptest.NewServer(withEditPermission(true, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		callCount++
 		switch r.Method {
 		case "GET":
@@ -276,13 +431,16 @@ func TestHandleUpdatePage(t *testing.T) {
 			if version["number"] != float64(6) {
 				t.Errorf("Update version = %v, want 6", version["number"])
 			}
+			if version["message"] != defaultVersionMessage {
+				t.Errorf("Update version message = %v, want %q", version["message"], defaultVersionMessage)
+			}
 
 			w.Header().Set("Content-Type", "application/json")
 			if _, err := w.Write([]byte(`{"id": "12345"}`)); err != nil {
 				panic(err)
 			}
 		}
-	}))
+	})))
 	defer httpServer.Close()
 
 	client := confluence.NewClient(httpServer.URL, confluence.BasicAuth{})
@@ -317,6 +475,53 @@ func TestHandleUpdatePage(t *testing.T) {
 	}
 }
 
+func TestHandleUpdatePage_BaseVersionConflict(t *testing.T) {
+	httpServer := httptest.NewServer(withEditPermission(true, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("Unexpected %s request for conflicting update", r.Method)
+			return
+		}
+		response := pageResponse("12345", "Test Page", "<p>A</p><p>B-theirs</p>", 6)
+		if r.URL.Query().Get("version") == "5" {
+			response = pageResponse("12345", "Test Page", "<p>A</p><p>B</p>", 5)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			panic(err)
+		}
+	})))
+	defer httpServer.Close()
+
+	client := confluence.NewClient(httpServer.URL, confluence.BasicAuth{})
+	server := New(client)
+
+	result, err := server.HandleTool(context.Background(), "confluence_update_page", map[string]interface{}{
+		"page_id":      "12345",
+		"title":        "Test Page",
+		"base_version": float64(5),
+		"blocks": []interface{}{
+			map[string]interface{}{"type": "paragraph", "text": "A"},
+			map[string]interface{}{"type": "paragraph", "text": "B-ours"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+
+	response := decodeResult(t, result)
+	if response["status"] != "conflict" {
+		t.Fatalf("Response status = %v, want conflict", response["status"])
+	}
+	conflicts, _ := response["conflicts"].([]interface{})
+	if len(conflicts) != 1 {
+		t.Fatalf("Response conflicts = %v, want 1", response["conflicts"])
+	}
+	theirs := conflicts[0].(map[string]interface{})["theirs"].([]interface{})
+	if theirs[0].(map[string]interface{})["text"] != "B-theirs" {
+		t.Errorf("Conflict theirs = %v, want B-theirs paragraph", theirs)
+	}
+}
+
 func TestHandleCreatePage(t *testing.T) {
 	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" {
@@ -373,12 +578,12 @@ func TestHandleCreatePage(t *testing.T) {
 }
 
 func TestHandleDeletePage(t *testing.T) {
-	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	httpServer := httptest.NewServer(withEditPermission(true, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "DELETE" {
 			t.Errorf("Expected DELETE request, got %s", r.Method)
 		}
 		w.WriteHeader(http.StatusNoContent)
-	}))
+	})))
 	defer httpServer.Close()
 
 	client := confluence.NewClient(httpServer.URL, confluence.BasicAuth{})
@@ -659,3 +864,58 @@ func TestParseCell(t *testing.T) {
 		})
 	}
 }
+
+// pageResponse returns a GET /rest/api/content/{id} response body.
+func pageResponse(id, title, xhtml string, version int) map[string]interface{} {
+	return map[string]interface{}{
+		"id":     id,
+		"type":   "page",
+		"status": "current",
+		"title":  title,
+		"body": map[string]interface{}{
+			"storage": map[string]string{
+				"value": xhtml,
+			},
+		},
+		"version": map[string]int{
+			"number": version,
+		},
+		"space": map[string]string{
+			"key": "TEST",
+		},
+	}
+}
+
+// decodeResult parses a successful tool result's JSON payload.
+func decodeResult(t *testing.T, result *ToolResult) map[string]interface{} {
+	t.Helper()
+	if result.IsError {
+		t.Fatalf("HandleTool() returned error: %v", result.Content)
+	}
+	var response map[string]interface{}
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &response); err != nil {
+		t.Fatalf("Failed to parse response JSON: %v", err)
+	}
+	return response
+}
+
+// withEditPermission answers the requests of confluence.Client.CanEdit,
+// reporting canEdit, and passes other requests to next.
+func withEditPermission(canEdit bool, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var out interface{}
+		switch {
+		case r.URL.Path == "/rest/api/user/current":
+			out = map[string]string{"accountId": "acc-1"}
+		case strings.HasSuffix(r.URL.Path, "/permission/check"):
+			out = map[string]bool{"hasPermission": canEdit}
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(out); err != nil {
+			panic(err)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+)
+
+// spaceTools returns tools that list and describe spaces.
+func spaceTools() []Tool {
+	return []Tool{
+		{
+			Name:        "confluence_list_spaces",
+			Description: "List Confluence spaces with their keys, names, descriptions and homepage IDs",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"type": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"global", "personal"},
+						"description": "Only list spaces of this type",
+					},
+					"status": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"current", "archived"},
+						"description": "Only list spaces with this status",
+					},
+					"labels": map[string]interface{}{
+						"type":        "array",
+						"description": "Only list spaces carrying any of these labels",
+						"items":       map[string]string{"type": "string"},
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "Maximum number of spaces (default 50, 0 for all)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "confluence_get_space",
+			Description: "Get a Confluence space's details and homepage, and optionally its page tree",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"space_key": map[string]interface{}{
+						"type":        "string",
+						"description": "The space key",
+					},
+					"depth": map[string]interface{}{
+						"type":        "integer",
+						"description": "Include the page tree down to this many levels below the top-level pages (-1 for all). Omit for no tree.",
+					},
+				},
+				"required": []string{"space_key"},
+			},
+		},
+	}
+}
+
+func (s *Server) handleListSpaces(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	var filter confluence.SpaceFilter
+	filter.Type, _ = input["type"].(string)
+	filter.Status, _ = input["status"].(string)
+	labelsRaw, _ := input["labels"].([]interface{})
+	for _, l := range labelsRaw {
+		if label, ok := l.(string); ok && label != "" {
+			filter.Labels = append(filter.Labels, label)
+		}
+	}
+	limit := 50
+	if l, ok := input["limit"].(float64); ok {
+		limit = int(l)
+	}
+
+	spaces, err := s.client.ListSpaces(ctx, filter, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"spaces": spaces,
+		"count":  len(spaces),
+	}, nil
+}
+
+func (s *Server) handleGetSpace(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	spaceKey, _ := input["space_key"].(string)
+	if spaceKey == "" {
+		return nil, fmt.Errorf("space_key is required")
+	}
+
+	space, err := s.client.GetSpace(ctx, spaceKey)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{
+		"space": space,
+	}
+	if space.HomepageID != "" {
+		home, err := s.client.GetPageInfo(ctx, space.HomepageID)
+		if err != nil {
+			return nil, err
+		}
+		result["homepage"] = map[string]interface{}{"id": home.ID, "title": home.Title}
+	}
+
+	if d, ok := input["depth"].(float64); ok {
+		roots, err := s.client.GetSpaceTree(ctx, spaceKey, int(d))
+		if err := truncatedResult(result, err); err != nil {
+			return nil, err
+		}
+		treeResult(result, roots)
+	}
+	return result, nil
+}
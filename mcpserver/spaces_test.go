@@ -0,0 +1,81 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+)
+
+func TestHandleSpaces(t *testing.T) {
+	space := map[string]interface{}{
+		"id":          7,
+		"key":         "DOCS",
+		"name":        "Documentation",
+		"type":        "global",
+		"description": map[string]interface{}{"plain": map[string]string{"value": "Product docs"}},
+		"homepage":    map[string]string{"id": "1"},
+	}
+	page := func(id, title string) map[string]interface{} {
+		return map[string]interface{}{"id": id, "title": title, "space": map[string]string{"key": "DOCS"}}
+	}
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var out interface{}
+		switch {
+		case r.URL.Path == "/rest/api/space":
+			if r.URL.Query().Get("status") != "current" {
+				t.Errorf("Unexpected query: %s", r.URL.RawQuery)
+			}
+			out = map[string]interface{}{"results": []interface{}{space}}
+		case r.URL.Path == "/rest/api/space/DOCS":
+			out = space
+		case r.URL.Path == "/rest/api/space/DOCS/content/page":
+			out = map[string]interface{}{"results": []interface{}{page("1", "Home")}}
+		case strings.HasSuffix(r.URL.Path, "/child/page"):
+			out = map[string]interface{}{"results": []interface{}{}}
+		default:
+			out = page("1", "Home")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(out); err != nil {
+			panic(err)
+		}
+	}))
+	defer httpServer.Close()
+
+	server := New(confluence.NewClient(httpServer.URL, confluence.BasicAuth{}))
+	ctx := context.Background()
+
+	result, err := server.HandleTool(ctx, "confluence_list_spaces", map[string]interface{}{
+		"status": "current",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	response := decodeResult(t, result)
+	spaces, _ := response["spaces"].([]interface{})
+	if len(spaces) != 1 || spaces[0].(map[string]interface{})["description"] != "Product docs" {
+		t.Errorf("list_spaces response = %v", response)
+	}
+
+	result, err = server.HandleTool(ctx, "confluence_get_space", map[string]interface{}{
+		"space_key": "DOCS",
+		"depth":     float64(1),
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	response = decodeResult(t, result)
+	home, _ := response["homepage"].(map[string]interface{})
+	if home["title"] != "Home" || response["tree"] != "- Home (1)\n" || response["pages"] != float64(1) {
+		t.Errorf("get_space response = %v", response)
+	}
+	if got := response["space"].(map[string]interface{})["homepageId"]; got != "1" {
+		t.Errorf("homepageId = %v", got)
+	}
+}
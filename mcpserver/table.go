@@ -0,0 +1,309 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+	"github.com/agentplexus/mcp-confluence/storage"
+)
+
+// tableTools returns tools that operate on one table of a page.
+func tableTools() []Tool {
+	return []Tool{
+		{
+			Name:        "confluence_export_table",
+			Description: "Export a table from a Confluence page as CSV or TSV. Choose the table by index and/or by headers it must contain.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": tableProperties(map[string]interface{}{
+					"format": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"csv", "tsv"},
+						"description": "Output format (default csv)",
+					},
+				}),
+				"required": []string{"page_id"},
+			},
+		},
+		{
+			Name:        "confluence_import_table",
+			Description: "Load CSV, TSV or JSON records into a table on a Confluence page, replacing its content or upserting rows by a key column. Use it to sync inventory tables from spreadsheets. Refuses pages that republishing would damage.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": withVersionOptions(tableProperties(map[string]interface{}{
+					"data": map[string]interface{}{
+						"type":        "string",
+						"description": "CSV or TSV with a header row, or a JSON array of objects",
+					},
+					"format": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"csv", "tsv", "json"},
+						"description": "Format of data (default csv)",
+					},
+					"mode": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"replace", "upsert"},
+						"description": "replace swaps in the new headers and rows; upsert updates rows whose key matches and appends the rest (default upsert)",
+					},
+					"key": map[string]interface{}{
+						"type":        "string",
+						"description": "Key column for upsert",
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Return the resulting diff without publishing (default false)",
+					},
+				})),
+				"required": []string{"page_id", "data"},
+			},
+		},
+		{
+			Name:        "confluence_edit_table",
+			Description: "Sort a table on a Confluence page or add, remove and rename its columns. Operations apply in order. Refuses the same pages as confluence_import_table.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": withVersionOptions(tableProperties(map[string]interface{}{
+					"ops": map[string]interface{}{
+						"type":        "array",
+						"description": "Operations: {op: sort, column, descending?}, {op: add_column, name, position?}, {op: remove_column, column}, {op: rename_column, column, name}",
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"op": map[string]interface{}{
+									"type": "string",
+									"enum": []string{"sort", "add_column", "remove_column", "rename_column"},
+								},
+								"column":     map[string]string{"type": "string"},
+								"name":       map[string]string{"type": "string"},
+								"position":   map[string]string{"type": "integer"},
+								"descending": map[string]string{"type": "boolean"},
+							},
+							"required": []string{"op"},
+						},
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Return the resulting diff without publishing (default false)",
+					},
+				})),
+				"required": []string{"page_id", "ops"},
+			},
+		},
+	}
+}
+
+// tableProperties returns the schema properties that choose a table,
+// merged with extra.
+func tableProperties(extra map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{
+		"page_id": map[string]interface{}{
+			"type":        "string",
+			"description": "The Confluence page ID",
+		},
+		"table": map[string]interface{}{
+			"type":        "integer",
+			"description": "Index of the table among the page's top-level tables (or among those matching headers), counting from 0. Required when several tables match.",
+		},
+		"headers": map[string]interface{}{
+			"type":        "array",
+			"description": "Headers the table must contain, ignoring case and order",
+			"items":       map[string]string{"type": "string"},
+		},
+	}
+	for k, v := range extra {
+		properties[k] = v
+	}
+	return properties
+}
+
+// findTable fetches a page with getPage and finds the table chosen by the
+// table and headers inputs.
+func (s *Server) findTable(ctx context.Context, input map[string]interface{}, getPage func(context.Context, string) (*storage.Page, *confluence.PageInfo, error)) (*storage.Page, *confluence.PageInfo, int, *storage.Table, error) {
+	pageID, _ := input["page_id"].(string)
+	if pageID == "" {
+		return nil, nil, 0, nil, fmt.Errorf("page_id is required")
+	}
+	n := -1
+	if t, ok := input["table"].(float64); ok {
+		n = int(t)
+	}
+	var headers []string
+	if raw, ok := input["headers"].([]interface{}); ok {
+		for _, h := range raw {
+			if str, ok := h.(string); ok {
+				headers = append(headers, str)
+			}
+		}
+	}
+
+	page, info, err := getPage(ctx, pageID)
+	if err != nil {
+		return nil, nil, 0, nil, err
+	}
+	index, table, err := storage.FindTable(page, headers, n)
+	if err != nil {
+		return nil, nil, 0, nil, err
+	}
+	return page, info, index, table, nil
+}
+
+// publishTable publishes page with the block at index replaced by table.
+func (s *Server) publishTable(ctx context.Context, page *storage.Page, info *confluence.PageInfo, index int, table *storage.Table, dryRun bool, opts ...confluence.UpdateOption) (map[string]interface{}, error) {
+	edited := &storage.Page{Blocks: append([]storage.Block{}, page.Blocks...)}
+	edited.Blocks[index] = table
+	return s.publishEdit(ctx, info.ID, info.Title, page, edited, info.Version, dryRun, opts...)
+}
+
+func (s *Server) handleExportTable(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	format, _ := input["format"].(string)
+	if format == "" {
+		format = "csv"
+	}
+	comma := ','
+	switch format {
+	case "csv":
+	case "tsv":
+		comma = '\t'
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+
+	_, info, index, table, err := s.findTable(ctx, input, s.client.GetPageStorage)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf strings.Builder
+	if err := table.WriteCSV(&buf, comma); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"page_id": info.ID,
+		"title":   info.Title,
+		"version": info.Version,
+		"block":   index,
+		"rows":    len(table.Rows),
+		"format":  format,
+		"data":    buf.String(),
+	}, nil
+}
+
+func (s *Server) handleImportTable(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	data, _ := input["data"].(string)
+	format, _ := input["format"].(string)
+	mode, _ := input["mode"].(string)
+	key, _ := input["key"].(string)
+	dryRun, _ := input["dry_run"].(bool)
+
+	if data == "" {
+		return nil, fmt.Errorf("data is required")
+	}
+	if mode == "" {
+		mode = "upsert"
+	}
+	if mode != "replace" && mode != "upsert" {
+		return nil, fmt.Errorf("unsupported mode: %s", mode)
+	}
+	if mode == "upsert" && key == "" {
+		return nil, fmt.Errorf("key is required for upsert")
+	}
+
+	var src *storage.Table
+	var err error
+	switch format {
+	case "", "csv":
+		src, err = storage.TableFromCSV(strings.NewReader(data))
+	case "tsv":
+		src, err = storage.TableFromTSV(strings.NewReader(data))
+	case "json":
+		src, err = storage.TableFromJSON([]byte(data))
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid data: %w", err)
+	}
+
+	page, info, index, table, err := s.findTable(ctx, input, s.getPageForEdit)
+	if err != nil {
+		return nil, err
+	}
+
+	var inserted, updated int
+	if mode == "replace" {
+		inserted = len(src.Rows)
+		table = src
+	} else {
+		table = table.Clone()
+		if inserted, updated, err = table.Upsert(key, src); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := s.publishTable(ctx, page, info, index, table, dryRun, updateOptions(input)...)
+	if err != nil {
+		return nil, err
+	}
+	result["inserted"] = inserted
+	result["updated"] = updated
+	return result, nil
+}
+
+func (s *Server) handleEditTable(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	opsRaw, _ := input["ops"].([]interface{})
+	dryRun, _ := input["dry_run"].(bool)
+	if len(opsRaw) == 0 {
+		return nil, fmt.Errorf("ops is required")
+	}
+
+	page, info, index, table, err := s.findTable(ctx, input, s.getPageForEdit)
+	if err != nil {
+		return nil, err
+	}
+
+	table = table.Clone()
+	for i, raw := range opsRaw {
+		if err := applyTableOp(table, raw); err != nil {
+			return nil, fmt.Errorf("op %d: %w", i, err)
+		}
+	}
+
+	return s.publishTable(ctx, page, info, index, table, dryRun, updateOptions(input)...)
+}
+
+func applyTableOp(table *storage.Table, raw interface{}) error {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("operation must be an object")
+	}
+	op, _ := m["op"].(string)
+	column, _ := m["column"].(string)
+	name, _ := m["name"].(string)
+
+	switch op {
+	case "sort":
+		descending, _ := m["descending"].(bool)
+		return table.SortBy(column, descending)
+	case "add_column":
+		position := -1
+		if p, ok := m["position"].(float64); ok {
+			position = int(p)
+		}
+		if name == "" {
+			return fmt.Errorf("add_column requires name")
+		}
+		return table.AddColumn(name, position)
+	case "remove_column":
+		return table.RemoveColumn(column)
+	case "rename_column":
+		if name == "" {
+			return fmt.Errorf("rename_column requires name")
+		}
+		return table.RenameColumn(column, name)
+	default:
+		return fmt.Errorf("unknown op: %s", op)
+	}
+}
@@ -0,0 +1,169 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+)
+
+const tableTestXHTML = `<h1>Inventory</h1>` +
+	`<table><tbody><tr><th>Team</th></tr><tr><td>Ops</td></tr></tbody></table>` +
+	`<table><tbody><tr><th>Host</th><th>CPU</th></tr><tr><td>web1</td><td>2</td></tr><tr><td>db1</td><td>8</td></tr></tbody></table>`
+
+// tableTestServer serves xhtml and records the published body.
+func tableTestServer(xhtml string, body *string) *httptest.Server {
+	return httptest.NewServer(withEditPermission(true, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "PUT" {
+			var payload struct {
+				Body struct {
+					Storage struct {
+						Value string `json:"value"`
+					} `json:"storage"`
+				} `json:"body"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				panic(err)
+			}
+			*body = payload.Body.Storage.Value
+		}
+		if err := json.NewEncoder(w).Encode(pageResponse("12345", "Hosts", xhtml, 3)); err != nil {
+			panic(err)
+		}
+	})))
+}
+
+func TestHandleExportTable(t *testing.T) {
+	var body string
+	httpServer := tableTestServer(tableTestXHTML, &body)
+	defer httpServer.Close()
+
+	server := New(confluence.NewClient(httpServer.URL, confluence.BasicAuth{}))
+	result, err := server.HandleTool(context.Background(), "confluence_export_table", map[string]interface{}{
+		"page_id": "12345",
+		"headers": []interface{}{"host"},
+		"format":  "tsv",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+
+	response := decodeResult(t, result)
+	if response["data"] != "Host\tCPU\nweb1\t2\ndb1\t8\n" || response["block"] != float64(2) {
+		t.Errorf("Response data = %q, block = %v", response["data"], response["block"])
+	}
+
+	result, err = server.HandleTool(context.Background(), "confluence_export_table", map[string]interface{}{
+		"page_id": "12345",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "2 tables match") {
+		t.Errorf("HandleTool() should require choosing a table, got %v", result.Content)
+	}
+}
+
+func TestHandleImportTable(t *testing.T) {
+	var body string
+	httpServer := tableTestServer(tableTestXHTML, &body)
+	defer httpServer.Close()
+
+	server := New(confluence.NewClient(httpServer.URL, confluence.BasicAuth{}))
+	result, err := server.HandleTool(context.Background(), "confluence_import_table", map[string]interface{}{
+		"page_id": "12345",
+		"table":   float64(1),
+		"data":    `[{"Host":"db1","CPU":16},{"Host":"cache1","CPU":4}]`,
+		"format":  "json",
+		"key":     "host",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+
+	response := decodeResult(t, result)
+	if response["inserted"] != float64(1) || response["updated"] != float64(1) || response["status"] != "updated" {
+		t.Errorf("Response = %v", response)
+	}
+	want := `<tr><td>web1</td><td>2</td></tr><tr><td>db1</td><td>16</td></tr><tr><td>cache1</td><td>4</td></tr>`
+	if !strings.Contains(body, want) || !strings.Contains(body, "<td>Ops</td>") {
+		t.Errorf("Published body = %s", body)
+	}
+}
+
+func TestHandleEditTable(t *testing.T) {
+	var body string
+	httpServer := tableTestServer(tableTestXHTML, &body)
+	defer httpServer.Close()
+
+	server := New(confluence.NewClient(httpServer.URL, confluence.BasicAuth{}))
+	result, err := server.HandleTool(context.Background(), "confluence_edit_table", map[string]interface{}{
+		"page_id": "12345",
+		"headers": []interface{}{"CPU"},
+		"ops": []interface{}{
+			map[string]interface{}{"op": "sort", "column": "cpu", "descending": true},
+			map[string]interface{}{"op": "add_column", "name": "Owner", "position": float64(1)},
+			map[string]interface{}{"op": "rename_column", "column": "CPU", "name": "Cores"},
+		},
+		"dry_run": true,
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+
+	response := decodeResult(t, result)
+	if response["status"] != "dry_run" || body != "" {
+		t.Errorf("Response status = %v, published %q", response["status"], body)
+	}
+	diff, _ := response["diff"].(string)
+	if !strings.Contains(diff, "Cores") || !strings.Contains(diff, "Owner") {
+		t.Errorf("Response diff = %s", diff)
+	}
+
+	result, err = server.HandleTool(context.Background(), "confluence_edit_table", map[string]interface{}{
+		"page_id": "12345",
+		"table":   float64(1),
+		"ops":     []interface{}{map[string]interface{}{"op": "remove_column", "column": "RAM"}},
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].Text, `op 0: column "RAM" not found`) {
+		t.Errorf("HandleTool() error = %v", result.Content)
+	}
+}
+
+func TestHandleEditTable_LossyPage(t *testing.T) {
+	var body string
+	xhtml := `<p><ac:inline-comment-marker ac:ref="c1">Reviewed</ac:inline-comment-marker> by ops</p>` + tableTestXHTML
+	httpServer := tableTestServer(xhtml, &body)
+	defer httpServer.Close()
+
+	server := New(confluence.NewClient(httpServer.URL, confluence.BasicAuth{}))
+	result, err := server.HandleTool(context.Background(), "confluence_edit_table", map[string]interface{}{
+		"page_id": "12345",
+		"headers": []interface{}{"CPU"},
+		"ops":     []interface{}{map[string]interface{}{"op": "sort", "column": "cpu"}},
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "ac:inline-comment-marker") || body != "" {
+		t.Errorf("edit_table on a page with an inline comment = %v, published %q", result.Content, body)
+	}
+
+	// Reading the table is still allowed.
+	result, err = server.HandleTool(context.Background(), "confluence_export_table", map[string]interface{}{
+		"page_id": "12345",
+		"headers": []interface{}{"CPU"},
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	decodeResult(t, result)
+}
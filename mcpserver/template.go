@@ -0,0 +1,101 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/agentplexus/mcp-confluence/storage"
+)
+
+// templateTools returns tools that create pages from templates.
+func templateTools() []Tool {
+	return []Tool{
+		{
+			Name:        "confluence_list_templates",
+			Description: "List the built-in page templates (meeting notes, decision records, incident postmortems, runbooks) with the variables each accepts.",
+			InputSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "confluence_create_page_from_template",
+			Description: "Create a new Confluence page by filling a template with variables. Use a built-in template by name, or pass your own template as Markdown with {{variable}}, {{#if variable}}...{{else}}...{{/if}} and {{#each list}}...{{/each}} placeholders.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"space_key": map[string]interface{}{
+						"type":        "string",
+						"description": "The space key where the page will be created",
+					},
+					"template": map[string]interface{}{
+						"type":        "string",
+						"description": "Built-in template name (see confluence_list_templates)",
+					},
+					"markdown": map[string]interface{}{
+						"type":        "string",
+						"description": "Custom template body as Markdown, instead of a built-in template",
+					},
+					"variables": map[string]interface{}{
+						"type":        "object",
+						"description": "Template variables. Lists are arrays of strings or of objects whose fields are available inside {{#each}}.",
+					},
+					"title": map[string]interface{}{
+						"type":        "string",
+						"description": "Page title; may contain placeholders. Defaults to the template's title.",
+					},
+					"parent_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Optional parent page ID",
+					},
+				},
+				"required": []string{"space_key"},
+			},
+		},
+	}
+}
+
+func (s *Server) handleListTemplates(_ context.Context, _ map[string]interface{}) (interface{}, error) {
+	templates := storage.BuiltinTemplates()
+	return map[string]interface{}{
+		"templates": templates,
+		"count":     len(templates),
+	}, nil
+}
+
+func (s *Server) handleCreatePageFromTemplate(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	spaceKey, _ := input["space_key"].(string)
+	name, _ := input["template"].(string)
+	markdown, hasMarkdown := input["markdown"].(string)
+	vars, _ := input["variables"].(map[string]interface{})
+	title, _ := input["title"].(string)
+	parentID, _ := input["parent_id"].(string)
+
+	if spaceKey == "" {
+		return nil, fmt.Errorf("space_key is required")
+	}
+	if (name == "") == !hasMarkdown {
+		return nil, fmt.Errorf("exactly one of template or markdown is required")
+	}
+
+	tmpl := &storage.Template{Name: "custom", Body: storage.ParseMarkdown(markdown)}
+	if name != "" {
+		var ok bool
+		if tmpl, ok = storage.BuiltinTemplate(name); !ok {
+			return nil, fmt.Errorf("unknown template: %s", name)
+		}
+	}
+	if title != "" {
+		tmpl.Title = title
+	}
+	if tmpl.Title == "" {
+		return nil, fmt.Errorf("title is required for a custom template")
+	}
+
+	title, page, err := tmpl.Execute(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.createPage(ctx, spaceKey, title, page, parentID)
+}
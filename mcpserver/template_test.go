@@ -0,0 +1,101 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+)
+
+func TestHandleListTemplates(t *testing.T) {
+	server := New(confluence.NewClient("http://localhost", confluence.BasicAuth{}))
+
+	result, err := server.HandleTool(context.Background(), "confluence_list_templates", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+
+	response := decodeResult(t, result)
+	templates, ok := response["templates"].([]interface{})
+	if !ok || len(templates) == 0 {
+		t.Fatalf("Response templates = %v", response["templates"])
+	}
+	first := templates[0].(map[string]interface{})
+	if first["name"] != "meeting-notes" || first["variables"] == nil {
+		t.Errorf("templates[0] = %v", first)
+	}
+}
+
+func TestHandleCreatePageFromTemplate(t *testing.T) {
+	var title, body string
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Title string `json:"title"`
+			Body  struct {
+				Storage struct {
+					Value string `json:"value"`
+				} `json:"storage"`
+			} `json:"body"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			panic(err)
+		}
+		title, body = payload.Title, payload.Body.Storage.Value
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]string{"id": "999"}); err != nil {
+			panic(err)
+		}
+	}))
+	defer httpServer.Close()
+
+	server := New(confluence.NewClient(httpServer.URL, confluence.BasicAuth{}))
+	result, err := server.HandleTool(context.Background(), "confluence_create_page_from_template", map[string]interface{}{
+		"space_key": "ENG",
+		"template":  "meeting-notes",
+		"variables": map[string]interface{}{
+			"date":      "2024-05-01",
+			"title":     "Platform sync",
+			"attendees": []interface{}{"Ana", "Bo"},
+			"actions":   []interface{}{map[string]interface{}{"task": "Ship", "owner": "Ana"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+
+	response := decodeResult(t, result)
+	if response["page_id"] != "999" || title != "2024-05-01 Platform sync" {
+		t.Errorf("Response = %v, title = %q", response, title)
+	}
+	if !strings.Contains(body, "<li>Ana</li><li>Bo</li>") || !strings.Contains(body, "<ac:task-body>Ship (Ana)</ac:task-body>") {
+		t.Errorf("Published body = %s", body)
+	}
+
+	result, err = server.HandleTool(context.Background(), "confluence_create_page_from_template", map[string]interface{}{
+		"space_key": "ENG",
+		"template":  "meeting-notes",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "missing required variables: date") {
+		t.Errorf("HandleTool() error = %v", result.Content)
+	}
+
+	result, err = server.HandleTool(context.Background(), "confluence_create_page_from_template", map[string]interface{}{
+		"space_key": "ENG",
+		"title":     "Release {{version}}",
+		"markdown":  "Shipping {{version}}.",
+		"variables": map[string]interface{}{"version": "1.2"},
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if result.IsError || title != "Release 1.2" || body != "<p>Shipping 1.2.</p>" {
+		t.Errorf("custom template: title = %q, body = %q, result = %v", title, body, result.Content)
+	}
+}
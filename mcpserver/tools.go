@@ -2,10 +2,10 @@ package mcpserver
 
 // Tools returns the list of available MCP tools.
 func (s *Server) Tools() []Tool {
-	return []Tool{
+	tools := []Tool{
 		{
 			Name:        "confluence_read_page",
-			Description: "Read a Confluence page as structured content blocks. Returns the page content parsed into blocks (paragraphs, tables, headings, etc.) that can be safely modified.",
+			Description: "Read a Confluence page as structured content blocks. Returns the page content parsed into blocks (paragraphs, tables, headings, etc.) that can be safely modified, as compact GitHub-flavoured Markdown with format \"markdown\", or as plain text with format \"text\". Use max_chars or max_blocks to read long pages in windows, continuing from next_offset.",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
@@ -13,6 +13,23 @@ func (s *Server) Tools() []Tool {
 						"type":        "string",
 						"description": "The Confluence page ID",
 					},
+					"format": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"blocks", "markdown", "text"},
+						"description": "Content format (default blocks). Markdown is compact and read-only friendly; panels become callouts and unsupported macros are kept as fenced Storage XHTML. Text drops all markup.",
+					},
+					"max_chars": map[string]interface{}{
+						"type":        "integer",
+						"description": "Approximate maximum characters of content to return. At least one block is always returned.",
+					},
+					"max_blocks": map[string]interface{}{
+						"type":        "integer",
+						"description": "Maximum number of blocks to return",
+					},
+					"offset": map[string]interface{}{
+						"type":        "integer",
+						"description": "Index of the first block to return (default 0). Pass the next_offset of the previous read to continue.",
+					},
 				},
 				"required": []string{"page_id"},
 			},
@@ -36,7 +53,7 @@ func (s *Server) Tools() []Tool {
 			Description: "Update a Confluence page with structured content blocks. Accepts an array of blocks (paragraphs, tables, headings, etc.) and safely renders them to valid Confluence Storage XHTML.",
 			InputSchema: map[string]interface{}{
 				"type": "object",
-				"properties": map[string]interface{}{
+				"properties": withVersionOptions(map[string]interface{}{
 					"page_id": map[string]interface{}{
 						"type":        "string",
 						"description": "The Confluence page ID",
@@ -52,7 +69,11 @@ func (s *Server) Tools() []Tool {
 							"type": "object",
 						},
 					},
-				},
+					"base_version": map[string]interface{}{
+						"type":        "integer",
+						"description": "The page version the blocks were based on. If the page changed since, non-overlapping edits are merged and overlapping edits are returned as conflicts instead of being overwritten.",
+					},
+				}),
 				"required": []string{"page_id", "title", "blocks"},
 			},
 		},
@@ -61,7 +82,7 @@ func (s *Server) Tools() []Tool {
 			Description: "Update a Confluence page with raw Storage Format XHTML. Use this when you need to preserve all formatting, attributes, and structure that the block-based update would lose (complex tables, inline styles, macros, etc.).",
 			InputSchema: map[string]interface{}{
 				"type": "object",
-				"properties": map[string]interface{}{
+				"properties": withVersionOptions(map[string]interface{}{
 					"page_id": map[string]interface{}{
 						"type":        "string",
 						"description": "The Confluence page ID",
@@ -74,7 +95,7 @@ func (s *Server) Tools() []Tool {
 						"type":        "string",
 						"description": "The raw Storage Format XHTML content",
 					},
-				},
+				}),
 				"required": []string{"page_id", "title", "xhtml"},
 			},
 		},
@@ -176,4 +197,22 @@ func (s *Server) Tools() []Tool {
 			},
 		},
 	}
+	tools = append(tools, formatTools()...)
+	tools = append(tools, diffTools()...)
+	tools = append(tools, patchTools()...)
+	tools = append(tools, markdownTools()...)
+	tools = append(tools, sectionTools()...)
+	tools = append(tools, selectTools()...)
+	tools = append(tools, tableTools()...)
+	tools = append(tools, templateTools()...)
+	tools = append(tools, labelTools()...)
+	tools = append(tools, attachmentTools()...)
+	tools = append(tools, commentTools()...)
+	tools = append(tools, versionTools()...)
+	tools = append(tools, treeTools()...)
+	tools = append(tools, moveTools()...)
+	tools = append(tools, propertyTools()...)
+	tools = append(tools, restrictionTools()...)
+	tools = append(tools, spaceTools()...)
+	return tools
 }
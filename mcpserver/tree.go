@@ -0,0 +1,114 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+)
+
+// treeTools returns tools that navigate the page hierarchy.
+func treeTools() []Tool {
+	return []Tool{
+		{
+			Name:        "confluence_get_page_tree",
+			Description: "Get the page hierarchy of a space or below a page as an indented list of titles and page IDs. Use it to find pages and to choose parents for new pages. Trees are cut off after 1000 pages and then marked truncated.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Root page of the subtree",
+					},
+					"space_key": map[string]interface{}{
+						"type":        "string",
+						"description": "Space whose whole tree to list, instead of page_id",
+					},
+					"depth": map[string]interface{}{
+						"type":        "integer",
+						"description": "Levels below the root pages to include (default 3, -1 for all)",
+					},
+				},
+			},
+		},
+	}
+}
+
+// writeTree writes nodes as a Markdown list indented by depth.
+func writeTree(buf *strings.Builder, nodes []*confluence.PageNode, level int) {
+	for _, n := range nodes {
+		fmt.Fprintf(buf, "%s- %s (%s)\n", strings.Repeat("  ", level), n.Title, n.ID)
+		writeTree(buf, n.Children, level+1)
+	}
+}
+
+// treeResult sets the tree and pages results for the trees at roots.
+// Callers mark trees cut off by confluence.ErrTreeTruncated with
+// truncatedResult.
+func treeResult(result map[string]interface{}, roots []*confluence.PageNode) {
+	var buf strings.Builder
+	writeTree(&buf, roots, 0)
+	count := 0
+	for _, r := range roots {
+		count += r.Count()
+	}
+	result["tree"] = buf.String()
+	result["pages"] = count
+}
+
+// truncatedResult records in result whether err reports a truncated page
+// tree, and returns err unless it does.
+func truncatedResult(result map[string]interface{}, err error) error {
+	if errors.Is(err, confluence.ErrTreeTruncated) {
+		result["truncated"] = true
+		result["warning"] = err.Error()
+		return nil
+	}
+	return err
+}
+
+func (s *Server) handleGetPageTree(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	spaceKey, _ := input["space_key"].(string)
+	depth := 3
+	if d, ok := input["depth"].(float64); ok {
+		depth = int(d)
+	}
+
+	if (pageID == "") == (spaceKey == "") {
+		return nil, fmt.Errorf("exactly one of page_id or space_key is required")
+	}
+
+	result := map[string]interface{}{}
+	var roots []*confluence.PageNode
+	if pageID != "" {
+		root, err := s.client.GetPageTree(ctx, pageID, depth)
+		if err := truncatedResult(result, err); err != nil {
+			return nil, err
+		}
+		ancestors, err := s.client.GetAncestors(ctx, pageID)
+		if err != nil {
+			return nil, err
+		}
+		path := make([]interface{}, len(ancestors))
+		for i, a := range ancestors {
+			path[i] = map[string]interface{}{"id": a.ID, "title": a.Title}
+		}
+		roots = []*confluence.PageNode{root}
+		result["page_id"] = pageID
+		result["space_key"] = root.SpaceKey
+		result["ancestors"] = path
+	} else {
+		var err error
+		roots, err = s.client.GetSpaceTree(ctx, spaceKey, depth)
+		if err := truncatedResult(result, err); err != nil {
+			return nil, err
+		}
+		result["space_key"] = spaceKey
+	}
+
+	treeResult(result, roots)
+	return result, nil
+}
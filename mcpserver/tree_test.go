@@ -0,0 +1,122 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+)
+
+func TestHandleGetPageTree(t *testing.T) {
+	titles := map[string]string{"1": "Home", "2": "Guides", "3": "Install", "9": "Archive"}
+	children := map[string][]string{"1": {"2"}, "2": {"3"}}
+	page := func(id string) map[string]interface{} {
+		return map[string]interface{}{"id": id, "title": titles[id], "space": map[string]string{"key": "DOCS"}}
+	}
+	list := func(ids ...string) map[string]interface{} {
+		results := []interface{}{}
+		for _, id := range ids {
+			results = append(results, page(id))
+		}
+		return map[string]interface{}{"results": results}
+	}
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/rest/api/content/"), "/")
+		var out interface{}
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/rest/api/space/"):
+			out = list("1", "9")
+		case len(parts) == 3:
+			out = list(children[parts[0]]...)
+		case r.URL.Query().Get("expand") == "ancestors":
+			out = map[string]interface{}{"ancestors": []interface{}{page("1")}}
+		default:
+			out = page(parts[0])
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(out); err != nil {
+			panic(err)
+		}
+	}))
+	defer httpServer.Close()
+
+	server := New(confluence.NewClient(httpServer.URL, confluence.BasicAuth{}))
+	ctx := context.Background()
+
+	result, err := server.HandleTool(ctx, "confluence_get_page_tree", map[string]interface{}{
+		"space_key": "DOCS",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	response := decodeResult(t, result)
+	want := "- Home (1)\n  - Guides (2)\n    - Install (3)\n- Archive (9)\n"
+	if response["tree"] != want || response["pages"] != float64(4) {
+		t.Errorf("space tree response = %v, want tree %q", response, want)
+	}
+
+	result, err = server.HandleTool(ctx, "confluence_get_page_tree", map[string]interface{}{
+		"page_id": "2",
+		"depth":   float64(0),
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	response = decodeResult(t, result)
+	ancestors, _ := response["ancestors"].([]interface{})
+	if response["tree"] != "- Guides (2)\n" || len(ancestors) != 1 || response["space_key"] != "DOCS" {
+		t.Errorf("subtree response = %v", response)
+	}
+
+	result, err = server.HandleTool(ctx, "confluence_get_page_tree", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError {
+		t.Error("get_page_tree without page_id or space_key should fail")
+	}
+}
+
+func TestHandleGetPageTree_Truncated(t *testing.T) {
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var out interface{}
+		switch {
+		case r.URL.Path == "/rest/api/content/1/child/page":
+			results := []interface{}{}
+			for i := 2; i <= 1200; i++ {
+				results = append(results, map[string]interface{}{"id": fmt.Sprint(i), "title": fmt.Sprintf("Page %d", i)})
+			}
+			out = map[string]interface{}{"results": results}
+		case strings.HasSuffix(r.URL.Path, "/child/page"):
+			out = map[string]interface{}{"results": []interface{}{}}
+		case r.URL.Query().Get("expand") == "ancestors":
+			out = map[string]interface{}{"ancestors": []interface{}{}}
+		default:
+			out = map[string]interface{}{"id": "1", "title": "Home"}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(out); err != nil {
+			panic(err)
+		}
+	}))
+	defer httpServer.Close()
+
+	server := New(confluence.NewClient(httpServer.URL, confluence.BasicAuth{}))
+	result, err := server.HandleTool(context.Background(), "confluence_get_page_tree", map[string]interface{}{
+		"page_id": "1",
+		"depth":   float64(1),
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	response := decodeResult(t, result)
+	if response["truncated"] != true || response["pages"] != float64(1000) {
+		t.Errorf("truncated tree response: truncated = %v, pages = %v", response["truncated"], response["pages"])
+	}
+}
@@ -0,0 +1,193 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/agentplexus/mcp-confluence/storage"
+)
+
+// versionTools returns tools that read and restore page history.
+func versionTools() []Tool {
+	return []Tool{
+		{
+			Name:        "confluence_list_versions",
+			Description: "List the version history of a Confluence page, newest first, with author, date and version message",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The Confluence page ID",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "Maximum number of versions (default 25, 0 for all)",
+					},
+				},
+				"required": []string{"page_id"},
+			},
+		},
+		{
+			Name:        "confluence_read_page_version",
+			Description: "Read the content of a historical version of a Confluence page",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The Confluence page ID",
+					},
+					"version": map[string]interface{}{
+						"type":        "integer",
+						"description": "The version number",
+					},
+					"format": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"blocks", "markdown", "text"},
+						"description": "Content format (default blocks)",
+					},
+				},
+				"required": []string{"page_id", "version"},
+			},
+		},
+		{
+			Name:        "confluence_restore_version",
+			Description: "Restore a historical version of a Confluence page, publishing its content and title as a new version. Use dry_run to see what would change.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The Confluence page ID",
+					},
+					"version": map[string]interface{}{
+						"type":        "integer",
+						"description": "The version number to restore",
+					},
+					"message": map[string]interface{}{
+						"type":        "string",
+						"description": "Version message for the restored version (default \"" + defaultVersionMessage + "\")",
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Return the diff from the current version without restoring (default false)",
+					},
+				},
+				"required": []string{"page_id", "version"},
+			},
+		},
+	}
+}
+
+func (s *Server) handleListVersions(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, _ := input["page_id"].(string)
+	if pageID == "" {
+		return nil, fmt.Errorf("page_id is required")
+	}
+	limit := 25
+	if l, ok := input["limit"].(float64); ok {
+		limit = int(l)
+	}
+
+	versions, err := s.client.ListVersions(ctx, pageID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"page_id":  pageID,
+		"versions": versions,
+		"count":    len(versions),
+	}, nil
+}
+
+// versionInput returns the page_id and version inputs.
+func versionInput(input map[string]interface{}) (string, int, error) {
+	pageID, _ := input["page_id"].(string)
+	version, _ := input["version"].(float64)
+	if pageID == "" {
+		return "", 0, fmt.Errorf("page_id is required")
+	}
+	if version < 1 {
+		return "", 0, fmt.Errorf("version is required")
+	}
+	return pageID, int(version), nil
+}
+
+func (s *Server) handleReadPageVersion(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, version, err := versionInput(input)
+	if err != nil {
+		return nil, err
+	}
+	format, _ := input["format"].(string)
+	if format == "" {
+		format = "blocks"
+	}
+	if _, ok := blockSizers[format]; !ok {
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+
+	page, info, err := s.client.GetPageVersion(ctx, pageID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"page_id": info.ID,
+		"title":   info.Title,
+		"version": info.Version,
+	}
+	if err := setContent(result, format, page); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Server) handleRestoreVersion(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+	pageID, version, err := versionInput(input)
+	if err != nil {
+		return nil, err
+	}
+	message, ok := input["message"].(string)
+	if !ok {
+		message = defaultVersionMessage
+	}
+	dryRun, _ := input["dry_run"].(bool)
+
+	if dryRun {
+		current, info, err := s.client.GetPageStorage(ctx, pageID)
+		if err != nil {
+			return nil, err
+		}
+		old, oldInfo, err := s.client.GetPageVersion(ctx, pageID, version)
+		if err != nil {
+			return nil, err
+		}
+		diff := storage.Diff(current, old)
+		return map[string]interface{}{
+			"status":   "dry_run",
+			"page_id":  info.ID,
+			"title":    oldInfo.Title,
+			"version":  info.Version,
+			"restored": version,
+			"changed":  !diff.Empty() || oldInfo.Title != info.Title,
+			"diff":     diff.Unified(),
+		}, nil
+	}
+
+	if err := s.requireEdit(ctx, pageID); err != nil {
+		return nil, err
+	}
+	restored, err := s.client.RestoreVersion(ctx, pageID, version, message)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"status":   "restored",
+		"page_id":  pageID,
+		"restored": version,
+		"version":  restored.Number,
+	}, nil
+}
@@ -0,0 +1,114 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/mcp-confluence/confluence"
+)
+
+func TestHandleVersions(t *testing.T) {
+	restored, message := 0, ""
+	httpServer := httptest.NewServer(withEditPermission(true, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var out interface{}
+		switch {
+		case r.URL.Path == "/rest/api/content/12345/version" && r.Method == "POST":
+			var payload struct {
+				Params struct {
+					VersionNumber int    `json:"versionNumber"`
+					Message       string `json:"message"`
+				} `json:"params"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				panic(err)
+			}
+			restored, message = payload.Params.VersionNumber, payload.Params.Message
+			out = map[string]interface{}{"number": 4}
+		case r.URL.Path == "/rest/api/content/12345/version":
+			out = map[string]interface{}{"results": []interface{}{
+				map[string]interface{}{"number": 3, "when": "2024-05-03T10:00:00.000Z", "message": "Tidy", "by": map[string]interface{}{"displayName": "Ana"}},
+				map[string]interface{}{"number": 2, "when": "2024-05-02T10:00:00.000Z", "by": map[string]interface{}{"displayName": "Ben"}},
+			}}
+		case r.URL.Query().Get("version") == "2":
+			out = pageResponse("12345", "Runbook", "<h2>Rollback</h2><p>Old steps</p>", 2)
+		default:
+			out = pageResponse("12345", "Runbook", "<h2>Rollback</h2><p>New steps</p>", 3)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(out); err != nil {
+			panic(err)
+		}
+	})))
+	defer httpServer.Close()
+
+	server := New(confluence.NewClient(httpServer.URL, confluence.BasicAuth{}))
+	ctx := context.Background()
+
+	result, err := server.HandleTool(ctx, "confluence_list_versions", map[string]interface{}{
+		"page_id": "12345",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	response := decodeResult(t, result)
+	versions, _ := response["versions"].([]interface{})
+	if len(versions) != 2 {
+		t.Fatalf("list_versions response = %v", response)
+	}
+	if v := versions[0].(map[string]interface{}); v["author"] != "Ana" || v["message"] != "Tidy" {
+		t.Errorf("versions[0] = %v", v)
+	}
+
+	result, err = server.HandleTool(ctx, "confluence_read_page_version", map[string]interface{}{
+		"page_id": "12345",
+		"version": float64(2),
+		"format":  "text",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if response := decodeResult(t, result); !strings.Contains(response["text"].(string), "Old steps") {
+		t.Errorf("read_page_version response = %v", response)
+	}
+
+	result, err = server.HandleTool(ctx, "confluence_restore_version", map[string]interface{}{
+		"page_id": "12345",
+		"version": float64(2),
+		"dry_run": true,
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	response = decodeResult(t, result)
+	if response["changed"] != true || !strings.Contains(response["diff"].(string), "Old steps") || restored != 0 {
+		t.Errorf("dry run restore_version response = %v", response)
+	}
+
+	result, err = server.HandleTool(ctx, "confluence_restore_version", map[string]interface{}{
+		"page_id": "12345",
+		"version": float64(2),
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if response := decodeResult(t, result); response["version"] != float64(4) || restored != 2 {
+		t.Errorf("restore_version response = %v", response)
+	}
+	if message != defaultVersionMessage {
+		t.Errorf("restore_version message = %q, want %q", message, defaultVersionMessage)
+	}
+
+	result, err = server.HandleTool(ctx, "confluence_read_page_version", map[string]interface{}{
+		"page_id": "12345",
+	})
+	if err != nil {
+		t.Fatalf("HandleTool() error = %v", err)
+	}
+	if !result.IsError {
+		t.Error("read_page_version without version should fail")
+	}
+}
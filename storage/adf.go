@@ -0,0 +1,757 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ADFNode is a node of an Atlas Document Format (ADF) document, the JSON
+// representation used by the Confluence Cloud editor and v2 API.
+type ADFNode struct {
+	Type    string                 `json:"type"`
+	Version int                    `json:"version,omitempty"` // set on the doc node only
+	Attrs   map[string]interface{} `json:"attrs,omitempty"`
+	Content []*ADFNode             `json:"content,omitempty"`
+	Text    string                 `json:"text,omitempty"`
+	Marks   []ADFMark              `json:"marks,omitempty"`
+}
+
+// MarshalJSON keeps an explicitly empty content array, which ADF requires on
+// the doc node.
+func (n ADFNode) MarshalJSON() ([]byte, error) {
+	type plain ADFNode
+	if n.Content != nil && len(n.Content) == 0 {
+		return json.Marshal(struct {
+			plain
+			Content []*ADFNode `json:"content"`
+		}{plain(n), n.Content})
+	}
+	return json.Marshal(plain(n))
+}
+
+// ADFMark is a formatting mark applied to an ADF text node.
+type ADFMark struct {
+	Type  string                 `json:"type"`
+	Attrs map[string]interface{} `json:"attrs,omitempty"`
+}
+
+// FidelityIssue describes content that could not be mapped exactly between
+// the IR and ADF and was dropped or simplified.
+type FidelityIssue struct {
+	Path   string `json:"path"` // IR path (blocks[0].items[1]) or ADF path (content[0].content[1])
+	Node   string `json:"node"` // block, node or mark type
+	Reason string `json:"reason"`
+}
+
+// confluenceMacroExtension is the ADF extension type of Confluence macros.
+const confluenceMacroExtension = "com.atlassian.confluence.macro.core"
+
+// panelTypes maps panel macros to ADF panel types. Confluence displays the
+// note macro as a yellow warning panel and the warning macro as a red error
+// panel.
+var panelTypes = map[string]string{
+	"info":    "info",
+	"tip":     "success",
+	"note":    "warning",
+	"warning": "error",
+}
+
+// statusColours maps status macro colours to ADF status colours.
+var statusColours = map[string]string{
+	"grey":   "neutral",
+	"red":    "red",
+	"yellow": "yellow",
+	"green":  "green",
+	"blue":   "blue",
+	"purple": "purple",
+}
+
+// RenderADF converts a Page to an ADF document. Content without an ADF
+// equivalent (attachment images, links to pages by title, panel titles and
+// non-task children of task items) is dropped and reported.
+func RenderADF(page *Page) (*ADFNode, []FidelityIssue, error) {
+	w := &adfWriter{}
+	doc := &ADFNode{Type: "doc", Version: 1, Content: []*ADFNode{}}
+	if page != nil {
+		content, err := w.blocks(page.Blocks, "blocks")
+		if err != nil {
+			return nil, nil, err
+		}
+		doc.Content = append(doc.Content, content...)
+	}
+	return doc, w.issues, nil
+}
+
+type adfWriter struct {
+	issues []FidelityIssue
+	ids    int // counter for generated localId attributes
+}
+
+func (w *adfWriter) report(path, node, reason string) {
+	w.issues = append(w.issues, FidelityIssue{Path: path, Node: node, Reason: reason})
+}
+
+func (w *adfWriter) localID() string {
+	w.ids++
+	return strconv.Itoa(w.ids)
+}
+
+func (w *adfWriter) blocks(blocks []Block, path string) ([]*ADFNode, error) {
+	var nodes []*ADFNode
+	for i, block := range blocks {
+		node, err := w.block(block, fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return nil, err
+		}
+		if node != nil {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
+func (w *adfWriter) block(block Block, path string) (*ADFNode, error) {
+	switch b := pointerBlock(block).(type) {
+	case *Paragraph:
+		return &ADFNode{Type: "paragraph", Content: w.inline(b.Text, b.Spans, path)}, nil
+	case *Heading:
+		if b.Level < 1 || b.Level > 6 {
+			return nil, fmt.Errorf("invalid heading level: %d", b.Level)
+		}
+		return &ADFNode{
+			Type:    "heading",
+			Attrs:   map[string]interface{}{"level": b.Level},
+			Content: w.inline(b.Text, nil, path),
+		}, nil
+	case *Table:
+		return w.table(b, path)
+	case *BulletList:
+		return w.list("bulletList", b.Items, path)
+	case *NumberedList:
+		return w.list("orderedList", b.Items, path)
+	case *TaskList:
+		return w.taskList(b, path)
+	case *CodeBlock:
+		return codeBlockADF(b.Language, b.Code), nil
+	case *HorizontalRule:
+		return &ADFNode{Type: "rule"}, nil
+	case *Blockquote:
+		content, err := w.blocks(b.Blocks, path+".blocks")
+		if err != nil {
+			return nil, err
+		}
+		return &ADFNode{Type: "blockquote", Content: content}, nil
+	case *Image:
+		if b.Attachment != "" {
+			w.report(path, "image", "attachment images need a media file ID in ADF")
+			return nil, nil
+		}
+		if b.URL == "" {
+			return nil, fmt.Errorf("image requires a url or attachment")
+		}
+		attrs := map[string]interface{}{"type": "external", "url": b.URL}
+		if b.Alt != "" {
+			attrs["alt"] = b.Alt
+		}
+		return &ADFNode{
+			Type:    "mediaSingle",
+			Attrs:   map[string]interface{}{"layout": "center"},
+			Content: []*ADFNode{{Type: "media", Attrs: attrs}},
+		}, nil
+	case *Macro:
+		return w.macro(b, path)
+	default:
+		return nil, fmt.Errorf("unsupported block type: %T", block)
+	}
+}
+
+func (w *adfWriter) table(t *Table, path string) (*ADFNode, error) {
+	table := &ADFNode{Type: "table"}
+	if len(t.Headers) > 0 {
+		row := &ADFNode{Type: "tableRow"}
+		for _, h := range t.Headers {
+			row.Content = append(row.Content, &ADFNode{
+				Type:    "tableHeader",
+				Content: []*ADFNode{{Type: "paragraph", Content: w.inline(h, nil, path)}},
+			})
+		}
+		table.Content = append(table.Content, row)
+	}
+	for i, r := range t.Rows {
+		row := &ADFNode{Type: "tableRow"}
+		for j, cell := range r.Cells {
+			content, err := w.cell(cell, fmt.Sprintf("%s.rows[%d].cells[%d]", path, i, j))
+			if err != nil {
+				return nil, err
+			}
+			row.Content = append(row.Content, &ADFNode{Type: "tableCell", Content: []*ADFNode{content}})
+		}
+		table.Content = append(table.Content, row)
+	}
+	return table, nil
+}
+
+func (w *adfWriter) cell(c Cell, path string) (*ADFNode, error) {
+	if c.Macro == nil {
+		return &ADFNode{Type: "paragraph", Content: w.inline(c.Text, nil, path)}, nil
+	}
+	node, err := w.macro(c.Macro, path)
+	if err != nil || node == nil {
+		return &ADFNode{Type: "paragraph"}, err
+	}
+	switch node.Type {
+	case "expand":
+		node.Type = "nestedExpand"
+	case "bodiedExtension":
+		// Table cells only allow extensions without a body.
+		w.report(path, "macro", "macro body dropped inside a table cell")
+		node.Type = "extension"
+		node.Content = nil
+	}
+	return node, nil
+}
+
+func (w *adfWriter) list(listType string, items []ListItem, path string) (*ADFNode, error) {
+	list := &ADFNode{Type: listType}
+	for i, item := range items {
+		itemPath := fmt.Sprintf("%s.items[%d]", path, i)
+		children, err := w.blocks(item.Children, itemPath+".children")
+		if err != nil {
+			return nil, err
+		}
+		content := append([]*ADFNode{{Type: "paragraph", Content: w.inline(item.Text, item.Spans, itemPath)}}, children...)
+		list.Content = append(list.Content, &ADFNode{Type: "listItem", Content: content})
+	}
+	return list, nil
+}
+
+// taskList renders a task list. ADF nests task lists as siblings of the
+// task item they belong to, and allows no other children.
+func (w *adfWriter) taskList(tl *TaskList, path string) (*ADFNode, error) {
+	list := &ADFNode{Type: "taskList", Attrs: map[string]interface{}{"localId": w.localID()}}
+	for i, item := range tl.Items {
+		itemPath := fmt.Sprintf("%s.items[%d]", path, i)
+		state := "TODO"
+		if item.Done {
+			state = "DONE"
+		}
+		list.Content = append(list.Content, &ADFNode{
+			Type:    "taskItem",
+			Attrs:   map[string]interface{}{"localId": w.localID(), "state": state},
+			Content: w.inline(item.Text, item.Spans, itemPath),
+		})
+		for j, child := range item.Children {
+			childPath := fmt.Sprintf("%s.children[%d]", itemPath, j)
+			nested, ok := pointerBlock(child).(*TaskList)
+			if !ok {
+				w.report(childPath, child.BlockType(), "task items can only contain nested task lists in ADF")
+				continue
+			}
+			node, err := w.taskList(nested, childPath)
+			if err != nil {
+				return nil, err
+			}
+			list.Content = append(list.Content, node)
+		}
+	}
+	return list, nil
+}
+
+func (w *adfWriter) macro(m *Macro, path string) (*ADFNode, error) {
+	switch {
+	case m.Name == "code" || m.Name == "noformat":
+		return codeBlockADF(m.Params["language"], m.Body), nil
+	case m.Name == "status":
+		return &ADFNode{Type: "paragraph", Content: []*ADFNode{w.status(m)}}, nil
+	}
+
+	var body []*ADFNode
+	if m.Body != "" {
+		page, err := Parse(m.Body)
+		if err != nil {
+			w.report(path, "macro", "macro body is not valid Storage XHTML")
+		} else if body, err = w.blocks(page.Blocks, path+".body"); err != nil {
+			return nil, err
+		}
+	}
+
+	if panelType, ok := panelTypes[m.Name]; ok {
+		if m.Params["title"] != "" {
+			w.report(path, "macro", "ADF panels have no title")
+		}
+		if len(body) == 0 {
+			body = []*ADFNode{{Type: "paragraph"}}
+		}
+		return &ADFNode{Type: "panel", Attrs: map[string]interface{}{"panelType": panelType}, Content: body}, nil
+	}
+	if m.Name == "expand" {
+		if len(body) == 0 {
+			body = []*ADFNode{{Type: "paragraph"}}
+		}
+		return &ADFNode{Type: "expand", Attrs: map[string]interface{}{"title": m.Params["title"]}, Content: body}, nil
+	}
+
+	params := make(map[string]interface{}, len(m.Params))
+	for k, v := range m.Params {
+		params[k] = map[string]interface{}{"value": v}
+	}
+	node := &ADFNode{
+		Type: "extension",
+		Attrs: map[string]interface{}{
+			"extensionType": confluenceMacroExtension,
+			"extensionKey":  m.Name,
+			"parameters":    map[string]interface{}{"macroParams": params},
+		},
+	}
+	if len(body) > 0 {
+		node.Type = "bodiedExtension"
+		node.Content = body
+	}
+	return node, nil
+}
+
+func (w *adfWriter) status(m *Macro) *ADFNode {
+	colour, ok := statusColours[strings.ToLower(m.Params["colour"])]
+	if !ok {
+		colour = "neutral"
+	}
+	return &ADFNode{Type: "status", Attrs: map[string]interface{}{
+		"text":    m.Params["title"],
+		"color":   colour,
+		"localId": w.localID(),
+	}}
+}
+
+// inline renders spans when present and the plain text otherwise.
+func (w *adfWriter) inline(text string, spans []Span, path string) []*ADFNode {
+	spans = matchingSpans(text, spans)
+	if len(spans) == 0 {
+		if text == "" {
+			return nil
+		}
+		return []*ADFNode{{Type: "text", Text: text}}
+	}
+
+	var nodes []*ADFNode
+	for _, s := range spans {
+		if s.Text == "" {
+			continue
+		}
+		var marks []ADFMark
+		if s.Code {
+			// The code mark combines with links only.
+			if s.Bold || s.Italic || s.Strike {
+				w.report(path, "code", "code cannot be combined with other formatting in ADF")
+			}
+			marks = append(marks, ADFMark{Type: "code"})
+		} else {
+			if s.Bold {
+				marks = append(marks, ADFMark{Type: "strong"})
+			}
+			if s.Italic {
+				marks = append(marks, ADFMark{Type: "em"})
+			}
+			if s.Strike {
+				marks = append(marks, ADFMark{Type: "strike"})
+			}
+		}
+		if s.Link != "" {
+			marks = append(marks, ADFMark{Type: "link", Attrs: map[string]interface{}{"href": s.Link}})
+		}
+		if s.Page != "" {
+			w.report(path, "link", "links to pages by title need a URL in ADF: "+s.Page)
+		}
+		nodes = append(nodes, &ADFNode{Type: "text", Text: s.Text, Marks: marks})
+	}
+	return nodes
+}
+
+func codeBlockADF(language, code string) *ADFNode {
+	node := &ADFNode{Type: "codeBlock"}
+	if language != "" {
+		node.Attrs = map[string]interface{}{"language": language}
+	}
+	if code != "" {
+		node.Content = []*ADFNode{{Type: "text", Text: code}}
+	}
+	return node
+}
+
+// ParseADF converts an ADF document to a Page. Nodes without an IR
+// equivalent (mentions, dates, decision lists, media without a filename,
+// third-party extensions and so on) are simplified or dropped and reported.
+func ParseADF(doc *ADFNode) (*Page, []FidelityIssue, error) {
+	if doc == nil {
+		return &Page{}, nil, nil
+	}
+	if doc.Type != "doc" {
+		return nil, nil, fmt.Errorf("expected ADF doc node, got %q", doc.Type)
+	}
+	r := &adfReader{}
+	return &Page{Blocks: r.blocks(doc.Content, "content")}, r.issues, nil
+}
+
+type adfReader struct {
+	issues []FidelityIssue
+}
+
+func (r *adfReader) report(path, node, reason string) {
+	r.issues = append(r.issues, FidelityIssue{Path: path, Node: node, Reason: reason})
+}
+
+func (r *adfReader) blocks(nodes []*ADFNode, path string) []Block {
+	var blocks []Block
+	for i, node := range nodes {
+		blocks = append(blocks, r.block(node, fmt.Sprintf("%s[%d]", path, i))...)
+	}
+	return blocks
+}
+
+// block converts a block node. Media groups may yield several blocks.
+func (r *adfReader) block(n *ADFNode, path string) []Block {
+	switch n.Type {
+	case "paragraph":
+		if len(n.Content) == 1 && n.Content[0].Type == "status" {
+			return []Block{adfStatus(n.Content[0])}
+		}
+		text, spans := r.inline(n.Content, path+".content")
+		return []Block{&Paragraph{Text: text, Spans: spans}}
+	case "heading":
+		text, spans := r.inline(n.Content, path+".content")
+		if spans != nil {
+			r.report(path, "heading", "heading formatting dropped")
+		}
+		return []Block{&Heading{Level: min(max(1, adfInt(n.Attrs["level"])), 6), Text: text}}
+	case "bulletList":
+		return []Block{&BulletList{Items: r.listItems(n.Content, path+".content")}}
+	case "orderedList":
+		return []Block{&NumberedList{Items: r.listItems(n.Content, path+".content")}}
+	case "taskList":
+		return []Block{r.taskList(n, path)}
+	case "decisionList":
+		r.report(path, n.Type, "decision list converted to a bullet list")
+		var items []ListItem
+		for i, item := range n.Content {
+			text, spans := r.inline(item.Content, fmt.Sprintf("%s.content[%d].content", path, i))
+			items = append(items, ListItem{Text: text, Spans: spans})
+		}
+		return []Block{&BulletList{Items: items}}
+	case "codeBlock":
+		language, _ := n.Attrs["language"].(string)
+		return []Block{&CodeBlock{Language: language, Code: adfText(n.Content)}}
+	case "rule":
+		return []Block{&HorizontalRule{}}
+	case "blockquote":
+		return []Block{&Blockquote{Blocks: r.blocks(n.Content, path+".content")}}
+	case "panel":
+		return []Block{r.panel(n, path)}
+	case "expand", "nestedExpand":
+		title, _ := n.Attrs["title"].(string)
+		macro := &Macro{Name: "expand", Params: map[string]string{}, Body: r.body(n.Content, path)}
+		if title != "" {
+			macro.Params["title"] = title
+		}
+		return []Block{macro}
+	case "table":
+		return []Block{r.table(n, path)}
+	case "mediaSingle", "mediaGroup":
+		var blocks []Block
+		for i, media := range n.Content {
+			if image := r.media(media, fmt.Sprintf("%s.content[%d]", path, i)); image != nil {
+				blocks = append(blocks, image)
+			}
+		}
+		return blocks
+	case "extension", "bodiedExtension":
+		if macro := r.extension(n, path); macro != nil {
+			return []Block{macro}
+		}
+		return nil
+	default:
+		r.report(path, n.Type, "no IR equivalent")
+		return nil
+	}
+}
+
+func (r *adfReader) listItems(nodes []*ADFNode, path string) []ListItem {
+	items := make([]ListItem, 0, len(nodes))
+	for i, node := range nodes {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		var item ListItem
+		start := 0
+		if len(node.Content) > 0 && node.Content[0].Type == "paragraph" {
+			item.Text, item.Spans = r.inline(node.Content[0].Content, itemPath+".content[0].content")
+			start = 1
+		}
+		for j := start; j < len(node.Content); j++ {
+			item.Children = append(item.Children, r.block(node.Content[j], fmt.Sprintf("%s.content[%d]", itemPath, j))...)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// taskList converts a task list, attaching nested task lists to the task
+// item before them.
+func (r *adfReader) taskList(n *ADFNode, path string) *TaskList {
+	tl := &TaskList{}
+	for i, node := range n.Content {
+		nodePath := fmt.Sprintf("%s.content[%d]", path, i)
+		switch node.Type {
+		case "taskItem":
+			state, _ := node.Attrs["state"].(string)
+			text, spans := r.inline(node.Content, nodePath+".content")
+			tl.Items = append(tl.Items, TaskItem{Text: text, Spans: spans, Done: state == "DONE"})
+		case "taskList":
+			if len(tl.Items) == 0 {
+				tl.Items = append(tl.Items, TaskItem{})
+			}
+			last := &tl.Items[len(tl.Items)-1]
+			last.Children = append(last.Children, r.taskList(node, nodePath))
+		default:
+			r.report(nodePath, node.Type, "unexpected node in task list")
+		}
+	}
+	return tl
+}
+
+func (r *adfReader) panel(n *ADFNode, path string) Block {
+	panelType, _ := n.Attrs["panelType"].(string)
+	name := ""
+	for macro, t := range panelTypes {
+		if t == panelType {
+			name = macro
+		}
+	}
+	if name == "" {
+		r.report(path, "panel", "panel type "+strconv.Quote(panelType)+" converted to an info panel")
+		name = "info"
+	}
+	return &Macro{Name: name, Params: map[string]string{}, Body: r.body(n.Content, path)}
+}
+
+// body renders nested ADF content as Storage XHTML for a macro body. The
+// empty paragraph ADF requires in an otherwise empty container is dropped.
+func (r *adfReader) body(nodes []*ADFNode, path string) string {
+	blocks := r.blocks(nodes, path+".content")
+	if len(blocks) == 1 {
+		if p, ok := blocks[0].(*Paragraph); ok && p.Text == "" {
+			return ""
+		}
+	}
+	xhtml, err := Render(&Page{Blocks: blocks})
+	if err != nil {
+		r.report(path, "body", err.Error())
+		return ""
+	}
+	return xhtml
+}
+
+func (r *adfReader) table(n *ADFNode, path string) *Table {
+	table := &Table{}
+	for i, row := range n.Content {
+		rowPath := fmt.Sprintf("%s.content[%d]", path, i)
+		header := i == 0 && len(row.Content) > 0
+		for _, cell := range row.Content {
+			header = header && cell.Type == "tableHeader"
+		}
+
+		var cells []Cell
+		for j, cell := range row.Content {
+			cellPath := fmt.Sprintf("%s.content[%d]", rowPath, j)
+			if adfInt(cell.Attrs["colspan"]) > 1 || adfInt(cell.Attrs["rowspan"]) > 1 {
+				r.report(cellPath, cell.Type, "merged cells are not supported")
+			}
+			cells = append(cells, r.cell(cell, cellPath))
+		}
+
+		if header {
+			for _, cell := range cells {
+				table.Headers = append(table.Headers, cell.Text)
+			}
+			continue
+		}
+		table.Rows = append(table.Rows, Row{Cells: cells})
+	}
+	return table
+}
+
+func (r *adfReader) cell(n *ADFNode, path string) Cell {
+	if len(n.Content) == 1 {
+		switch child := n.Content[0]; {
+		case child.Type == "paragraph" && len(child.Content) == 1 && child.Content[0].Type == "status":
+			return Cell{Macro: adfStatus(child.Content[0])}
+		case child.Type == "extension":
+			if macro := r.extension(child, path+".content[0]"); macro != nil {
+				return Cell{Macro: macro}
+			}
+			return Cell{}
+		}
+	}
+
+	var parts []string
+	for i, child := range n.Content {
+		childPath := fmt.Sprintf("%s.content[%d]", path, i)
+		var text string
+		if child.Type == "paragraph" {
+			var spans []Span
+			if text, spans = r.inline(child.Content, childPath+".content"); spans != nil {
+				r.report(childPath, child.Type, "table cell formatting dropped")
+			}
+		} else {
+			r.report(childPath, child.Type, "table cell content flattened to text")
+			text = strings.TrimSpace(adfText(child.Content))
+		}
+		if text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return Cell{Text: strings.Join(parts, " ")}
+}
+
+func (r *adfReader) media(n *ADFNode, path string) Block {
+	if n.Type != "media" {
+		r.report(path, n.Type, "no IR equivalent")
+		return nil
+	}
+	alt, _ := n.Attrs["alt"].(string)
+	switch mediaType, _ := n.Attrs["type"].(string); mediaType {
+	case "external":
+		url, _ := n.Attrs["url"].(string)
+		return &Image{URL: url, Alt: alt}
+	case "file":
+		// Confluence annotates attachment media with the attachment's filename.
+		if name, _ := n.Attrs["__fileName"].(string); name != "" {
+			return &Image{Attachment: name, Alt: alt}
+		}
+	}
+	r.report(path, "media", "media without an external URL or attachment filename")
+	return nil
+}
+
+func (r *adfReader) extension(n *ADFNode, path string) *Macro {
+	extensionType, _ := n.Attrs["extensionType"].(string)
+	key, _ := n.Attrs["extensionKey"].(string)
+	if extensionType != confluenceMacroExtension || key == "" {
+		r.report(path, n.Type, "extension "+strconv.Quote(extensionType)+" is not a Confluence macro")
+		return nil
+	}
+
+	macro := &Macro{Name: key, Params: map[string]string{}}
+	parameters, _ := n.Attrs["parameters"].(map[string]interface{})
+	params, _ := parameters["macroParams"].(map[string]interface{})
+	for k, v := range params {
+		if param, ok := v.(map[string]interface{}); ok {
+			macro.Params[k], _ = param["value"].(string)
+		}
+	}
+	if n.Type == "bodiedExtension" {
+		macro.Body = r.body(n.Content, path)
+	}
+	return macro
+}
+
+// inline converts inline nodes to plain text and, if any formatting is
+// present, spans.
+func (r *adfReader) inline(nodes []*ADFNode, path string) (string, []Span) {
+	b := &inlineBuilder{}
+	for i, n := range nodes {
+		nodePath := fmt.Sprintf("%s[%d]", path, i)
+		switch n.Type {
+		case "text":
+			var mark Span
+			for _, m := range n.Marks {
+				switch m.Type {
+				case "strong":
+					mark.Bold = true
+				case "em":
+					mark.Italic = true
+				case "code":
+					mark.Code = true
+				case "strike":
+					mark.Strike = true
+				case "link":
+					mark.Link, _ = m.Attrs["href"].(string)
+				default:
+					r.report(nodePath, m.Type, "mark dropped")
+					continue
+				}
+				b.marked = true
+			}
+			b.add(n.Text, mark)
+		case "hardBreak":
+			b.add("\n", Span{})
+		case "inlineCard":
+			url, _ := n.Attrs["url"].(string)
+			b.add(url, Span{Link: url})
+			b.marked = true
+		case "emoji":
+			text, _ := n.Attrs["text"].(string)
+			if text == "" {
+				text, _ = n.Attrs["shortName"].(string)
+			}
+			b.add(text, Span{})
+		case "mention":
+			text, _ := n.Attrs["text"].(string)
+			r.report(nodePath, n.Type, "mention converted to text")
+			b.add(text, Span{})
+		case "date":
+			r.report(nodePath, n.Type, "date converted to text")
+			b.add(adfDate(n), Span{})
+		case "status":
+			r.report(nodePath, n.Type, "inline status converted to text")
+			text, _ := n.Attrs["text"].(string)
+			b.add("["+text+"]", Span{})
+		default:
+			r.report(nodePath, n.Type, "no IR equivalent")
+		}
+	}
+	return b.result()
+}
+
+func adfStatus(n *ADFNode) *Macro {
+	text, _ := n.Attrs["text"].(string)
+	colour, _ := n.Attrs["color"].(string)
+	macro := &Macro{Name: "status", Params: map[string]string{"title": text}}
+	for name, c := range statusColours {
+		if c == colour && name != "grey" {
+			macro.Params["colour"] = strings.ToUpper(name[:1]) + name[1:]
+		}
+	}
+	return macro
+}
+
+// adfInt reads a numeric attribute, which is a float64 when the document was
+// decoded from JSON.
+func adfInt(v interface{}) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case float64:
+		return int(n)
+	}
+	return 0
+}
+
+// adfText concatenates the text of nodes and their descendants.
+func adfText(nodes []*ADFNode) string {
+	var buf strings.Builder
+	for _, n := range nodes {
+		buf.WriteString(n.Text)
+		buf.WriteString(adfText(n.Content))
+	}
+	return buf.String()
+}
+
+// adfDate formats a date node's millisecond timestamp as YYYY-MM-DD.
+func adfDate(n *ADFNode) string {
+	timestamp, _ := n.Attrs["timestamp"].(string)
+	ms, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return timestamp
+	}
+	return time.UnixMilli(ms).UTC().Format("2006-01-02")
+}
@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// adfRoundTrip converts page to ADF JSON and back.
+func adfRoundTrip(t *testing.T, page *Page) (*Page, []FidelityIssue, []FidelityIssue) {
+	t.Helper()
+	doc, renderIssues, err := RenderADF(page)
+	if err != nil {
+		t.Fatalf("RenderADF() error = %v", err)
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded ADFNode
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	got, parseIssues, err := ParseADF(&decoded)
+	if err != nil {
+		t.Fatalf("ParseADF() error = %v", err)
+	}
+	return got, renderIssues, parseIssues
+}
+
+func TestADFRoundTrip(t *testing.T) {
+	xhtml := `<h2>Status</h2>` +
+		`<p>Plain <strong>bold</strong> <em>it</em> <code>x</code> <s>old</s> <a href="https://example.com">link</a></p>` +
+		`<table><tbody><tr><th>Service</th><th>State</th></tr>` +
+		`<tr><td>API</td><td><ac:structured-macro ac:name="status"><ac:parameter ac:name="colour">Green</ac:parameter><ac:parameter ac:name="title">OK</ac:parameter></ac:structured-macro></td></tr></tbody></table>` +
+		`<ul><li>one<ol><li>nested</li></ol></li><li>two</li></ul>` +
+		`<ac:task-list><ac:task><ac:task-status>complete</ac:task-status><ac:task-body>done</ac:task-body></ac:task>` +
+		`<ac:task><ac:task-status>incomplete</ac:task-status><ac:task-body>open<ac:task-list><ac:task><ac:task-status>incomplete</ac:task-status><ac:task-body>sub</ac:task-body></ac:task></ac:task-list></ac:task-body></ac:task></ac:task-list>` +
+		`<ac:structured-macro ac:name="code"><ac:parameter ac:name="language">go</ac:parameter><ac:plain-text-body><![CDATA[fmt.Println("hi")]]></ac:plain-text-body></ac:structured-macro>` +
+		`<hr/>` +
+		`<blockquote><p>quoted</p></blockquote>` +
+		`<ac:image ac:alt="Logo"><ri:url ri:value="https://example.com/logo.png"/></ac:image>` +
+		`<ac:structured-macro ac:name="warning"><ac:rich-text-body><p>Careful</p></ac:rich-text-body></ac:structured-macro>` +
+		`<ac:structured-macro ac:name="expand"><ac:parameter ac:name="title">More</ac:parameter><ac:rich-text-body><p>Hidden</p></ac:rich-text-body></ac:structured-macro>` +
+		`<ac:structured-macro ac:name="toc"><ac:parameter ac:name="maxLevel">2</ac:parameter></ac:structured-macro>`
+
+	page, err := Parse(xhtml)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	got, renderIssues, parseIssues := adfRoundTrip(t, page)
+	if len(renderIssues) != 0 || len(parseIssues) != 0 {
+		t.Errorf("unexpected fidelity issues: render %+v, parse %+v", renderIssues, parseIssues)
+	}
+
+	want, err := Render(page)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	gotXHTML, err := Render(got)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if gotXHTML != want {
+		t.Errorf("round trip =\n%s\nwant\n%s", gotXHTML, want)
+	}
+}
+
+func TestRenderADF(t *testing.T) {
+	page := &Page{Blocks: []Block{
+		&Heading{Level: 1, Text: "Title"},
+		&Paragraph{Spans: []Span{{Text: "a "}, {Text: "b", Bold: true, Link: "https://example.com"}}},
+		&Macro{Name: "info", Body: "<p>Note</p>"},
+	}}
+
+	doc, issues, err := RenderADF(page)
+	if err != nil {
+		t.Fatalf("RenderADF() error = %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("RenderADF() issues = %+v", issues)
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `{"type":"doc","version":1,"content":[` +
+		`{"type":"heading","attrs":{"level":1},"content":[{"type":"text","text":"Title"}]},` +
+		`{"type":"paragraph","content":[{"type":"text","text":"a "},{"type":"text","text":"b","marks":[{"type":"strong"},{"type":"link","attrs":{"href":"https://example.com"}}]}]},` +
+		`{"type":"panel","attrs":{"panelType":"info"},"content":[{"type":"paragraph","content":[{"type":"text","text":"Note"}]}]}]}`
+	if string(data) != want {
+		t.Errorf("RenderADF() =\n%s\nwant\n%s", data, want)
+	}
+
+	empty, _, err := RenderADF(&Page{})
+	if err != nil {
+		t.Fatalf("RenderADF() error = %v", err)
+	}
+	if data, _ := json.Marshal(empty); string(data) != `{"type":"doc","version":1,"content":[]}` {
+		t.Errorf("RenderADF(empty) = %s", data)
+	}
+}
+
+func TestRenderADFFidelity(t *testing.T) {
+	page := &Page{Blocks: []Block{
+		&Image{Attachment: "diagram.png"},
+		&Paragraph{Spans: []Span{{Text: "Runbook", Page: "Runbook"}}},
+		&Macro{Name: "note", Params: map[string]string{"title": "Heads up"}, Body: "<p>x</p>"},
+		&TaskList{Items: []TaskItem{{Text: "a", Children: []Block{&BulletList{Items: []ListItem{{Text: "b"}}}}}}},
+	}}
+
+	_, issues, err := RenderADF(page)
+	if err != nil {
+		t.Fatalf("RenderADF() error = %v", err)
+	}
+	wantPaths := []string{"blocks[0]", "blocks[1]", "blocks[2]", "blocks[3].items[0].children[0]"}
+	if len(issues) != len(wantPaths) {
+		t.Fatalf("RenderADF() issues = %+v, want %d", issues, len(wantPaths))
+	}
+	for i, path := range wantPaths {
+		if issues[i].Path != path {
+			t.Errorf("issues[%d].Path = %s, want %s", i, issues[i].Path, path)
+		}
+	}
+}
+
+func TestParseADF(t *testing.T) {
+	input := `{"type":"doc","version":1,"content":[
+		{"type":"paragraph","content":[
+			{"type":"text","text":"Hi "},
+			{"type":"mention","attrs":{"id":"1","text":"@Ana"}},
+			{"type":"text","text":" see","marks":[{"type":"underline"}]},
+			{"type":"hardBreak"},
+			{"type":"inlineCard","attrs":{"url":"https://example.com"}}
+		]},
+		{"type":"panel","attrs":{"panelType":"note"},"content":[{"type":"paragraph","content":[{"type":"text","text":"Purple"}]}]},
+		{"type":"mediaSingle","content":[{"type":"media","attrs":{"type":"file","id":"abc","collection":"c","__fileName":"a.png","alt":"A"}}]},
+		{"type":"table","content":[
+			{"type":"tableRow","content":[{"type":"tableCell","attrs":{"colspan":2},"content":[{"type":"bulletList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"x"}]}]}]}]}]}
+		]},
+		{"type":"extension","attrs":{"extensionType":"com.example","extensionKey":"widget"}},
+		{"type":"layoutSection","content":[]}
+	]}`
+
+	var doc ADFNode
+	if err := json.Unmarshal([]byte(input), &doc); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	page, issues, err := ParseADF(&doc)
+	if err != nil {
+		t.Fatalf("ParseADF() error = %v", err)
+	}
+
+	got, err := Render(page)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	want := `<p>Hi @Ana see` + "\n" + `<a href="https://example.com">https://example.com</a></p>` +
+		`<ac:structured-macro ac:name="info"><ac:rich-text-body><p>Purple</p></ac:rich-text-body></ac:structured-macro>` +
+		`<ac:image ac:alt="A"><ri:attachment ri:filename="a.png"/></ac:image>` +
+		`<table><tbody><tr><td>x</td></tr></tbody></table>`
+	if got != want {
+		t.Errorf("ParseADF() rendered =\n%s\nwant\n%s", got, want)
+	}
+
+	var reasons []string
+	for _, issue := range issues {
+		reasons = append(reasons, issue.Path+" "+issue.Node)
+	}
+	wantIssues := []string{
+		"content[0].content[1] mention",
+		"content[0].content[2] underline",
+		"content[1] panel",
+		"content[3].content[0].content[0] tableCell",
+		"content[3].content[0].content[0].content[0] bulletList",
+		"content[4] extension",
+		"content[5] layoutSection",
+	}
+	if strings.Join(reasons, "\n") != strings.Join(wantIssues, "\n") {
+		t.Errorf("ParseADF() issues =\n%s\nwant\n%s", strings.Join(reasons, "\n"), strings.Join(wantIssues, "\n"))
+	}
+}
+
+func TestParseADFNotDoc(t *testing.T) {
+	if _, _, err := ParseADF(&ADFNode{Type: "paragraph"}); err == nil {
+		t.Error("ParseADF() should reject a non-doc node")
+	}
+}
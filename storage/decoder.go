@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	acNamespace = "http://atlassian.com/content"
+	riNamespace = "http://atlassian.com/resource/identifier"
+
+	rootOpen  = "<root xmlns:ac=\"" + acNamespace + "\" xmlns:ri=\"" + riNamespace + "\">"
+	rootClose = "</root>"
+)
+
+// namespacePrefixes maps the namespaces declared by rootOpen back to the
+// prefixes used in Storage Format.
+var namespacePrefixes = map[string]string{
+	acNamespace: "ac",
+	riNamespace: "ri",
+}
+
+// Decoder reads top-level blocks from a Storage XHTML stream one at a time.
+// Unlike Parse, it never holds more than the block being decoded in memory,
+// which makes it suitable for indexing large pages or whole spaces.
+type Decoder struct {
+	ctx context.Context
+	xml *xml.Decoder
+}
+
+// NewDecoder returns a Decoder that reads Storage XHTML from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return NewDecoderContext(context.Background(), r)
+}
+
+// NewDecoderContext returns a Decoder that reads Storage XHTML from r and
+// stops with ctx.Err() once ctx is cancelled.
+func NewDecoderContext(ctx context.Context, r io.Reader) *Decoder {
+	wrapped := io.MultiReader(
+		strings.NewReader(rootOpen),
+		&contextReader{ctx: ctx, r: r},
+		strings.NewReader(rootClose),
+	)
+	decoder := xml.NewDecoder(wrapped)
+	decoder.Entity = htmlEntities
+	return &Decoder{ctx: ctx, xml: decoder}
+}
+
+// Next returns the next top-level block. It returns io.EOF when the stream
+// is exhausted. Unknown top-level elements are skipped.
+func (d *Decoder) Next() (Block, error) {
+	for {
+		if err := d.ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		tok, err := d.xml.Token()
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse error: %w", err)
+		}
+
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		block, err := parseElement(d.xml, se)
+		if err != nil {
+			return nil, err
+		}
+		if block != nil {
+			return block, nil
+		}
+	}
+}
+
+// contextReader fails reads once its context is done, so that cancellation
+// interrupts a single oversized block rather than waiting for it to finish.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDecoderNext(t *testing.T) {
+	xhtml := `<h1>Title</h1><div>skipped</div><p>Intro</p>` +
+		`<table><tbody><tr><th>A</th></tr><tr><td>1</td></tr></tbody></table><hr/>`
+
+	decoder := NewDecoder(strings.NewReader(xhtml))
+
+	wantTypes := []string{"heading", "paragraph", "table", "horizontal_rule"}
+	for i, want := range wantTypes {
+		block, err := decoder.Next()
+		if err != nil {
+			t.Fatalf("Next() #%d error = %v", i, err)
+		}
+		if block.BlockType() != want {
+			t.Errorf("Next() #%d type = %v, want %v", i, block.BlockType(), want)
+		}
+	}
+
+	if _, err := decoder.Next(); err != io.EOF {
+		t.Errorf("Next() after last block error = %v, want io.EOF", err)
+	}
+}
+
+func TestDecoderEmpty(t *testing.T) {
+	decoder := NewDecoder(strings.NewReader(""))
+	if _, err := decoder.Next(); err != io.EOF {
+		t.Errorf("Next() on empty input error = %v, want io.EOF", err)
+	}
+}
+
+func TestDecoderInvalidXML(t *testing.T) {
+	decoder := NewDecoder(strings.NewReader("<p>ok</p><p>unclosed"))
+
+	if _, err := decoder.Next(); err != nil {
+		t.Fatalf("Next() first block error = %v", err)
+	}
+	if _, err := decoder.Next(); err == nil || err == io.EOF {
+		t.Errorf("Next() on malformed block error = %v, want parse error", err)
+	}
+}
+
+func TestDecoderContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	decoder := NewDecoderContext(ctx, strings.NewReader("<p>One</p><p>Two</p>"))
+
+	if _, err := decoder.Next(); err != nil {
+		t.Fatalf("Next() before cancel error = %v", err)
+	}
+
+	cancel()
+
+	if _, err := decoder.Next(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Next() after cancel error = %v, want context.Canceled", err)
+	}
+}
+
+func TestDecoderMatchesParse(t *testing.T) {
+	xhtml := `<h2>Owners</h2><ul><li>Alice</li><li>Bob</li></ul>` +
+		`<ac:structured-macro ac:name="info"><ac:rich-text-body><p>Note</p></ac:rich-text-body></ac:structured-macro>`
+
+	page, err := Parse(xhtml)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	decoder := NewDecoder(strings.NewReader(xhtml))
+	var streamed []Block
+	for {
+		block, err := decoder.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		streamed = append(streamed, block)
+	}
+
+	if len(streamed) != len(page.Blocks) {
+		t.Fatalf("Decoder yielded %d blocks, Parse() returned %d", len(streamed), len(page.Blocks))
+	}
+	for i := range streamed {
+		if streamed[i].BlockType() != page.Blocks[i].BlockType() {
+			t.Errorf("block %d type = %v, want %v", i, streamed[i].BlockType(), page.Blocks[i].BlockType())
+		}
+	}
+}
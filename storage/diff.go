@@ -0,0 +1,662 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// EditOp identifies the kind of change described by an edit.
+type EditOp string
+
+// Edit operations produced by Diff.
+const (
+	EditInsert EditOp = "insert"
+	EditDelete EditOp = "delete"
+	EditModify EditOp = "modify"
+	EditMove   EditOp = "move"
+)
+
+// PageDiff is the block-level difference between two pages.
+type PageDiff struct {
+	Edits []BlockEdit `json:"edits"`
+}
+
+// BlockEdit describes a change to one top-level block. OldIndex is -1 for
+// inserts and NewIndex is -1 for deletes. Modified tables and lists carry
+// nested row, cell and item edits.
+type BlockEdit struct {
+	Op       EditOp        `json:"op"`
+	Type     string        `json:"type"`
+	OldIndex int           `json:"old_index"`
+	NewIndex int           `json:"new_index"`
+	Old      Block         `json:"-"`
+	New      Block         `json:"-"`
+	Headers  *HeaderChange `json:"headers,omitempty"`
+	Rows     []RowEdit     `json:"rows,omitempty"`
+	Items    []ItemEdit    `json:"items,omitempty"`
+}
+
+// HeaderChange records a change to a table's header row.
+type HeaderChange struct {
+	Old []string `json:"old"`
+	New []string `json:"new"`
+}
+
+// RowEdit describes a change to a table row.
+type RowEdit struct {
+	Op       EditOp     `json:"op"`
+	OldIndex int        `json:"old_index"`
+	NewIndex int        `json:"new_index"`
+	Old      *Row       `json:"old,omitempty"`
+	New      *Row       `json:"new,omitempty"`
+	Cells    []CellEdit `json:"cells,omitempty"`
+}
+
+// CellEdit describes a changed cell within a modified row.
+type CellEdit struct {
+	Column int    `json:"column"`
+	Header string `json:"header,omitempty"`
+	Old    Cell   `json:"old"`
+	New    Cell   `json:"new"`
+}
+
+// ItemEdit describes a change to a list item.
+type ItemEdit struct {
+	Op       EditOp    `json:"op"`
+	OldIndex int       `json:"old_index"`
+	NewIndex int       `json:"new_index"`
+	Old      *ListItem `json:"old,omitempty"`
+	New      *ListItem `json:"new,omitempty"`
+}
+
+// MarshalJSON includes the old and new blocks tagged with their type.
+func (e BlockEdit) MarshalJSON() ([]byte, error) {
+	type plain BlockEdit
+	out := struct {
+		plain
+		Old json.RawMessage `json:"old,omitempty"`
+		New json.RawMessage `json:"new,omitempty"`
+	}{plain: plain(e)}
+
+	var err error
+	if e.Old != nil {
+		if out.Old, err = marshalBlock(e.Old); err != nil {
+			return nil, err
+		}
+	}
+	if e.New != nil {
+		if out.New, err = marshalBlock(e.New); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(out)
+}
+
+// marshalBlock encodes a block as a JSON object with a "type" field.
+func marshalBlock(b Block) (json.RawMessage, error) {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	fields["type"], _ = json.Marshal(b.BlockType())
+	return json.Marshal(fields)
+}
+
+// Empty reports whether the diff contains no edits.
+func (d *PageDiff) Empty() bool {
+	return d == nil || len(d.Edits) == 0
+}
+
+// Diff computes the block-level edits that turn old into new. Identical
+// blocks that changed position are reported as moves; changed blocks of the
+// same type between unchanged anchors are reported as modifications with
+// nested diffs for table rows/cells and list items.
+func Diff(old, new *Page) *PageDiff {
+	oldBlocks, newBlocks := pageBlocks(old), pageBlocks(new)
+	oldKeys, newKeys := fingerprints(oldBlocks), fingerprints(newBlocks)
+
+	diff := &PageDiff{Edits: []BlockEdit{}}
+	for _, h := range hunks(align(oldKeys, newKeys)) {
+		for _, p := range pairHunk(h, oldKeys, newKeys, func(o, n int) bool {
+			return oldBlocks[o].BlockType() == newBlocks[n].BlockType()
+		}) {
+			diff.Edits = append(diff.Edits, blockEdit(p, oldBlocks, newBlocks))
+		}
+	}
+	detectMoves(diff, oldKeys, newKeys)
+	return diff
+}
+
+func pageBlocks(p *Page) []Block {
+	if p == nil {
+		return nil
+	}
+	return p.Blocks
+}
+
+// fingerprint returns a key that is equal for blocks with equal content.
+// The rendered XHTML is used so that nil and empty slices compare equal;
+// blocks that cannot be rendered fall back to their JSON encoding.
+func fingerprint(b Block) string {
+	if xhtml, err := RenderBlock(b); err == nil {
+		return b.BlockType() + ":" + xhtml
+	}
+	return b.BlockType() + ":" + jsonKey(b)
+}
+
+// pair is one step of an alignment: both indexes set for a match or
+// modification, one of them -1 for an insert or delete.
+type pair struct {
+	old, new int
+}
+
+// align returns the longest-common-subsequence alignment of two key lists.
+func align(a, b []string) []pair {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var result []pair
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			result = append(result, pair{i, j})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			result = append(result, pair{i, -1})
+			i++
+		default:
+			result = append(result, pair{-1, j})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		result = append(result, pair{i, -1})
+	}
+	for ; j < m; j++ {
+		result = append(result, pair{-1, j})
+	}
+	return result
+}
+
+// hunks groups consecutive unmatched steps of an alignment.
+func hunks(steps []pair) [][]pair {
+	var result [][]pair
+	var current []pair
+	for _, s := range steps {
+		if s.old >= 0 && s.new >= 0 {
+			if len(current) > 0 {
+				result = append(result, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, s)
+	}
+	if len(current) > 0 {
+		result = append(result, current)
+	}
+	return result
+}
+
+// pairHunk turns a hunk of deletes and inserts into edits, pairing each
+// deleted element with the next inserted element it is comparable with.
+// Paired steps have both indexes set.
+func pairHunk(h []pair, oldKeys, newKeys []string, comparable func(o, n int) bool) []pair {
+	var dels, ins []int
+	for _, s := range h {
+		if s.old >= 0 {
+			dels = append(dels, s.old)
+		} else {
+			ins = append(ins, s.new)
+		}
+	}
+
+	matched := make(map[int]int) // new index -> old index
+	used := make(map[int]bool)   // old index
+	for _, n := range ins {
+		for _, o := range dels {
+			if !used[o] && comparable(o, n) && oldKeys[o] != newKeys[n] {
+				matched[n] = o
+				used[o] = true
+				break
+			}
+		}
+	}
+
+	var result []pair
+	for _, o := range dels {
+		if !used[o] {
+			result = append(result, pair{o, -1})
+		}
+	}
+	for _, n := range ins {
+		if o, ok := matched[n]; ok {
+			result = append(result, pair{o, n})
+		} else {
+			result = append(result, pair{-1, n})
+		}
+	}
+	return result
+}
+
+func blockEdit(p pair, oldBlocks, newBlocks []Block) BlockEdit {
+	switch {
+	case p.new < 0:
+		b := oldBlocks[p.old]
+		return BlockEdit{Op: EditDelete, Type: b.BlockType(), OldIndex: p.old, NewIndex: -1, Old: b}
+	case p.old < 0:
+		b := newBlocks[p.new]
+		return BlockEdit{Op: EditInsert, Type: b.BlockType(), OldIndex: -1, NewIndex: p.new, New: b}
+	}
+
+	oldBlock, newBlock := oldBlocks[p.old], newBlocks[p.new]
+	edit := BlockEdit{
+		Op:       EditModify,
+		Type:     newBlock.BlockType(),
+		OldIndex: p.old,
+		NewIndex: p.new,
+		Old:      oldBlock,
+		New:      newBlock,
+	}
+	if oldTable, ok := asTable(oldBlock); ok {
+		newTable, _ := asTable(newBlock)
+		edit.Headers, edit.Rows = diffTable(oldTable, newTable)
+	}
+	if oldItems, ok := listItems(oldBlock); ok {
+		newItems, _ := listItems(newBlock)
+		edit.Items = diffItems(oldItems, newItems)
+	}
+	return edit
+}
+
+// detectMoves converts delete/insert pairs of identical blocks into moves.
+func detectMoves(d *PageDiff, oldKeys, newKeys []string) {
+	var edits []BlockEdit
+	moved := make(map[int]bool) // index into d.Edits of consumed deletes
+	for i, e := range d.Edits {
+		if e.Op != EditInsert {
+			continue
+		}
+		for j, del := range d.Edits {
+			if del.Op == EditDelete && !moved[j] && oldKeys[del.OldIndex] == newKeys[e.NewIndex] {
+				moved[j] = true
+				d.Edits[i] = BlockEdit{
+					Op:       EditMove,
+					Type:     e.Type,
+					OldIndex: del.OldIndex,
+					NewIndex: e.NewIndex,
+					Old:      del.Old,
+					New:      e.New,
+				}
+				break
+			}
+		}
+	}
+	for j, e := range d.Edits {
+		if !moved[j] {
+			edits = append(edits, e)
+		}
+	}
+	d.Edits = edits
+	if d.Edits == nil {
+		d.Edits = []BlockEdit{}
+	}
+}
+
+func asTable(b Block) (*Table, bool) {
+	switch t := b.(type) {
+	case *Table:
+		return t, true
+	case Table:
+		return &t, true
+	}
+	return nil, false
+}
+
+func listItems(b Block) ([]ListItem, bool) {
+	switch l := b.(type) {
+	case *BulletList:
+		return l.Items, true
+	case BulletList:
+		return l.Items, true
+	case *NumberedList:
+		return l.Items, true
+	case NumberedList:
+		return l.Items, true
+	}
+	return nil, false
+}
+
+func diffTable(old, new *Table) (*HeaderChange, []RowEdit) {
+	var headers *HeaderChange
+	if tableLine(old.Headers) != tableLine(new.Headers) || len(old.Headers) != len(new.Headers) {
+		headers = &HeaderChange{Old: old.Headers, New: new.Headers}
+	}
+
+	oldKeys := make([]string, len(old.Rows))
+	for i := range old.Rows {
+		oldKeys[i] = rowLine(&old.Rows[i])
+	}
+	newKeys := make([]string, len(new.Rows))
+	for i := range new.Rows {
+		newKeys[i] = rowLine(&new.Rows[i])
+	}
+
+	var edits []RowEdit
+	for _, h := range hunks(align(oldKeys, newKeys)) {
+		for _, p := range pairHunk(h, oldKeys, newKeys, func(int, int) bool { return true }) {
+			edit := RowEdit{OldIndex: p.old, NewIndex: p.new}
+			switch {
+			case p.new < 0:
+				edit.Op = EditDelete
+				edit.Old = &old.Rows[p.old]
+			case p.old < 0:
+				edit.Op = EditInsert
+				edit.New = &new.Rows[p.new]
+			default:
+				edit.Op = EditModify
+				edit.Old = &old.Rows[p.old]
+				edit.New = &new.Rows[p.new]
+				edit.Cells = diffCells(edit.Old, edit.New, new.Headers)
+			}
+			edits = append(edits, edit)
+		}
+	}
+	return headers, edits
+}
+
+func diffCells(old, new *Row, headers []string) []CellEdit {
+	n := len(old.Cells)
+	if len(new.Cells) > n {
+		n = len(new.Cells)
+	}
+	var edits []CellEdit
+	for i := 0; i < n; i++ {
+		var o, c Cell
+		if i < len(old.Cells) {
+			o = old.Cells[i]
+		}
+		if i < len(new.Cells) {
+			c = new.Cells[i]
+		}
+		if jsonKey(o) == jsonKey(c) {
+			continue
+		}
+		edit := CellEdit{Column: i, Old: o, New: c}
+		if i < len(headers) {
+			edit.Header = headers[i]
+		}
+		edits = append(edits, edit)
+	}
+	return edits
+}
+
+func diffItems(old, new []ListItem) []ItemEdit {
+	oldKeys := make([]string, len(old))
+	for i, item := range old {
+		oldKeys[i] = jsonKey(item)
+	}
+	newKeys := make([]string, len(new))
+	for i, item := range new {
+		newKeys[i] = jsonKey(item)
+	}
+
+	var edits []ItemEdit
+	for _, h := range hunks(align(oldKeys, newKeys)) {
+		for _, p := range pairHunk(h, oldKeys, newKeys, func(int, int) bool { return true }) {
+			edit := ItemEdit{OldIndex: p.old, NewIndex: p.new}
+			switch {
+			case p.new < 0:
+				edit.Op = EditDelete
+				edit.Old = &old[p.old]
+			case p.old < 0:
+				edit.Op = EditInsert
+				edit.New = &new[p.new]
+			default:
+				edit.Op = EditModify
+				edit.Old = &old[p.old]
+				edit.New = &new[p.new]
+			}
+			edits = append(edits, edit)
+		}
+	}
+	return edits
+}
+
+func jsonKey(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// Unified renders the diff as human-readable text, one hunk per edit, in
+// the style of a unified diff: removed lines start with "-", added lines
+// with "+" and context lines with a space.
+func (d *PageDiff) Unified() string {
+	if d.Empty() {
+		return ""
+	}
+	var buf strings.Builder
+	for _, e := range d.Edits {
+		writeEditHeader(&buf, e)
+		switch e.Op {
+		case EditInsert:
+			writeLines(&buf, "+", describeBlock(e.New))
+		case EditDelete:
+			writeLines(&buf, "-", describeBlock(e.Old))
+		case EditMove:
+			writeLines(&buf, " ", describeBlock(e.New))
+		case EditModify:
+			writeModify(&buf, e)
+		}
+	}
+	return buf.String()
+}
+
+func writeEditHeader(buf *strings.Builder, e BlockEdit) {
+	switch e.Op {
+	case EditInsert:
+		fmt.Fprintf(buf, "@@ insert %s at block %d @@\n", e.Type, e.NewIndex)
+	case EditDelete:
+		fmt.Fprintf(buf, "@@ delete %s at block %d @@\n", e.Type, e.OldIndex)
+	case EditMove:
+		fmt.Fprintf(buf, "@@ move %s from block %d to %d @@\n", e.Type, e.OldIndex, e.NewIndex)
+	case EditModify:
+		fmt.Fprintf(buf, "@@ modify %s at block %d (was %d) @@\n", e.Type, e.NewIndex, e.OldIndex)
+	}
+}
+
+func writeModify(buf *strings.Builder, e BlockEdit) {
+	switch {
+	case e.Rows != nil || e.Headers != nil:
+		newTable, _ := asTable(e.New)
+		if e.Headers != nil {
+			writeLines(buf, "-", []string{tableLine(e.Headers.Old)})
+			writeLines(buf, "+", []string{tableLine(e.Headers.New)})
+		} else {
+			writeLines(buf, " ", []string{tableLine(newTable.Headers)})
+		}
+		for _, r := range e.Rows {
+			if r.Old != nil {
+				writeLines(buf, "-", []string{rowLine(r.Old)})
+			}
+			if r.New != nil {
+				writeLines(buf, "+", []string{rowLine(r.New)})
+			}
+		}
+	case e.Items != nil:
+		for _, item := range e.Items {
+			if item.Old != nil {
+				writeLines(buf, "-", []string{"- " + item.Old.Text})
+			}
+			if item.New != nil {
+				writeLines(buf, "+", []string{"- " + item.New.Text})
+			}
+		}
+	default:
+		writeLines(buf, "-", describeBlock(e.Old))
+		writeLines(buf, "+", describeBlock(e.New))
+	}
+}
+
+func writeLines(buf *strings.Builder, prefix string, lines []string) {
+	for _, line := range lines {
+		buf.WriteString(prefix)
+		buf.WriteString(" ")
+		buf.WriteString(line)
+		buf.WriteString("\n")
+	}
+}
+
+// describeBlock returns a compact line-oriented description of a block
+// used for unified diff output.
+func describeBlock(b Block) []string {
+	switch v := b.(type) {
+	case *Paragraph:
+		return strings.Split(v.Text, "\n")
+	case *Heading:
+		return []string{strings.Repeat("#", v.Level) + " " + v.Text}
+	case *Table:
+		lines := []string{tableLine(v.Headers)}
+		for i := range v.Rows {
+			lines = append(lines, rowLine(&v.Rows[i]))
+		}
+		return lines
+	case *BulletList:
+		return itemLines("- ", v.Items)
+	case *NumberedList:
+		return itemLines("1. ", v.Items)
+	case *CodeBlock:
+		lines := []string{"```" + v.Language}
+		lines = append(lines, strings.Split(v.Code, "\n")...)
+		return append(lines, "```")
+	case *Macro:
+		return []string{macroLine(v)}
+	case *HorizontalRule:
+		return []string{"---"}
+	case *TaskList:
+		var lines []string
+		for _, item := range v.Items {
+			marker := "- [ ] "
+			if item.Done {
+				marker = "- [x] "
+			}
+			lines = append(lines, marker+item.Text)
+			lines = append(lines, childLines(item.Children)...)
+		}
+		return lines
+	case *Blockquote:
+		var lines []string
+		for _, child := range v.Blocks {
+			for _, line := range describeBlock(child) {
+				lines = append(lines, "> "+line)
+			}
+		}
+		return lines
+	case *Image:
+		src := v.URL
+		if v.Attachment != "" {
+			src = v.Attachment
+		}
+		return []string{"![" + v.Alt + "](" + src + ")"}
+	}
+	if p := pointerBlock(b); p != nil && p != b {
+		return describeBlock(p)
+	}
+	return []string{b.BlockType()}
+}
+
+// pointerBlock returns the pointer form of a value block so callers only
+// need to handle pointer types.
+func pointerBlock(b Block) Block {
+	switch v := b.(type) {
+	case Paragraph:
+		return &v
+	case Heading:
+		return &v
+	case Table:
+		return &v
+	case BulletList:
+		return &v
+	case NumberedList:
+		return &v
+	case CodeBlock:
+		return &v
+	case Macro:
+		return &v
+	case HorizontalRule:
+		return &v
+	case TaskList:
+		return &v
+	case Blockquote:
+		return &v
+	case Image:
+		return &v
+	}
+	return b
+}
+
+func itemLines(marker string, items []ListItem) []string {
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		lines = append(lines, marker+item.Text)
+		lines = append(lines, childLines(item.Children)...)
+	}
+	return lines
+}
+
+// childLines describes nested blocks, indented under their parent item.
+func childLines(children []Block) []string {
+	var lines []string
+	for _, child := range children {
+		for _, line := range describeBlock(child) {
+			lines = append(lines, "  "+line)
+		}
+	}
+	return lines
+}
+
+func tableLine(cells []string) string {
+	return "| " + strings.Join(cells, " | ") + " |"
+}
+
+func rowLine(r *Row) string {
+	cells := make([]string, len(r.Cells))
+	for i, c := range r.Cells {
+		if c.Macro != nil {
+			cells[i] = macroLine(c.Macro)
+		} else {
+			cells[i] = c.Text
+		}
+	}
+	return tableLine(cells)
+}
+
+func macroLine(m *Macro) string {
+	data, _ := json.Marshal(m.Params)
+	line := "{" + m.Name
+	if len(m.Params) > 0 {
+		line += " " + string(data)
+	}
+	return line + "}"
+}
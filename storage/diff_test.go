@@ -0,0 +1,234 @@
+package storage
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	base := []Block{
+		&Heading{Level: 1, Text: "Title"},
+		&Paragraph{Text: "Intro"},
+		&Paragraph{Text: "Body"},
+	}
+
+	tests := []struct {
+		name    string
+		new     []Block
+		wantOps []EditOp
+	}{
+		{
+			name:    "identical",
+			new:     base,
+			wantOps: nil,
+		},
+		{
+			name: "insert",
+			new: []Block{
+				base[0], base[1], &HorizontalRule{}, base[2],
+			},
+			wantOps: []EditOp{EditInsert},
+		},
+		{
+			name:    "delete",
+			new:     []Block{base[0], base[2]},
+			wantOps: []EditOp{EditDelete},
+		},
+		{
+			name: "modify",
+			new: []Block{
+				base[0], &Paragraph{Text: "Intro, revised"}, base[2],
+			},
+			wantOps: []EditOp{EditModify},
+		},
+		{
+			name:    "move",
+			new:     []Block{base[2], base[0], base[1]},
+			wantOps: []EditOp{EditMove},
+		},
+		{
+			name: "type change is delete and insert",
+			new: []Block{
+				base[0], &Heading{Level: 2, Text: "Intro"}, base[2],
+			},
+			wantOps: []EditOp{EditDelete, EditInsert},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Diff(&Page{Blocks: base}, &Page{Blocks: tt.new})
+			if len(d.Edits) != len(tt.wantOps) {
+				t.Fatalf("Diff() returned %d edits, want %d: %+v", len(d.Edits), len(tt.wantOps), d.Edits)
+			}
+			for i, op := range tt.wantOps {
+				if d.Edits[i].Op != op {
+					t.Errorf("Diff() edit %d op = %v, want %v", i, d.Edits[i].Op, op)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffIndexes(t *testing.T) {
+	old := &Page{Blocks: []Block{&Paragraph{Text: "A"}, &Paragraph{Text: "B"}, &Paragraph{Text: "C"}}}
+	new := &Page{Blocks: []Block{&Paragraph{Text: "C"}, &Paragraph{Text: "A"}, &Paragraph{Text: "B"}}}
+
+	d := Diff(old, new)
+	if len(d.Edits) != 1 {
+		t.Fatalf("Diff() returned %d edits, want 1", len(d.Edits))
+	}
+	move := d.Edits[0]
+	if move.OldIndex != 2 || move.NewIndex != 0 {
+		t.Errorf("move indexes = %d -> %d, want 2 -> 0", move.OldIndex, move.NewIndex)
+	}
+}
+
+func TestDiffTableCells(t *testing.T) {
+	old := &Page{Blocks: []Block{&Table{
+		Headers: []string{"Service", "Owner"},
+		Rows: []Row{
+			{Cells: []Cell{{Text: "Auth"}, {Text: "Alice"}}},
+			{Cells: []Cell{{Text: "API"}, {Text: "Bob"}}},
+		},
+	}}}
+	new := &Page{Blocks: []Block{&Table{
+		Headers: []string{"Service", "Owner"},
+		Rows: []Row{
+			{Cells: []Cell{{Text: "Auth"}, {Text: "Carol"}}},
+			{Cells: []Cell{{Text: "API"}, {Text: "Bob"}}},
+			{Cells: []Cell{{Text: "Web"}, {Text: "Dan"}}},
+		},
+	}}}
+
+	d := Diff(old, new)
+	if len(d.Edits) != 1 || d.Edits[0].Op != EditModify {
+		t.Fatalf("Diff() edits = %+v, want one modify", d.Edits)
+	}
+	edit := d.Edits[0]
+	if edit.Headers != nil {
+		t.Errorf("Headers change = %+v, want nil", edit.Headers)
+	}
+	if len(edit.Rows) != 2 {
+		t.Fatalf("Rows edits = %d, want 2", len(edit.Rows))
+	}
+	if edit.Rows[0].Op != EditModify || len(edit.Rows[0].Cells) != 1 {
+		t.Fatalf("first row edit = %+v, want modify with one cell", edit.Rows[0])
+	}
+	cell := edit.Rows[0].Cells[0]
+	if cell.Column != 1 || cell.Header != "Owner" || cell.Old.Text != "Alice" || cell.New.Text != "Carol" {
+		t.Errorf("cell edit = %+v", cell)
+	}
+	if edit.Rows[1].Op != EditInsert || edit.Rows[1].NewIndex != 2 {
+		t.Errorf("second row edit = %+v, want insert at 2", edit.Rows[1])
+	}
+}
+
+func TestDiffTableHeaders(t *testing.T) {
+	old := &Page{Blocks: []Block{&Table{Headers: []string{"A", "B"}}}}
+	new := &Page{Blocks: []Block{&Table{Headers: []string{"A", "C"}}}}
+
+	d := Diff(old, new)
+	if len(d.Edits) != 1 || d.Edits[0].Headers == nil {
+		t.Fatalf("Diff() edits = %+v, want header change", d.Edits)
+	}
+	if d.Edits[0].Headers.New[1] != "C" {
+		t.Errorf("Headers.New = %v", d.Edits[0].Headers.New)
+	}
+}
+
+func TestDiffListItems(t *testing.T) {
+	old := &Page{Blocks: []Block{&BulletList{Items: []ListItem{{Text: "One"}, {Text: "Two"}}}}}
+	new := &Page{Blocks: []Block{&BulletList{Items: []ListItem{{Text: "One"}, {Text: "Two!"}, {Text: "Three"}}}}}
+
+	d := Diff(old, new)
+	if len(d.Edits) != 1 {
+		t.Fatalf("Diff() returned %d edits, want 1", len(d.Edits))
+	}
+	items := d.Edits[0].Items
+	if len(items) != 2 {
+		t.Fatalf("Items edits = %+v, want 2", items)
+	}
+	if items[0].Op != EditModify || items[0].New.Text != "Two!" {
+		t.Errorf("first item edit = %+v", items[0])
+	}
+	if items[1].Op != EditInsert || items[1].New.Text != "Three" {
+		t.Errorf("second item edit = %+v", items[1])
+	}
+}
+
+func TestDiffNilAndEmptySlices(t *testing.T) {
+	old := &Page{Blocks: []Block{&Table{Headers: nil, Rows: nil}}}
+	new := &Page{Blocks: []Block{&Table{Headers: []string{}, Rows: []Row{}}}}
+
+	if d := Diff(old, new); !d.Empty() {
+		t.Errorf("Diff() of equivalent tables = %+v, want empty", d.Edits)
+	}
+}
+
+func TestDiffMacroParamOrder(t *testing.T) {
+	old := &Page{Blocks: []Block{&Macro{Name: "info", Params: map[string]string{"a": "1", "b": "2", "c": "3"}}}}
+	new := &Page{Blocks: []Block{&Macro{Name: "info", Params: map[string]string{"c": "3", "b": "2", "a": "1"}}}}
+
+	if d := Diff(old, new); !d.Empty() {
+		t.Errorf("Diff() of equal macros = %+v, want empty", d.Edits)
+	}
+}
+
+func TestDiffUnified(t *testing.T) {
+	old := &Page{Blocks: []Block{
+		&Heading{Level: 1, Text: "Title"},
+		&Paragraph{Text: "Old text"},
+	}}
+	new := &Page{Blocks: []Block{
+		&Heading{Level: 1, Text: "Title"},
+		&Paragraph{Text: "New text"},
+		&BulletList{Items: []ListItem{{Text: "Item"}}},
+	}}
+
+	got := Diff(old, new).Unified()
+	for _, want := range []string{
+		"@@ modify paragraph at block 1 (was 1) @@",
+		"- Old text",
+		"+ New text",
+		"@@ insert bullet_list at block 2 @@",
+		"+ - Item",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Unified() = %q, want to contain %q", got, want)
+		}
+	}
+
+	if got := Diff(old, old).Unified(); got != "" {
+		t.Errorf("Unified() of empty diff = %q, want empty", got)
+	}
+}
+
+func TestDiffJSON(t *testing.T) {
+	old := &Page{Blocks: []Block{&Paragraph{Text: "A"}}}
+	new := &Page{Blocks: []Block{&Paragraph{Text: "B"}}}
+
+	data, err := json.Marshal(Diff(old, new))
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	var decoded struct {
+		Edits []struct {
+			Op  string                 `json:"op"`
+			Old map[string]interface{} `json:"old"`
+			New map[string]interface{} `json:"new"`
+		} `json:"edits"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if len(decoded.Edits) != 1 {
+		t.Fatalf("decoded %d edits, want 1", len(decoded.Edits))
+	}
+	edit := decoded.Edits[0]
+	if edit.Op != "modify" || edit.Old["type"] != "paragraph" || edit.New["text"] != "B" {
+		t.Errorf("decoded edit = %+v", edit)
+	}
+}
@@ -0,0 +1,649 @@
+package storage
+
+import (
+	"html"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	atxHeadingPattern    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	thematicBreakPattern = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	listItemPattern      = regexp.MustCompile(`^( {0,3})([-*+]|\d{1,9}[.)])( +|$)(.*)$`)
+	fencePattern         = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})(.*)$")
+	taskMarkerPattern    = regexp.MustCompile(`^\[([ xX])\](?:[ \t]+|$)`)
+	delimiterCellPattern = regexp.MustCompile(`^:?-+:?$`)
+	imageOnlyPattern     = regexp.MustCompile(`^!\[([^\]]*)\]\(\s*<?([^\s>)]*)>?(?:\s+"[^"]*")?\s*\)$`)
+)
+
+// ParseMarkdown converts CommonMark Markdown, including the GFM table,
+// task list and strikethrough extensions, to a Page. It supports ATX and
+// setext headings, paragraphs with emphasis, strong, strikethrough, code
+// spans and links, nested bullet, ordered and task lists, tables, fenced and
+// indented code, blockquotes, images and thematic breaks. Raw HTML is kept as
+// literal text.
+//
+// An image that forms a paragraph of its own becomes an Image block; a
+// relative source is taken to be the file name of a page attachment.
+func ParseMarkdown(src string) *Page {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\t", "    ")
+	return &Page{Blocks: parseMarkdownBlocks(strings.Split(src, "\n"))}
+}
+
+func parseMarkdownBlocks(lines []string) []Block {
+	blocks := []Block{}
+	var para []string
+	flush := func() {
+		if len(para) > 0 {
+			blocks = append(blocks, markdownParagraph(para))
+			para = nil
+		}
+	}
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			flush()
+			i++
+		case leadingSpaces(line) >= 4 && len(para) == 0:
+			var block Block
+			block, i = parseIndentedCode(lines, i)
+			blocks = append(blocks, block)
+		case fencePattern.MatchString(line):
+			flush()
+			var block Block
+			block, i = parseFencedCode(lines, i)
+			blocks = append(blocks, block)
+		case atxHeadingPattern.MatchString(line):
+			flush()
+			m := atxHeadingPattern.FindStringSubmatch(line)
+			blocks = append(blocks, &Heading{Level: len(m[1]), Text: markdownPlainText(m[2])})
+			i++
+		case len(para) > 0 && setextLevel(trimmed) > 0:
+			text := markdownPlainText(strings.Join(para, "\n"))
+			blocks = append(blocks, &Heading{Level: setextLevel(trimmed), Text: text})
+			para = nil
+			i++
+		case thematicBreakPattern.MatchString(line):
+			flush()
+			blocks = append(blocks, &HorizontalRule{})
+			i++
+		case isQuoteLine(line):
+			flush()
+			var block Block
+			block, i = parseMarkdownQuote(lines, i)
+			blocks = append(blocks, block)
+		case listItemPattern.MatchString(line) && (len(para) == 0 || canInterruptParagraph(line)):
+			flush()
+			var block Block
+			block, i = parseMarkdownList(lines, i)
+			blocks = append(blocks, block)
+		case i+1 < len(lines) && strings.Contains(line, "|") && isDelimiterRow(lines[i+1], len(splitTableRow(line))):
+			flush()
+			var block Block
+			block, i = parseMarkdownTable(lines, i)
+			blocks = append(blocks, block)
+		default:
+			para = append(para, trimmed)
+			i++
+		}
+	}
+
+	flush()
+	return blocks
+}
+
+// startsBlock reports whether line begins a block that ends a paragraph
+// continuation.
+func startsBlock(line string) bool {
+	return fencePattern.MatchString(line) ||
+		atxHeadingPattern.MatchString(line) ||
+		thematicBreakPattern.MatchString(line) ||
+		isQuoteLine(line) ||
+		(listItemPattern.MatchString(line) && canInterruptParagraph(line))
+}
+
+func leadingSpaces(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func setextLevel(trimmed string) int {
+	switch {
+	case strings.Trim(trimmed, "=") == "":
+		return 1
+	case strings.Trim(trimmed, "-") == "":
+		return 2
+	}
+	return 0
+}
+
+func markdownParagraph(lines []string) Block {
+	text := strings.Join(lines, "\n")
+	if m := imageOnlyPattern.FindStringSubmatch(text); m != nil {
+		return markdownImage(m[1], m[2])
+	}
+	plain, spans := parseMarkdownInline(text)
+	return &Paragraph{Text: plain, Spans: spans}
+}
+
+func markdownImage(alt, src string) *Image {
+	if strings.Contains(src, "://") || strings.HasPrefix(src, "/") || strings.HasPrefix(src, "data:") {
+		return &Image{URL: src, Alt: alt}
+	}
+	return &Image{Attachment: path.Base(src), Alt: alt}
+}
+
+func parseIndentedCode(lines []string, i int) (Block, int) {
+	var code []string
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			code = append(code, "")
+			continue
+		}
+		if leadingSpaces(line) < 4 {
+			break
+		}
+		code = append(code, line[4:])
+	}
+	for len(code) > 0 && code[len(code)-1] == "" {
+		code = code[:len(code)-1]
+	}
+	return &CodeBlock{Code: strings.Join(code, "\n")}, i
+}
+
+func parseFencedCode(lines []string, i int) (Block, int) {
+	m := fencePattern.FindStringSubmatch(lines[i])
+	indent, fence, info := len(m[1]), m[2], strings.TrimSpace(m[3])
+	language := ""
+	if fields := strings.Fields(info); len(fields) > 0 {
+		language = fields[0]
+	}
+
+	var code []string
+	for i++; i < len(lines); i++ {
+		line := lines[i]
+		if closing := strings.TrimSpace(line); leadingSpaces(line) < 4 &&
+			strings.HasPrefix(closing, fence) && strings.Trim(closing, fence[:1]) == "" {
+			i++
+			break
+		}
+		code = append(code, line[min(indent, leadingSpaces(line)):])
+	}
+	return &CodeBlock{Language: language, Code: strings.Join(code, "\n")}, i
+}
+
+func isQuoteLine(line string) bool {
+	return leadingSpaces(line) < 4 && strings.HasPrefix(strings.TrimLeft(line, " "), ">")
+}
+
+func parseMarkdownQuote(lines []string, i int) (Block, int) {
+	var inner []string
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isQuoteLine(line) {
+			content := strings.TrimPrefix(strings.TrimLeft(line, " "), ">")
+			inner = append(inner, strings.TrimPrefix(content, " "))
+			continue
+		}
+		// Lazy continuation of a quoted paragraph.
+		if strings.TrimSpace(line) != "" && len(inner) > 0 &&
+			strings.TrimSpace(inner[len(inner)-1]) != "" && !startsBlock(line) {
+			inner = append(inner, line)
+			continue
+		}
+		break
+	}
+	return &Blockquote{Blocks: parseMarkdownBlocks(inner)}, i
+}
+
+// listMarker describes a list item's marker: its kind (the bullet character,
+// or the delimiter of an ordered marker) and the column its content starts at.
+type listMarker struct {
+	kind    string
+	ordered bool
+	content int
+}
+
+func parseListMarker(line string) (listMarker, string, bool) {
+	m := listItemPattern.FindStringSubmatch(line)
+	if m == nil || thematicBreakPattern.MatchString(line) {
+		return listMarker{}, "", false
+	}
+	marker := listMarker{kind: m[2], content: len(m[1]) + len(m[2]) + len(m[3])}
+	if last := m[2][len(m[2])-1]; last == '.' || last == ')' {
+		marker.ordered = true
+		marker.kind = string(last)
+	}
+	// Content indented by more than four spaces is indented code; the
+	// item content then starts one space after the marker.
+	if len(m[3]) > 4 {
+		marker.content = len(m[1]) + len(m[2]) + 1
+		return marker, strings.Repeat(" ", len(m[3])-1) + m[4], true
+	}
+	if m[3] == "" {
+		marker.content = len(m[1]) + len(m[2]) + 1
+	}
+	return marker, m[4], true
+}
+
+// canInterruptParagraph reports whether a list item may start without a
+// preceding blank line: it must have content, and ordered lists must start at 1.
+func canInterruptParagraph(line string) bool {
+	m := listItemPattern.FindStringSubmatch(line)
+	if m == nil || strings.TrimSpace(m[4]) == "" {
+		return false
+	}
+	if strings.ContainsAny(m[2][len(m[2])-1:], ".)") {
+		return strings.TrimLeft(m[2][:len(m[2])-1], "0") == "1"
+	}
+	return true
+}
+
+func parseMarkdownList(lines []string, i int) (Block, int) {
+	first, _, _ := parseListMarker(lines[i])
+	var items [][]string
+
+	for i < len(lines) {
+		marker, content, ok := parseListMarker(lines[i])
+		if !ok || marker.kind != first.kind || marker.ordered != first.ordered {
+			break
+		}
+		item := []string{content}
+		blank := false
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			if strings.TrimSpace(line) == "" {
+				item = append(item, "")
+				blank = true
+				continue
+			}
+			if leadingSpaces(line) >= marker.content {
+				item = append(item, line[marker.content:])
+				blank = false
+				continue
+			}
+			if _, _, ok := parseListMarker(line); ok {
+				break
+			}
+			// Lazy continuation of the item's paragraph.
+			if !blank && !startsBlock(line) && strings.TrimSpace(item[len(item)-1]) != "" {
+				item = append(item, strings.TrimSpace(line))
+				continue
+			}
+			break
+		}
+		items = append(items, item)
+		if blank {
+			// A blank line followed by something other than another item
+			// of this list ends the list.
+			if i >= len(lines) {
+				break
+			}
+			if next, _, ok := parseListMarker(lines[i]); !ok || next.kind != first.kind {
+				break
+			}
+		}
+	}
+
+	return markdownListBlock(items, first.ordered), i
+}
+
+func markdownListBlock(items [][]string, ordered bool) Block {
+	tasks := make([]TaskItem, 0, len(items))
+	listItems := make([]ListItem, 0, len(items))
+	for _, lines := range items {
+		text, spans, children := markdownItemContent(lines)
+		listItems = append(listItems, ListItem{Text: text, Spans: spans, Children: children})
+
+		m := taskMarkerPattern.FindStringSubmatch(lines[0])
+		if m == nil {
+			continue
+		}
+		lines = append([]string{lines[0][len(m[0]):]}, lines[1:]...)
+		text, spans, children = markdownItemContent(lines)
+		tasks = append(tasks, TaskItem{Text: text, Spans: spans, Done: m[1] != " ", Children: children})
+	}
+
+	switch {
+	case len(tasks) == len(items):
+		return &TaskList{Items: tasks}
+	case ordered:
+		return &NumberedList{Items: listItems}
+	default:
+		return &BulletList{Items: listItems}
+	}
+}
+
+// markdownItemContent parses a list item's lines: a leading paragraph becomes
+// the item text and any further blocks become its children.
+func markdownItemContent(lines []string) (string, []Span, []Block) {
+	blocks := parseMarkdownBlocks(lines)
+	if len(blocks) == 0 {
+		return "", nil, nil
+	}
+	if p, ok := blocks[0].(*Paragraph); ok {
+		if len(blocks) == 1 {
+			return p.Text, p.Spans, nil
+		}
+		return p.Text, p.Spans, blocks[1:]
+	}
+	return "", nil, blocks
+}
+
+// splitTableRow splits a GFM table row into its trimmed cells.
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+func isDelimiterRow(line string, columns int) bool {
+	if !strings.Contains(line, "-") {
+		return false
+	}
+	cells := splitTableRow(line)
+	if len(cells) != columns {
+		return false
+	}
+	for _, cell := range cells {
+		if !delimiterCellPattern.MatchString(cell) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseMarkdownTable(lines []string, i int) (Block, int) {
+	table := &Table{Headers: []string{}, Rows: []Row{}}
+	for _, cell := range splitTableRow(lines[i]) {
+		table.Headers = append(table.Headers, markdownPlainText(cell))
+	}
+
+	for i += 2; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" || startsBlock(line) {
+			break
+		}
+		cells := splitTableRow(line)
+		row := Row{Cells: make([]Cell, len(table.Headers))}
+		for c := range row.Cells {
+			if c < len(cells) {
+				row.Cells[c] = Cell{Text: markdownPlainText(cells[c])}
+			}
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table, i
+}
+
+// markdownPlainText returns the text of inline Markdown without formatting.
+func markdownPlainText(s string) string {
+	text, _ := parseMarkdownInline(s)
+	return text
+}
+
+// parseMarkdownInline parses inline Markdown into plain text and, if any
+// formatting is present, the spans carrying it.
+func parseMarkdownInline(s string) (string, []Span) {
+	var b inlineBuilder
+	markdownInline(s, Span{}, &b)
+	return b.result()
+}
+
+func markdownInline(s string, mark Span, b *inlineBuilder) {
+	var literal strings.Builder
+	emit := func(text string, m Span) {
+		if literal.Len() > 0 {
+			b.add(html.UnescapeString(literal.String()), mark)
+			literal.Reset()
+		}
+		if text != "" {
+			b.add(text, m)
+			b.marked = true
+		}
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			literal.WriteByte(s[i+1])
+			i += 2
+		case c == '\n':
+			literal.WriteByte(' ')
+			i++
+		case c == '`':
+			run := runLength(s, i, '`')
+			end := findBacktickRun(s, i+run, run)
+			if end < 0 {
+				literal.WriteString(s[i : i+run])
+				i += run
+				continue
+			}
+			code := strings.ReplaceAll(s[i+run:end], "\n", " ")
+			if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+				code = code[1 : len(code)-1]
+			}
+			m := mark
+			m.Code = true
+			emit(code, m)
+			i = end + run
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			label, dest, next, ok := parseMarkdownLink(s, i+1)
+			if !ok {
+				literal.WriteByte(c)
+				i++
+				continue
+			}
+			// Inline images have no IR counterpart; keep them as links.
+			m := mark
+			m.Link = dest
+			emit(markdownPlainText(label), m)
+			i = next
+		case c == '[':
+			label, dest, next, ok := parseMarkdownLink(s, i)
+			if !ok {
+				literal.WriteByte(c)
+				i++
+				continue
+			}
+			emit("", mark)
+			m := mark
+			m.Link = dest
+			b.marked = true
+			markdownInline(label, m, b)
+			i = next
+		case c == '<':
+			end := strings.IndexByte(s[i:], '>')
+			target := ""
+			if end > 0 {
+				target = s[i+1 : i+end]
+			}
+			if target == "" || strings.ContainsAny(target, " <") || !(strings.Contains(target, "://") || strings.Contains(target, "@")) {
+				literal.WriteByte(c)
+				i++
+				continue
+			}
+			m := mark
+			m.Link = target
+			if !strings.Contains(target, "://") && !strings.HasPrefix(target, "mailto:") {
+				m.Link = "mailto:" + target
+			}
+			emit(target, m)
+			i += end + 1
+		case c == '~' && strings.HasPrefix(s[i:], "~~"):
+			end := findCloser(s, i+2, "~~")
+			if end < 0 {
+				literal.WriteString("~~")
+				i += 2
+				continue
+			}
+			emit("", mark)
+			m := mark
+			m.Strike = true
+			b.marked = true
+			markdownInline(s[i+2:end], m, b)
+			i = end + 2
+		case (c == '*' || c == '_') && opensEmphasis(s, i):
+			run := min(runLength(s, i, c), 3)
+			delim := strings.Repeat(string(c), run)
+			end := findCloser(s, i+run, delim)
+			if end < 0 {
+				n := runLength(s, i, c)
+				literal.WriteString(s[i : i+n])
+				i += n
+				continue
+			}
+			emit("", mark)
+			m := mark
+			m.Italic = m.Italic || run != 2
+			m.Bold = m.Bold || run >= 2
+			b.marked = true
+			markdownInline(s[i+run:end], m, b)
+			i = end + run
+		default:
+			literal.WriteByte(c)
+			i++
+		}
+	}
+	emit("", mark)
+}
+
+func isASCIIPunct(c byte) bool {
+	return c < 128 && unicode.IsPunct(rune(c)) || strings.IndexByte("$+<=>^`|~", c) >= 0
+}
+
+func runLength(s string, i int, c byte) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == c {
+		n++
+	}
+	return n
+}
+
+func findBacktickRun(s string, from, n int) int {
+	for i := from; i < len(s); {
+		if s[i] != '`' {
+			i++
+			continue
+		}
+		run := runLength(s, i, '`')
+		if run == n {
+			return i
+		}
+		i += run
+	}
+	return -1
+}
+
+// opensEmphasis reports whether the delimiter run at i can open emphasis:
+// it must be followed by non-space, and "_" must not be inside a word.
+func opensEmphasis(s string, i int) bool {
+	c := s[i]
+	end := i + runLength(s, i, c)
+	if end >= len(s) || s[end] == ' ' || s[end] == '\n' {
+		return false
+	}
+	if c == '_' && i > 0 && isWordByte(s[i-1]) {
+		return false
+	}
+	return true
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// findCloser finds the closing delimiter run equal to delim, skipping code
+// spans, escapes and delimiter runs of other lengths.
+func findCloser(s string, from int, delim string) int {
+	c := delim[0]
+	for i := from; i < len(s); {
+		switch s[i] {
+		case '\\':
+			i += 2
+			continue
+		case '`':
+			run := runLength(s, i, '`')
+			if end := findBacktickRun(s, i+run, run); end >= 0 {
+				i = end + run
+				continue
+			}
+			i += run
+			continue
+		}
+		if s[i] != c {
+			i++
+			continue
+		}
+		run := runLength(s, i, c)
+		closes := i > from && s[i-1] != ' ' && s[i-1] != '\n'
+		if c == '_' && i+run < len(s) && isWordByte(s[i+run]) {
+			closes = false
+		}
+		if run == len(delim) && closes {
+			return i
+		}
+		i += run
+	}
+	return -1
+}
+
+// parseMarkdownLink parses "[label](dest "title")" starting at the "[" at
+// i, returning the label, destination and the index after the link.
+func parseMarkdownLink(s string, i int) (string, string, int, bool) {
+	depth := 0
+	labelEnd := -1
+	for j := i; j < len(s) && labelEnd < 0; j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				labelEnd = j
+			}
+		}
+	}
+	if labelEnd < 0 || labelEnd+1 >= len(s) || s[labelEnd+1] != '(' {
+		return "", "", 0, false
+	}
+
+	end := strings.IndexByte(s[labelEnd+2:], ')')
+	if end < 0 {
+		return "", "", 0, false
+	}
+	target := strings.TrimSpace(s[labelEnd+2 : labelEnd+2+end])
+	if fields := strings.Fields(target); len(fields) > 0 {
+		target = fields[0]
+	}
+	target = strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+	return s[i+1 : labelEnd], target, labelEnd + 3 + end, true
+}
@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"testing"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "headings",
+			markdown: "# Title\n\n## Section ##\n\nSetext\n======\n\nSub\n---",
+			want:     "<h1>Title</h1><h2>Section</h2><h1>Setext</h1><h2>Sub</h2>",
+		},
+		{
+			name:     "paragraph lines are joined",
+			markdown: "first line\nsecond line\n\nnext paragraph",
+			want:     "<p>first line second line</p><p>next paragraph</p>",
+		},
+		{
+			name:     "inline marks",
+			markdown: "Some **bold**, *italic*, ~~gone~~ and `x < y` text.",
+			want:     "<p>Some <strong>bold</strong>, <em>italic</em>, <s>gone</s> and <code>x &lt; y</code> text.</p>",
+		},
+		{
+			name:     "nested emphasis",
+			markdown: "**bold *both* bold** and ***all***",
+			want:     "<p><strong>bold </strong><strong><em>both</em></strong><strong> bold</strong> and <strong><em>all</em></strong></p>",
+		},
+		{
+			name:     "intraword underscores stay literal",
+			markdown: "snake_case_name and _em_",
+			want:     "<p>snake_case_name and <em>em</em></p>",
+		},
+		{
+			name:     "links",
+			markdown: "See [the **docs**](https://example.com/docs \"Docs\") or <https://example.com>.",
+			want:     `<p>See <a href="https://example.com/docs">the </a><a href="https://example.com/docs"><strong>docs</strong></a> or <a href="https://example.com">https://example.com</a>.</p>`,
+		},
+		{
+			name:     "escapes and entities",
+			markdown: `\*not emphasis\* &amp; 1 \< 2`,
+			want:     "<p>*not emphasis* &amp; 1 &lt; 2</p>",
+		},
+		{
+			name:     "bullet list with nesting",
+			markdown: "- one\n- two\n  - two a\n  - two b\n- three",
+			want:     "<ul><li>one</li><li>two<ul><li>two a</li><li>two b</li></ul></li><li>three</li></ul>",
+		},
+		{
+			name:     "numbered list",
+			markdown: "1. first\n2. second\n   1. nested",
+			want:     "<ol><li>first</li><li>second<ol><li>nested</li></ol></li></ol>",
+		},
+		{
+			name:     "different bullet starts a new list",
+			markdown: "- a\n+ b",
+			want:     "<ul><li>a</li></ul><ul><li>b</li></ul>",
+		},
+		{
+			name:     "loose list",
+			markdown: "- a\n\n- b\n\nafter",
+			want:     "<ul><li>a</li><li>b</li></ul><p>after</p>",
+		},
+		{
+			name:     "task list",
+			markdown: "- [ ] todo\n- [x] done",
+			want: "<ac:task-list>" +
+				"<ac:task><ac:task-status>incomplete</ac:task-status><ac:task-body>todo</ac:task-body></ac:task>" +
+				"<ac:task><ac:task-status>complete</ac:task-status><ac:task-body>done</ac:task-body></ac:task>" +
+				"</ac:task-list>",
+		},
+		{
+			name:     "mixed task markers stay a bullet list",
+			markdown: "- [ ] todo\n- plain",
+			want:     "<ul><li>[ ] todo</li><li>plain</li></ul>",
+		},
+		{
+			name:     "table",
+			markdown: "| Name | Role |\n|------|:----:|\n| **Alice** | Lead |\n| Bob |\n| a \\| b | c |",
+			want: "<table><tbody><tr><th>Name</th><th>Role</th></tr>" +
+				"<tr><td>Alice</td><td>Lead</td></tr>" +
+				"<tr><td>Bob</td><td></td></tr>" +
+				"<tr><td>a | b</td><td>c</td></tr></tbody></table>",
+		},
+		{
+			name:     "fenced code",
+			markdown: "```go\nfunc main() {\n\t<-done\n}\n```",
+			want:     `<ac:structured-macro ac:name="code"><ac:parameter ac:name="language">go</ac:parameter><ac:plain-text-body><![CDATA[func main() {` + "\n" + `    <-done` + "\n" + `}]]></ac:plain-text-body></ac:structured-macro>`,
+		},
+		{
+			name:     "unterminated fence runs to end",
+			markdown: "~~~\ncode",
+			want:     `<ac:structured-macro ac:name="code"><ac:plain-text-body><![CDATA[code]]></ac:plain-text-body></ac:structured-macro>`,
+		},
+		{
+			name:     "indented code",
+			markdown: "    line 1\n\n    line 2\n\ntext",
+			want:     `<ac:structured-macro ac:name="code"><ac:plain-text-body><![CDATA[line 1` + "\n\n" + `line 2]]></ac:plain-text-body></ac:structured-macro><p>text</p>`,
+		},
+		{
+			name:     "blockquote",
+			markdown: "> quoted *text*\nlazy line\n>\n> - item",
+			want:     "<blockquote><p>quoted <em>text</em> lazy line</p><ul><li>item</li></ul></blockquote>",
+		},
+		{
+			name:     "images",
+			markdown: "![Logo](https://example.com/logo.png)\n\n![Diagram](images/arch.png \"Architecture\")",
+			want: `<ac:image ac:alt="Logo"><ri:url ri:value="https://example.com/logo.png"/></ac:image>` +
+				`<ac:image ac:alt="Diagram"><ri:attachment ri:filename="arch.png"/></ac:image>`,
+		},
+		{
+			name:     "horizontal rules",
+			markdown: "a\n\n***\n\n- - -",
+			want:     "<p>a</p><hr/><hr/>",
+		},
+		{
+			name:     "raw html is literal",
+			markdown: "<div>x</div>",
+			want:     "<p>&lt;div&gt;x&lt;/div&gt;</p>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := ParseMarkdown(tt.markdown)
+			got, err := Render(page)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMarkdown() rendered\n got = %s\nwant = %s", got, tt.want)
+			}
+			if err := Validate(got); err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestParseMarkdownPlainText(t *testing.T) {
+	page := ParseMarkdown("Plain text only\n\nWith **bold**")
+	if len(page.Blocks) != 2 {
+		t.Fatalf("ParseMarkdown() blocks = %d, want 2", len(page.Blocks))
+	}
+	plain := page.Blocks[0].(*Paragraph)
+	if plain.Text != "Plain text only" || plain.Spans != nil {
+		t.Errorf("plain paragraph = %+v, want text without spans", plain)
+	}
+	marked := page.Blocks[1].(*Paragraph)
+	if marked.Text != "With bold" || len(marked.Spans) != 2 {
+		t.Errorf("marked paragraph = %+v, want text \"With bold\" with 2 spans", marked)
+	}
+}
+
+func TestParseMarkdownRoundTrip(t *testing.T) {
+	markdown := "# Notes\n\nSome *formatted* [text](https://example.com).\n\n- a\n  - b\n\n- [x] shipped\n\n> quote\n\n![x](diagram.png)"
+	xhtml, err := Render(ParseMarkdown(markdown))
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	page, err := Parse(xhtml)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	again, err := Render(page)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if again != xhtml {
+		t.Errorf("round trip mismatch\n got = %s\nwant = %s", again, xhtml)
+	}
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"encoding/json"
+)
+
+// Conflict is a region that both sides changed differently since the base.
+// BaseStart and BaseEnd delimit the affected base blocks ([start, end)).
+type Conflict struct {
+	BaseStart int     `json:"base_start"`
+	BaseEnd   int     `json:"base_end"`
+	Base      []Block `json:"-"`
+	Theirs    []Block `json:"-"`
+	Ours      []Block `json:"-"`
+}
+
+// MarshalJSON includes each side's blocks tagged with their type.
+func (c Conflict) MarshalJSON() ([]byte, error) {
+	type plain Conflict
+	out := struct {
+		plain
+		Base   []json.RawMessage `json:"base"`
+		Theirs []json.RawMessage `json:"theirs"`
+		Ours   []json.RawMessage `json:"ours"`
+	}{plain: plain(c)}
+
+	var err error
+	if out.Base, err = marshalBlocks(c.Base); err != nil {
+		return nil, err
+	}
+	if out.Theirs, err = marshalBlocks(c.Theirs); err != nil {
+		return nil, err
+	}
+	if out.Ours, err = marshalBlocks(c.Ours); err != nil {
+		return nil, err
+	}
+	return json.Marshal(out)
+}
+
+func marshalBlocks(blocks []Block) ([]json.RawMessage, error) {
+	result := make([]json.RawMessage, len(blocks))
+	for i, b := range blocks {
+		data, err := marshalBlock(b)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = data
+	}
+	return result, nil
+}
+
+// MergeResult is the outcome of a three-way merge.
+type MergeResult struct {
+	// Page is the merged content. Conflicting regions contain our version,
+	// so Page should only be published when Conflicts is empty.
+	Page      *Page
+	Conflicts []Conflict
+}
+
+// Merge performs a block-level three-way merge. base is the common ancestor,
+// theirs is the content that was published since (e.g. a human edit), and
+// ours is the content we want to publish. Regions changed on only one side
+// take that side's blocks; regions changed identically on both sides are
+// taken once; regions changed differently on both sides are conflicts.
+func Merge(base, theirs, ours *Page) *MergeResult {
+	baseBlocks, theirBlocks, ourBlocks := pageBlocks(base), pageBlocks(theirs), pageBlocks(ours)
+	baseKeys, theirKeys, ourKeys := fingerprints(baseBlocks), fingerprints(theirBlocks), fingerprints(ourBlocks)
+
+	theirMatch := matches(align(baseKeys, theirKeys), len(baseKeys))
+	ourMatch := matches(align(baseKeys, ourKeys), len(baseKeys))
+
+	result := &MergeResult{Page: &Page{Blocks: []Block{}}}
+	i, j, k := 0, 0, 0
+	for b := 0; b <= len(baseBlocks); b++ {
+		// A stable block is unchanged on both sides; the end of the page
+		// acts as a final stable point.
+		stable := b == len(baseBlocks)
+		if !stable && (theirMatch[b] < 0 || ourMatch[b] < 0) {
+			continue
+		}
+
+		theirEnd, ourEnd := len(theirBlocks), len(ourBlocks)
+		if !stable {
+			theirEnd, ourEnd = theirMatch[b], ourMatch[b]
+		}
+		result.mergeChunk(i, b, baseBlocks, baseKeys,
+			theirBlocks[j:theirEnd], theirKeys[j:theirEnd], ourBlocks[k:ourEnd], ourKeys[k:ourEnd])
+
+		if !stable {
+			result.Page.Blocks = append(result.Page.Blocks, ourBlocks[ourEnd])
+		}
+		i, j, k = b+1, theirEnd+1, ourEnd+1
+	}
+	return result
+}
+
+// mergeChunk resolves the unstable region between two stable blocks.
+func (r *MergeResult) mergeChunk(start, end int, baseBlocks []Block, baseKeys []string,
+	theirBlocks []Block, theirKeys []string, ourBlocks []Block, ourKeys []string) {
+	baseChunk := baseKeys[start:end]
+	switch {
+	case equalKeys(theirKeys, baseChunk):
+		r.Page.Blocks = append(r.Page.Blocks, ourBlocks...)
+	case equalKeys(ourKeys, baseChunk), equalKeys(ourKeys, theirKeys):
+		r.Page.Blocks = append(r.Page.Blocks, theirBlocks...)
+	default:
+		r.Conflicts = append(r.Conflicts, Conflict{
+			BaseStart: start,
+			BaseEnd:   end,
+			Base:      baseBlocks[start:end],
+			Theirs:    theirBlocks,
+			Ours:      ourBlocks,
+		})
+		r.Page.Blocks = append(r.Page.Blocks, ourBlocks...)
+	}
+}
+
+func fingerprints(blocks []Block) []string {
+	keys := make([]string, len(blocks))
+	for i, b := range blocks {
+		keys[i] = fingerprint(b)
+	}
+	return keys
+}
+
+// matches maps each index of the first sequence to its matched index in the
+// second, or -1 when it has no match.
+func matches(steps []pair, n int) []int {
+	result := make([]int, n)
+	for i := range result {
+		result[i] = -1
+	}
+	for _, s := range steps {
+		if s.old >= 0 && s.new >= 0 {
+			result[s.old] = s.new
+		}
+	}
+	return result
+}
+
+func equalKeys(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func mergeTexts(p *Page) []string {
+	texts := make([]string, len(p.Blocks))
+	for i, b := range p.Blocks {
+		texts[i] = describeBlock(b)[0]
+	}
+	return texts
+}
+
+func paragraphs(texts ...string) *Page {
+	page := &Page{}
+	for _, text := range texts {
+		page.Blocks = append(page.Blocks, &Paragraph{Text: text})
+	}
+	return page
+}
+
+func TestMerge(t *testing.T) {
+	base := paragraphs("A", "B", "C", "D")
+
+	tests := []struct {
+		name          string
+		theirs        *Page
+		ours          *Page
+		want          []string
+		wantConflicts int
+	}{
+		{
+			name:   "no changes",
+			theirs: base,
+			ours:   base,
+			want:   []string{"A", "B", "C", "D"},
+		},
+		{
+			name:   "only ours changed",
+			theirs: base,
+			ours:   paragraphs("A", "B2", "C", "D"),
+			want:   []string{"A", "B2", "C", "D"},
+		},
+		{
+			name:   "only theirs changed",
+			theirs: paragraphs("A", "B", "C", "D2"),
+			ours:   base,
+			want:   []string{"A", "B", "C", "D2"},
+		},
+		{
+			name:   "disjoint changes",
+			theirs: paragraphs("A2", "B", "C", "D"),
+			ours:   paragraphs("A", "B", "C", "D", "E"),
+			want:   []string{"A2", "B", "C", "D", "E"},
+		},
+		{
+			name:   "theirs deleted, ours inserted elsewhere",
+			theirs: paragraphs("A", "C", "D"),
+			ours:   paragraphs("A", "B", "C", "X", "D"),
+			want:   []string{"A", "C", "X", "D"},
+		},
+		{
+			name:   "identical change on both sides",
+			theirs: paragraphs("A", "B2", "C", "D"),
+			ours:   paragraphs("A", "B2", "C", "D"),
+			want:   []string{"A", "B2", "C", "D"},
+		},
+		{
+			name:          "conflicting change",
+			theirs:        paragraphs("A", "B-theirs", "C", "D"),
+			ours:          paragraphs("A", "B-ours", "C", "D"),
+			want:          []string{"A", "B-ours", "C", "D"},
+			wantConflicts: 1,
+		},
+		{
+			name:          "conflicting insert at end",
+			theirs:        paragraphs("A", "B", "C", "D", "T"),
+			ours:          paragraphs("A", "B", "C", "D", "O"),
+			want:          []string{"A", "B", "C", "D", "O"},
+			wantConflicts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Merge(base, tt.theirs, tt.ours)
+			if len(result.Conflicts) != tt.wantConflicts {
+				t.Fatalf("Merge() conflicts = %d, want %d: %+v", len(result.Conflicts), tt.wantConflicts, result.Conflicts)
+			}
+			got := mergeTexts(result.Page)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Merge() blocks = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Merge() blocks = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestMergeConflictDetails(t *testing.T) {
+	base := paragraphs("A", "B", "C")
+	theirs := paragraphs("A", "B-theirs", "C")
+	ours := paragraphs("A", "B-ours", "C")
+
+	result := Merge(base, theirs, ours)
+	if len(result.Conflicts) != 1 {
+		t.Fatalf("Merge() conflicts = %d, want 1", len(result.Conflicts))
+	}
+	c := result.Conflicts[0]
+	if c.BaseStart != 1 || c.BaseEnd != 2 {
+		t.Errorf("conflict base range = [%d, %d), want [1, 2)", c.BaseStart, c.BaseEnd)
+	}
+	if len(c.Theirs) != 1 || c.Theirs[0].(*Paragraph).Text != "B-theirs" {
+		t.Errorf("conflict theirs = %v", c.Theirs)
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded struct {
+		BaseStart int                      `json:"base_start"`
+		Ours      []map[string]interface{} `json:"ours"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if decoded.BaseStart != 1 || len(decoded.Ours) != 1 || decoded.Ours[0]["type"] != "paragraph" {
+		t.Errorf("decoded conflict = %+v", decoded)
+	}
+}
+
+func TestMergeEmptyBase(t *testing.T) {
+	result := Merge(nil, paragraphs("T"), paragraphs("T"))
+	if len(result.Conflicts) != 0 || len(result.Page.Blocks) != 1 {
+		t.Errorf("Merge() = %+v, want single block without conflicts", result)
+	}
+}
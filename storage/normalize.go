@@ -0,0 +1,353 @@
+package storage
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// VolatileAttributes are attributes Confluence regenerates on every save.
+// Normalize drops them so that otherwise identical content compares equal.
+var VolatileAttributes = map[string]bool{
+	"ac:local-id":   true,
+	"ac:macro-id":   true,
+	"local-id":      true,
+	"data-local-id": true,
+}
+
+// blockElements are elements whose surrounding whitespace is insignificant.
+var blockElements = map[string]bool{
+	"root": true, "p": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"ul": true, "ol": true, "li": true, "table": true, "tbody": true, "tr": true, "th": true, "td": true,
+	"blockquote": true, "pre": true, "hr": true,
+	"ac:structured-macro": true, "ac:parameter": true, "ac:rich-text-body": true, "ac:plain-text-body": true,
+	"ac:task-list": true, "ac:task": true, "ac:task-id": true, "ac:task-status": true, "ac:task-body": true,
+	"ac:layout": true, "ac:layout-section": true, "ac:layout-cell": true, "ac:image": true,
+}
+
+// preformattedElements keep their text content verbatim.
+var preformattedElements = map[string]bool{
+	"pre":                true,
+	"ac:plain-text-body": true,
+}
+
+// xnode is a minimal XML tree used for normalization and pretty-printing.
+type xnode struct {
+	name     string // qualified name (e.g. "ac:structured-macro"); empty for text nodes
+	attrs    []xml.Attr
+	children []*xnode
+	text     string
+}
+
+func (n *xnode) isText() bool { return n.name == "" }
+
+// Normalize returns a canonical form of Storage XHTML so that content
+// produced by Render can be compared with what Confluence returns.
+// Attributes are sorted and volatile IDs removed, insignificant whitespace
+// is collapsed, entities are decoded to characters, empty elements are
+// self-closed, and the <p> wrapper Confluence adds inside table cells and
+// list items is removed.
+func Normalize(xhtml string) (string, error) {
+	root, err := parseTree(xhtml)
+	if err != nil {
+		return "", err
+	}
+	var buf strings.Builder
+	for _, child := range root.children {
+		writeNode(&buf, child)
+	}
+	return buf.String(), nil
+}
+
+// Pretty returns the normalized form of Storage XHTML with block elements
+// indented on their own lines, for human review. Runs of text and inline
+// elements stay together on one line, so the indentation is not
+// significant: normalizing Pretty output yields the same result as
+// normalizing the input.
+func Pretty(xhtml string) (string, error) {
+	root, err := parseTree(xhtml)
+	if err != nil {
+		return "", err
+	}
+	var buf strings.Builder
+	writePrettyChildren(&buf, root.children, 0)
+	return buf.String(), nil
+}
+
+// parseTree reads Storage XHTML into a normalized tree. RawToken is used so
+// that namespace prefixes (ac:, ri:) are kept as written.
+func parseTree(xhtml string) (*xnode, error) {
+	decoder := xml.NewDecoder(strings.NewReader("<root>" + xhtml + "</root>"))
+	decoder.Entity = htmlEntities
+
+	var stack []*xnode
+	var root *xnode
+
+	for {
+		tok, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse error: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			node := &xnode{name: qualifiedName(t.Name)}
+			for _, attr := range t.Attr {
+				name := qualifiedName(attr.Name)
+				if VolatileAttributes[name] {
+					continue
+				}
+				node.attrs = append(node.attrs, xml.Attr{Name: xml.Name{Local: name}, Value: attr.Value})
+			}
+			sort.Slice(node.attrs, func(i, j int) bool {
+				return node.attrs[i].Name.Local < node.attrs[j].Name.Local
+			})
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			} else {
+				root = node
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("parse error: unexpected end element %s", qualifiedName(t.Name))
+			}
+			node := stack[len(stack)-1]
+			if node.name != qualifiedName(t.Name) {
+				return nil, fmt.Errorf("parse error: element <%s> closed by </%s>", node.name, qualifiedName(t.Name))
+			}
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) == 0 {
+				continue
+			}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, &xnode{text: string(t)})
+		}
+	}
+
+	if root == nil || len(stack) > 0 {
+		return nil, fmt.Errorf("parse error: unexpected end of input")
+	}
+
+	normalizeNode(root, false)
+	return root, nil
+}
+
+func qualifiedName(name xml.Name) string {
+	if name.Space != "" {
+		return name.Space + ":" + name.Local
+	}
+	return name.Local
+}
+
+// normalizeNode collapses whitespace, merges adjacent text and unwraps
+// single-paragraph cells and list items, recursively.
+func normalizeNode(n *xnode, preformatted bool) {
+	preformatted = preformatted || preformattedElements[n.name]
+
+	// Merge adjacent text nodes (entities and CDATA sections split them).
+	var merged []*xnode
+	for _, child := range n.children {
+		if child.isText() && len(merged) > 0 && merged[len(merged)-1].isText() {
+			merged[len(merged)-1].text += child.text
+			continue
+		}
+		merged = append(merged, child)
+	}
+	n.children = merged
+
+	if !preformatted {
+		for _, child := range n.children {
+			if child.isText() {
+				child.text = collapseSpace(child.text)
+			}
+		}
+		if blockElements[n.name] {
+			n.children = trimBlockWhitespace(n.children)
+		}
+	}
+
+	for _, child := range n.children {
+		if !child.isText() {
+			normalizeNode(child, preformatted)
+		}
+	}
+
+	// Confluence wraps cell and list item content in a paragraph, and the
+	// text of a list item before its nested lists.
+	if (n.name == "td" || n.name == "th" || n.name == "li") && len(n.children) > 0 {
+		first := n.children[0]
+		if first.name == "p" && len(first.attrs) == 0 && onlyNestedLists(n.children[1:]) {
+			n.children = append(first.children, n.children[1:]...)
+		}
+	}
+}
+
+// onlyNestedLists reports whether nodes are all lists.
+func onlyNestedLists(nodes []*xnode) bool {
+	for _, n := range nodes {
+		if n.name != "ul" && n.name != "ol" && n.name != "ac:task-list" {
+			return false
+		}
+	}
+	return true
+}
+
+// collapseSpace replaces runs of XML whitespace with a single space.
+// Non-breaking spaces are significant and left alone.
+func collapseSpace(s string) string {
+	var buf strings.Builder
+	inSpace := false
+	for _, r := range s {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' {
+			if !inSpace {
+				buf.WriteByte(' ')
+			}
+			inSpace = true
+			continue
+		}
+		inSpace = false
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+// trimBlockWhitespace trims text next to block boundaries and drops text
+// nodes that become empty.
+func trimBlockWhitespace(children []*xnode) []*xnode {
+	var result []*xnode
+	for i, child := range children {
+		if child.isText() {
+			if i == 0 || blockElements[children[i-1].name] {
+				child.text = strings.TrimLeft(child.text, " ")
+			}
+			if i == len(children)-1 || blockElements[children[i+1].name] {
+				child.text = strings.TrimRight(child.text, " ")
+			}
+			if child.text == "" {
+				continue
+			}
+		}
+		result = append(result, child)
+	}
+	return result
+}
+
+func writeNode(buf *strings.Builder, n *xnode) {
+	if n.isText() {
+		buf.WriteString(escapeText(n.text))
+		return
+	}
+	writeStartTag(buf, n)
+	if len(n.children) == 0 {
+		return
+	}
+	if preformattedElements[n.name] {
+		writePreformatted(buf, n)
+	} else {
+		for _, child := range n.children {
+			writeNode(buf, child)
+		}
+	}
+	buf.WriteString("</" + n.name + ">")
+}
+
+// writeStartTag writes the opening tag, self-closing it when n is empty.
+func writeStartTag(buf *strings.Builder, n *xnode) {
+	buf.WriteString("<" + n.name)
+	for _, attr := range n.attrs {
+		buf.WriteString(" " + attr.Name.Local + `="` + escapeAttr(attr.Value) + `"`)
+	}
+	if len(n.children) == 0 {
+		buf.WriteString("/>")
+		return
+	}
+	buf.WriteString(">")
+}
+
+// writePreformatted writes plain-text bodies as CDATA and other
+// preformatted content escaped, both verbatim.
+func writePreformatted(buf *strings.Builder, n *xnode) {
+	if n.name != "ac:plain-text-body" {
+		for _, child := range n.children {
+			writeNode(buf, child)
+		}
+		return
+	}
+	var text strings.Builder
+	for _, child := range n.children {
+		text.WriteString(child.text)
+	}
+	buf.WriteString("<![CDATA[")
+	buf.WriteString(strings.ReplaceAll(text.String(), "]]>", "]]]]><![CDATA[>"))
+	buf.WriteString("]]>")
+}
+
+// writePrettyChildren writes each block element of nodes with
+// writePretty and each run of text and inline elements on a single line,
+// since whitespace between inline content is significant.
+func writePrettyChildren(buf *strings.Builder, nodes []*xnode, depth int) {
+	indent := strings.Repeat("  ", depth)
+	inRun := false
+	for _, n := range nodes {
+		if !n.isText() && blockElements[n.name] {
+			if inRun {
+				buf.WriteString("\n")
+				inRun = false
+			}
+			writePretty(buf, n, depth)
+			continue
+		}
+		if !inRun {
+			buf.WriteString(indent)
+			inRun = true
+		}
+		writeNode(buf, n)
+	}
+	if inRun {
+		buf.WriteString("\n")
+	}
+}
+
+func writePretty(buf *strings.Builder, n *xnode, depth int) {
+	indent := strings.Repeat("  ", depth)
+	if !hasBlockChild(n) {
+		buf.WriteString(indent)
+		writeNode(buf, n)
+		buf.WriteString("\n")
+		return
+	}
+	buf.WriteString(indent)
+	writeStartTag(buf, n)
+	buf.WriteString("\n")
+	writePrettyChildren(buf, n.children, depth+1)
+	buf.WriteString(indent + "</" + n.name + ">\n")
+}
+
+func hasBlockChild(n *xnode) bool {
+	if preformattedElements[n.name] {
+		return false
+	}
+	for _, child := range n.children {
+		if blockElements[child.name] {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+)
+
+func escapeText(s string) string { return textEscaper.Replace(s) }
+
+func escapeAttr(s string) string { return attrEscaper.Replace(s) }
@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "sorts attributes",
+			input: `<a title="t" href="https://example.com">x</a>`,
+			want:  `<a href="https://example.com" title="t">x</a>`,
+		},
+		{
+			name:  "drops volatile attributes",
+			input: `<table ac:local-id="abc" data-layout="default"><tbody></tbody></table>`,
+			want:  `<table data-layout="default"><tbody/></table>`,
+		},
+		{
+			name:  "collapses whitespace",
+			input: "<p>\n  Hello   <strong>big</strong>\n  world\n</p>",
+			want:  `<p>Hello <strong>big</strong> world</p>`,
+		},
+		{
+			name:  "drops whitespace between blocks",
+			input: "<h1>Title</h1>\n\n<p>Text</p>\n",
+			want:  `<h1>Title</h1><p>Text</p>`,
+		},
+		{
+			name:  "decodes entities",
+			input: `<p>a &amp; b&nbsp;&mdash;&#160;c</p>`,
+			want:  "<p>a &amp; b — c</p>",
+		},
+		{
+			name:  "self-closes empty elements",
+			input: `<p></p><hr></hr><br/>`,
+			want:  `<p/><hr/><br/>`,
+		},
+		{
+			name:  "unwraps paragraph in table cell",
+			input: `<table><tbody><tr><td><p>Value</p></td></tr></tbody></table>`,
+			want:  `<table><tbody><tr><td>Value</td></tr></tbody></table>`,
+		},
+		{
+			name:  "keeps multiple paragraphs in cell",
+			input: `<table><tbody><tr><td><p>A</p><p>B</p></td></tr></tbody></table>`,
+			want:  `<table><tbody><tr><td><p>A</p><p>B</p></td></tr></tbody></table>`,
+		},
+		{
+			name:  "unwraps list item paragraph before nested list",
+			input: `<ul><li><p>One</p><ul><li><p>Two</p></li></ul></li></ul>`,
+			want:  `<ul><li>One<ul><li>Two</li></ul></li></ul>`,
+		},
+		{
+			name:  "preserves code whitespace as CDATA",
+			input: "<ac:structured-macro ac:name=\"code\"><ac:plain-text-body><![CDATA[a  <b>\n  c]]></ac:plain-text-body></ac:structured-macro>",
+			want:  "<ac:structured-macro ac:name=\"code\"><ac:plain-text-body><![CDATA[a  <b>\n  c]]></ac:plain-text-body></ac:structured-macro>",
+		},
+		{
+			name:  "empty input",
+			input: "",
+			want:  "",
+		},
+		{
+			name:    "malformed XML",
+			input:   `<p>unclosed`,
+			wantErr: true,
+		},
+		{
+			name:    "mismatched tags",
+			input:   `<p><strong>x</p></strong>`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Normalize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeMatchesRenderedOutput(t *testing.T) {
+	page := &Page{
+		Blocks: []Block{
+			&Heading{Level: 2, Text: "Owners"},
+			&Table{
+				Headers: []string{"Name"},
+				Rows:    []Row{{Cells: []Cell{{Text: "Alice"}}}},
+			},
+		},
+	}
+
+	rendered, err := Render(page)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	// What Confluence typically returns for the same content.
+	returned := "<h2>Owners</h2>\n<table ac:local-id=\"1f2e\">" +
+		"<tbody><tr><th><p>Name</p></th></tr><tr><td><p>Alice</p></td></tr></tbody></table>"
+
+	want, err := Normalize(rendered)
+	if err != nil {
+		t.Fatalf("Normalize(rendered) error = %v", err)
+	}
+	got, err := Normalize(returned)
+	if err != nil {
+		t.Fatalf("Normalize(returned) error = %v", err)
+	}
+	if got != want {
+		t.Errorf("Normalize(returned) = %q, want %q", got, want)
+	}
+}
+
+func TestPretty(t *testing.T) {
+	input := `<h1>Title</h1><ul><li>One<ul><li>Two</li></ul></li></ul>` +
+		`<table><tbody><tr><td><p>A <strong>b</strong></p></td></tr></tbody></table>`
+
+	want := "<h1>Title</h1>\n" +
+		"<ul>\n" +
+		"  <li>\n" +
+		"    One\n" +
+		"    <ul>\n" +
+		"      <li>Two</li>\n" +
+		"    </ul>\n" +
+		"  </li>\n" +
+		"</ul>\n" +
+		"<table>\n" +
+		"  <tbody>\n" +
+		"    <tr>\n" +
+		"      <td>A <strong>b</strong></td>\n" +
+		"    </tr>\n" +
+		"  </tbody>\n" +
+		"</table>\n"
+
+	got, err := Pretty(input)
+	if err != nil {
+		t.Fatalf("Pretty() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("Pretty() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPrettyNormalizesBack(t *testing.T) {
+	inputs := []string{
+		`<h1>Title</h1><p>Some <em>text</em> here.</p>`,
+		`<ul><li>One<ul><li>Two</li></ul></li></ul>`,
+		`<ac:structured-macro ac:name="info"><ac:parameter ac:name="title">Note</ac:parameter>` +
+			`<ac:rich-text-body><p>Body</p></ac:rich-text-body></ac:structured-macro>`,
+		// Inline runs next to block children must not gain whitespace.
+		`<ul><li><p>a<strong>b</strong></p><ul><li>x</li></ul></li></ul>`,
+		`<table><tbody><tr><td><strong>a</strong>b<p>x</p></td></tr></tbody></table>`,
+		`<ac:rich-text-body>x<em>y</em><p>z</p><code>c</code>d</ac:rich-text-body>`,
+	}
+
+	for _, input := range inputs {
+		pretty, err := Pretty(input)
+		if err != nil {
+			t.Fatalf("Pretty() error = %v", err)
+		}
+		fromPretty, err := Normalize(pretty)
+		if err != nil {
+			t.Fatalf("Normalize(pretty) error = %v", err)
+		}
+		direct, err := Normalize(input)
+		if err != nil {
+			t.Fatalf("Normalize() error = %v", err)
+		}
+		if fromPretty != direct {
+			t.Errorf("Normalize(Pretty(x)) = %q, want %q", fromPretty, direct)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package storage
+
+import "unicode/utf8"
+
+// PageOutline summarizes the shape of a page: its heading hierarchy with
+// the size and content of each section.
+type PageOutline struct {
+	Blocks   int             `json:"blocks"`
+	Chars    int             `json:"chars"`
+	Tables   int             `json:"tables"`
+	Macros   map[string]int  `json:"macros,omitempty"`
+	Sections []*OutlineEntry `json:"sections"`
+}
+
+// OutlineEntry describes one section of a PageOutline. Counts and sizes
+// include subsections.
+type OutlineEntry struct {
+	Level    int             `json:"level"`
+	Text     string          `json:"text"`
+	Path     string          `json:"path"`  // path accepted by FindSection
+	Start    int             `json:"start"` // index of the heading block
+	End      int             `json:"end"`   // index after the last block
+	Chars    int             `json:"chars"` // approximate plain-text size
+	Tables   int             `json:"tables"`
+	Macros   map[string]int  `json:"macros,omitempty"` // count per macro name
+	Children []*OutlineEntry `json:"children,omitempty"`
+}
+
+// Outline returns the heading outline of page. Sizes are measured in
+// characters of the RenderText output, which approximates what reading
+// the section costs.
+func Outline(page *Page) *PageOutline {
+	if page == nil {
+		page = &Page{}
+	}
+	stats := make([]blockStats, len(page.Blocks))
+	for i, block := range page.Blocks {
+		stats[i] = statsOf(block)
+	}
+
+	root := Sections(page)
+	total := sumStats(stats, 0, len(stats))
+	return &PageOutline{
+		Blocks:   len(page.Blocks),
+		Chars:    total.chars,
+		Tables:   total.tables,
+		Macros:   total.macros,
+		Sections: outlineEntries(root.Children, stats),
+	}
+}
+
+func outlineEntries(sections []*Section, stats []blockStats) []*OutlineEntry {
+	entries := make([]*OutlineEntry, 0, len(sections))
+	for _, s := range sections {
+		sum := sumStats(stats, s.Start, s.End)
+		entries = append(entries, &OutlineEntry{
+			Level:    s.Heading.Level,
+			Text:     s.Heading.Text,
+			Path:     s.Path,
+			Start:    s.Start,
+			End:      s.End,
+			Chars:    sum.chars,
+			Tables:   sum.tables,
+			Macros:   sum.macros,
+			Children: outlineEntries(s.Children, stats),
+		})
+	}
+	return entries
+}
+
+type blockStats struct {
+	chars  int
+	tables int
+	macros map[string]int
+}
+
+// statsOf measures a block, counting tables and macros nested in lists,
+// blockquotes and table cells.
+func statsOf(block Block) blockStats {
+	stats := blockStats{chars: utf8.RuneCountInString(RenderBlockText(block)) + 2}
+	walkBlock("", block, func(_ string, b Block) bool {
+		switch b := pointerBlock(b).(type) {
+		case *Table:
+			stats.tables++
+		case *Macro:
+			if stats.macros == nil {
+				stats.macros = make(map[string]int)
+			}
+			stats.macros[b.Name]++
+		}
+		return true
+	})
+	return stats
+}
+
+func sumStats(stats []blockStats, start, end int) blockStats {
+	var sum blockStats
+	for _, s := range stats[start:end] {
+		sum.chars += s.chars
+		sum.tables += s.tables
+		for name, n := range s.macros {
+			if sum.macros == nil {
+				sum.macros = make(map[string]int)
+			}
+			sum.macros[name] += n
+		}
+	}
+	return sum
+}
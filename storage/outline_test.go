@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOutline(t *testing.T) {
+	status := &Macro{Name: "status", Params: map[string]string{"title": "OK"}}
+	page := &Page{Blocks: []Block{
+		&Paragraph{Text: "Intro"},
+		&Heading{Level: 1, Text: "Design"},
+		&Table{Headers: []string{"A"}, Rows: []Row{{Cells: []Cell{{Macro: status}}}}},
+		&Heading{Level: 2, Text: "Risks"},
+		&BulletList{Items: []ListItem{{Text: "x", Children: []Block{&Macro{Name: "jira"}}}}},
+		&Heading{Level: 1, Text: "Rollout"},
+		&Macro{Name: "info", Body: "<p>Soon</p>"},
+	}}
+
+	got := Outline(page)
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `{"blocks":7,"chars":50,"tables":1,"macros":{"info":1,"jira":1,"status":1},"sections":[` +
+		`{"level":1,"text":"Design","path":"Design","start":1,"end":5,"chars":28,"tables":1,"macros":{"jira":1,"status":1},"children":[` +
+		`{"level":2,"text":"Risks","path":"Design/Risks","start":3,"end":5,"chars":12,"tables":0,"macros":{"jira":1}}]},` +
+		`{"level":1,"text":"Rollout","path":"Rollout","start":5,"end":7,"chars":15,"tables":0,"macros":{"info":1}}]}`
+	if string(data) != want {
+		t.Errorf("Outline() =\n%s\nwant\n%s", data, want)
+	}
+
+	empty := Outline(nil)
+	if empty.Blocks != 0 || len(empty.Sections) != 0 {
+		t.Errorf("Outline(nil) = %+v", empty)
+	}
+}
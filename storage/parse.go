@@ -2,7 +2,6 @@ package storage
 
 import (
 	"encoding/xml"
-	"fmt"
 	"io"
 	"strings"
 )
@@ -13,30 +12,18 @@ func Parse(xhtml string) (*Page, error) {
 		return &Page{}, nil
 	}
 
-	wrapped := "<root xmlns:ac=\"http://atlassian.com/confluence\" xmlns:ri=\"http://atlassian.com/confluence\">" + xhtml + "</root>"
-	decoder := xml.NewDecoder(strings.NewReader(wrapped))
-	decoder.Entity = htmlEntities
-
+	decoder := NewDecoder(strings.NewReader(xhtml))
 	page := &Page{}
 
 	for {
-		tok, err := decoder.Token()
+		block, err := decoder.Next()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return nil, fmt.Errorf("parse error: %w", err)
-		}
-
-		if se, ok := tok.(xml.StartElement); ok {
-			block, err := parseElement(decoder, se)
-			if err != nil {
-				return nil, err
-			}
-			if block != nil {
-				page.Blocks = append(page.Blocks, block)
-			}
+			return nil, err
 		}
+		page.Blocks = append(page.Blocks, block)
 	}
 
 	return page, nil
@@ -61,6 +48,12 @@ func parseElement(decoder *xml.Decoder, start xml.StartElement) (Block, error) {
 		return &HorizontalRule{}, nil
 	case "structured-macro":
 		return parseMacro(decoder, start)
+	case "task-list":
+		return parseTaskList(decoder)
+	case "blockquote":
+		return parseBlockquote(decoder)
+	case "image":
+		return parseImage(decoder, start)
 	default:
 		// Skip unknown elements
 		if err := skipElement(decoder); err != nil {
@@ -247,8 +240,11 @@ func parseTableCell(decoder *xml.Decoder, tagName string) (*Cell, error) {
 	return cell, nil
 }
 
-func parseParagraph(decoder *xml.Decoder, _ xml.StartElement) (*Paragraph, error) {
-	var content strings.Builder
+// parseParagraph parses a <p>. A paragraph holding nothing but an image is
+// returned as an Image block.
+func parseParagraph(decoder *xml.Decoder, _ xml.StartElement) (Block, error) {
+	var inline inlineBuilder
+	var image *Image
 	for {
 		tok, err := decoder.Token()
 		if err == io.EOF {
@@ -260,21 +256,29 @@ func parseParagraph(decoder *xml.Decoder, _ xml.StartElement) (*Paragraph, error
 
 		switch t := tok.(type) {
 		case xml.CharData:
-			content.Write(t)
+			inline.add(string(t), Span{})
 		case xml.EndElement:
 			if t.Name.Local == "p" {
-				return &Paragraph{Text: strings.TrimSpace(content.String())}, nil
+				text, spans := inline.result()
+				if image != nil && text == "" {
+					return image, nil
+				}
+				return &Paragraph{Text: text, Spans: spans}, nil
 			}
 		case xml.StartElement:
-			// Recursively extract text from nested elements (strong, code, a, etc.)
-			nested, err := extractNestedText(decoder, t.Name.Local)
-			if err != nil {
+			if t.Name.Local == "image" && image == nil {
+				if image, err = parseImage(decoder, t); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if err := parseInline(decoder, t, Span{}, &inline); err != nil {
 				return nil, err
 			}
-			content.WriteString(nested)
 		}
 	}
-	return &Paragraph{Text: content.String()}, nil
+	text, spans := inline.result()
+	return &Paragraph{Text: text, Spans: spans}, nil
 }
 
 func parseHeading(decoder *xml.Decoder, start xml.StartElement) (*Heading, error) {
@@ -380,7 +384,18 @@ func parseNumberedList(decoder *xml.Decoder, _ xml.StartElement) (*NumberedList,
 }
 
 func parseListItem(decoder *xml.Decoder) (*ListItem, error) {
-	var content strings.Builder
+	text, spans, children, err := parseItemContent(decoder, "li")
+	if err != nil {
+		return nil, err
+	}
+	return &ListItem{Text: text, Spans: spans, Children: children}, nil
+}
+
+// parseItemContent parses the content of a list item or task body up to
+// endTag: inline content becomes text and spans, nested lists become children.
+func parseItemContent(decoder *xml.Decoder, endTag string) (string, []Span, []Block, error) {
+	var inline inlineBuilder
+	var children []Block
 
 	for {
 		tok, err := decoder.Token()
@@ -388,27 +403,209 @@ func parseListItem(decoder *xml.Decoder) (*ListItem, error) {
 			break
 		}
 		if err != nil {
-			return nil, err
+			return "", nil, nil, err
 		}
 
 		switch t := tok.(type) {
 		case xml.CharData:
-			content.Write(t)
+			inline.add(string(t), Span{})
 		case xml.EndElement:
-			if t.Name.Local == "li" {
-				return &ListItem{Text: strings.TrimSpace(content.String())}, nil
+			if t.Name.Local == endTag {
+				text, spans := inline.result()
+				return text, spans, children, nil
 			}
 		case xml.StartElement:
-			// Recursively extract text from nested elements (p, strong, code, etc.)
-			nested, err := extractNestedText(decoder, t.Name.Local)
+			switch t.Name.Local {
+			case "ul", "ol", "task-list":
+				child, err := parseElement(decoder, t)
+				if err != nil {
+					return "", nil, nil, err
+				}
+				children = append(children, child)
+			case "p":
+				// Separate the text of consecutive paragraphs.
+				if !inline.empty() {
+					inline.add(" ", Span{})
+				}
+				if err := parseInline(decoder, t, Span{}, &inline); err != nil {
+					return "", nil, nil, err
+				}
+			default:
+				if err := parseInline(decoder, t, Span{}, &inline); err != nil {
+					return "", nil, nil, err
+				}
+			}
+		}
+	}
+
+	text, spans := inline.result()
+	return text, spans, children, nil
+}
+
+func parseTaskList(decoder *xml.Decoder) (*TaskList, error) {
+	list := &TaskList{Items: []TaskItem{}}
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "task" {
+				item, err := parseTask(decoder)
+				if err != nil {
+					return nil, err
+				}
+				list.Items = append(list.Items, *item)
+			} else {
+				if err := skipElement(decoder); err != nil {
+					return nil, err
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Local == "task-list" {
+				return list, nil
+			}
+		}
+	}
+
+	return list, nil
+}
+
+func parseTask(decoder *xml.Decoder) (*TaskItem, error) {
+	item := &TaskItem{}
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "task-status":
+				status, err := parseTextContent(decoder, "task-status")
+				if err != nil {
+					return nil, err
+				}
+				item.Done = status == "complete"
+			case "task-body":
+				if item.Text, item.Spans, item.Children, err = parseItemContent(decoder, "task-body"); err != nil {
+					return nil, err
+				}
+			default:
+				if err := skipElement(decoder); err != nil {
+					return nil, err
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Local == "task" {
+				return item, nil
+			}
+		}
+	}
+
+	return item, nil
+}
+
+func parseBlockquote(decoder *xml.Decoder) (*Blockquote, error) {
+	quote := &Blockquote{}
+	var loose inlineBuilder
+
+	// Text directly inside the quote is collected into a paragraph.
+	flush := func() {
+		if text, spans := loose.result(); text != "" {
+			quote.Blocks = append(quote.Blocks, &Paragraph{Text: text, Spans: spans})
+		}
+		loose = inlineBuilder{}
+	}
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.CharData:
+			loose.add(string(t), Span{})
+		case xml.StartElement:
+			if _, ok := inlineMarks[t.Name.Local]; ok {
+				if err := parseInline(decoder, t, Span{}, &loose); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			flush()
+			block, err := parseElement(decoder, t)
 			if err != nil {
 				return nil, err
 			}
-			content.WriteString(nested)
+			if block != nil {
+				quote.Blocks = append(quote.Blocks, block)
+			}
+		case xml.EndElement:
+			if t.Name.Local == "blockquote" {
+				flush()
+				return quote, nil
+			}
+		}
+	}
+
+	flush()
+	return quote, nil
+}
+
+func parseImage(decoder *xml.Decoder, start xml.StartElement) (*Image, error) {
+	image := &Image{}
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "alt" {
+			image.Alt = attr.Value
+		}
+	}
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			for _, attr := range t.Attr {
+				switch {
+				case t.Name.Local == "url" && attr.Name.Local == "value":
+					image.URL = attr.Value
+				case t.Name.Local == "attachment" && attr.Name.Local == "filename":
+					image.Attachment = attr.Value
+				}
+			}
+			if err := skipElement(decoder); err != nil {
+				return nil, err
+			}
+		case xml.EndElement:
+			if t.Name.Local == "image" {
+				return image, nil
+			}
 		}
 	}
 
-	return &ListItem{Text: content.String()}, nil
+	return image, nil
 }
 
 func parseMacro(decoder *xml.Decoder, start xml.StartElement) (*Macro, error) {
@@ -447,7 +644,13 @@ func parseMacro(decoder *xml.Decoder, start xml.StartElement) (*Macro, error) {
 				if name != "" {
 					macro.Params[name] = value
 				}
-			case "rich-text-body", "plain-text-body":
+			case "rich-text-body":
+				body, err := parseRawContent(decoder, t.Name.Local)
+				if err != nil {
+					return nil, err
+				}
+				macro.Body = body
+			case "plain-text-body":
 				body, err := parseTextContent(decoder, t.Name.Local)
 				if err != nil {
 					return nil, err
@@ -497,6 +700,71 @@ func parseTextContent(decoder *xml.Decoder, endTag string) (string, error) {
 	return content.String(), nil
 }
 
+// parseRawContent returns the inner Storage XHTML of the element being
+// decoded, up to its endTag, with namespace prefixes restored.
+func parseRawContent(decoder *xml.Decoder, endTag string) (string, error) {
+	var content strings.Builder
+	var pending *xml.StartElement
+	depth := 0
+
+	flush := func(selfClose bool) {
+		if pending == nil {
+			return
+		}
+		content.WriteString("<" + prefixedName(pending.Name))
+		for _, attr := range pending.Attr {
+			content.WriteString(" " + prefixedName(attr.Name) + `="` + escapeAttr(attr.Value) + `"`)
+		}
+		if selfClose {
+			content.WriteString("/>")
+		} else {
+			content.WriteString(">")
+		}
+		pending = nil
+	}
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			flush(false)
+			start := t.Copy()
+			pending = &start
+			depth++
+		case xml.EndElement:
+			if depth == 0 && t.Name.Local == endTag {
+				flush(false)
+				return strings.TrimSpace(content.String()), nil
+			}
+			depth--
+			if pending != nil {
+				flush(true)
+				continue
+			}
+			content.WriteString("</" + prefixedName(t.Name) + ">")
+		case xml.CharData:
+			flush(false)
+			content.WriteString(escapeText(string(t)))
+		}
+	}
+
+	return content.String(), nil
+}
+
+func prefixedName(name xml.Name) string {
+	if prefix, ok := namespacePrefixes[name.Space]; ok {
+		return prefix + ":" + name.Local
+	}
+	return qualifiedName(name)
+}
+
 func skipElement(decoder *xml.Decoder) error {
 	depth := 1
 	for depth > 0 {
@@ -545,3 +813,132 @@ func extractNestedText(decoder *xml.Decoder, _ string) (string, error) {
 
 	return strings.TrimSpace(content.String()), nil
 }
+
+// inlineMarks maps inline formatting elements to the mark they apply.
+var inlineMarks = map[string]func(*Span, xml.StartElement){
+	"strong": func(s *Span, _ xml.StartElement) { s.Bold = true },
+	"b":      func(s *Span, _ xml.StartElement) { s.Bold = true },
+	"em":     func(s *Span, _ xml.StartElement) { s.Italic = true },
+	"i":      func(s *Span, _ xml.StartElement) { s.Italic = true },
+	"code":   func(s *Span, _ xml.StartElement) { s.Code = true },
+	"s":      func(s *Span, _ xml.StartElement) { s.Strike = true },
+	"del":    func(s *Span, _ xml.StartElement) { s.Strike = true },
+	"strike": func(s *Span, _ xml.StartElement) { s.Strike = true },
+	"a": func(s *Span, el xml.StartElement) {
+		for _, attr := range el.Attr {
+			if attr.Name.Local == "href" {
+				s.Link = attr.Value
+			}
+		}
+	},
+}
+
+// parseInline consumes the element started by start, adding its text to b
+// with mark plus any formatting the element and its descendants apply.
+// Elements without a known mark contribute their text unformatted.
+// Links to Confluence pages (ac:link with ri:page) mark their body with the
+// page reference and fall back to the page title when the body is empty.
+func parseInline(decoder *xml.Decoder, start xml.StartElement, mark Span, b *inlineBuilder) error {
+	if apply, ok := inlineMarks[start.Name.Local]; ok {
+		apply(&mark, start)
+		b.marked = true
+	}
+	pageLink := start.Name.Local == "link"
+	before := b.length()
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.CharData:
+			b.add(string(t), mark)
+		case xml.StartElement:
+			if pageLink && t.Name.Local == "page" {
+				for _, attr := range t.Attr {
+					switch attr.Name.Local {
+					case "content-title":
+						mark.Page = attr.Value
+					case "space-key":
+						mark.Space = attr.Value
+					}
+				}
+				b.marked = true
+			}
+			if err := parseInline(decoder, t, mark, b); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			if pageLink && mark.Page != "" && b.length() == before {
+				b.add(mark.Page, mark)
+			}
+			return nil
+		}
+	}
+}
+
+// inlineBuilder accumulates inline text as spans, merging adjacent runs that
+// share the same formatting.
+type inlineBuilder struct {
+	spans  []Span
+	marked bool // whether any formatting was seen
+}
+
+func (b *inlineBuilder) add(text string, mark Span) {
+	if text == "" {
+		return
+	}
+	if n := len(b.spans); n > 0 && sameMarks(b.spans[n-1], mark) {
+		b.spans[n-1].Text += text
+		return
+	}
+	mark.Text = text
+	b.spans = append(b.spans, mark)
+}
+
+func (b *inlineBuilder) length() int {
+	n := 0
+	for _, span := range b.spans {
+		n += len(span.Text)
+	}
+	return n
+}
+
+func (b *inlineBuilder) empty() bool {
+	return len(b.spans) == 0
+}
+
+// result returns the trimmed plain text and, if any formatting was seen, the
+// spans carrying it.
+func (b *inlineBuilder) result() (string, []Span) {
+	spans := append([]Span{}, b.spans...)
+	if len(spans) > 0 {
+		spans[0].Text = strings.TrimLeft(spans[0].Text, " \t\r\n")
+		last := len(spans) - 1
+		spans[last].Text = strings.TrimRight(spans[last].Text, " \t\r\n")
+	}
+
+	var text strings.Builder
+	kept := spans[:0]
+	for _, span := range spans {
+		if span.Text == "" {
+			continue
+		}
+		text.WriteString(span.Text)
+		kept = append(kept, span)
+	}
+	if !b.marked || len(kept) == 0 {
+		return text.String(), nil
+	}
+	return text.String(), kept
+}
+
+func sameMarks(a, b Span) bool {
+	a.Text, b.Text = "", ""
+	return a == b
+}
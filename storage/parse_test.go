@@ -109,6 +109,39 @@ func TestParseParagraph(t *testing.T) {
 	}
 }
 
+func TestParseParagraphSpans(t *testing.T) {
+	xhtml := `<p>Plain <strong>bold <em>both</em></strong> <a href="https://example.com">link</a></p>`
+	page, err := Parse(xhtml)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	p, ok := page.Blocks[0].(*Paragraph)
+	if !ok {
+		t.Fatalf("Parse() block type = %T, want *Paragraph", page.Blocks[0])
+	}
+
+	if p.Text != "Plain bold both link" {
+		t.Errorf("Paragraph.Text = %q, want %q", p.Text, "Plain bold both link")
+	}
+
+	want := []Span{
+		{Text: "Plain "},
+		{Text: "bold ", Bold: true},
+		{Text: "both", Bold: true, Italic: true},
+		{Text: " "},
+		{Text: "link", Link: "https://example.com"},
+	}
+	if len(p.Spans) != len(want) {
+		t.Fatalf("Paragraph.Spans = %+v, want %+v", p.Spans, want)
+	}
+	for i := range want {
+		if p.Spans[i] != want[i] {
+			t.Errorf("Paragraph.Spans[%d] = %+v, want %+v", i, p.Spans[i], want[i])
+		}
+	}
+}
+
 func TestParseHeading(t *testing.T) {
 	tests := []struct {
 		xhtml     string
@@ -199,6 +232,105 @@ func TestParseNumberedList(t *testing.T) {
 	}
 }
 
+func TestParseNestedList(t *testing.T) {
+	xhtml := "<ul><li><p>Parent</p><ol><li>Child</li></ol></li></ul>"
+	page, err := Parse(xhtml)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	bl, ok := page.Blocks[0].(*BulletList)
+	if !ok {
+		t.Fatalf("Parse() block type = %T, want *BulletList", page.Blocks[0])
+	}
+	item := bl.Items[0]
+	if item.Text != "Parent" {
+		t.Errorf("ListItem.Text = %q, want Parent", item.Text)
+	}
+	if len(item.Children) != 1 {
+		t.Fatalf("ListItem.Children = %d, want 1", len(item.Children))
+	}
+	nl, ok := item.Children[0].(*NumberedList)
+	if !ok || len(nl.Items) != 1 || nl.Items[0].Text != "Child" {
+		t.Errorf("ListItem.Children[0] = %+v, want numbered list with Child", item.Children[0])
+	}
+}
+
+func TestParseTaskList(t *testing.T) {
+	xhtml := "<ac:task-list>" +
+		"<ac:task><ac:task-id>1</ac:task-id><ac:task-status>complete</ac:task-status><ac:task-body>Done</ac:task-body></ac:task>" +
+		"<ac:task><ac:task-id>2</ac:task-id><ac:task-status>incomplete</ac:task-status><ac:task-body><strong>Open</strong></ac:task-body></ac:task>" +
+		"</ac:task-list>"
+	page, err := Parse(xhtml)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tl, ok := page.Blocks[0].(*TaskList)
+	if !ok {
+		t.Fatalf("Parse() block type = %T, want *TaskList", page.Blocks[0])
+	}
+	if len(tl.Items) != 2 {
+		t.Fatalf("TaskList.Items = %d, want 2", len(tl.Items))
+	}
+	if !tl.Items[0].Done || tl.Items[0].Text != "Done" {
+		t.Errorf("TaskList.Items[0] = %+v, want done task", tl.Items[0])
+	}
+	if tl.Items[1].Done || tl.Items[1].Text != "Open" || len(tl.Items[1].Spans) != 1 {
+		t.Errorf("TaskList.Items[1] = %+v, want open bold task", tl.Items[1])
+	}
+}
+
+func TestParseBlockquote(t *testing.T) {
+	page, err := Parse("<blockquote><p>Quoted</p><ul><li>Item</li></ul></blockquote>")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	bq, ok := page.Blocks[0].(*Blockquote)
+	if !ok {
+		t.Fatalf("Parse() block type = %T, want *Blockquote", page.Blocks[0])
+	}
+	if len(bq.Blocks) != 2 {
+		t.Fatalf("Blockquote.Blocks = %d, want 2", len(bq.Blocks))
+	}
+	if p, ok := bq.Blocks[0].(*Paragraph); !ok || p.Text != "Quoted" {
+		t.Errorf("Blockquote.Blocks[0] = %+v, want Quoted paragraph", bq.Blocks[0])
+	}
+}
+
+func TestParseImage(t *testing.T) {
+	tests := []struct {
+		xhtml string
+		want  Image
+	}{
+		{
+			`<ac:image ac:alt="Logo"><ri:url ri:value="https://example.com/logo.png"/></ac:image>`,
+			Image{URL: "https://example.com/logo.png", Alt: "Logo"},
+		},
+		{
+			`<p><ac:image><ri:attachment ri:filename="diagram.png"/></ac:image></p>`,
+			Image{Attachment: "diagram.png"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.xhtml, func(t *testing.T) {
+			page, err := Parse(tt.xhtml)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			img, ok := page.Blocks[0].(*Image)
+			if !ok {
+				t.Fatalf("Parse() block type = %T, want *Image", page.Blocks[0])
+			}
+			if *img != tt.want {
+				t.Errorf("Image = %+v, want %+v", *img, tt.want)
+			}
+		})
+	}
+}
+
 func TestParseTable(t *testing.T) {
 	xhtml := `<table><tbody>
 		<tr><th>Name</th><th>Age</th></tr>
@@ -305,6 +437,32 @@ func TestParseMacro(t *testing.T) {
 	}
 }
 
+func TestParseMacroRichBody(t *testing.T) {
+	body := `<p>See <strong>this</strong> &amp; <ac:link><ri:page ri:content-title="Other"/></ac:link></p><ul><li>one</li></ul>`
+	xhtml := `<ac:structured-macro ac:name="note"><ac:rich-text-body>` + body + `</ac:rich-text-body></ac:structured-macro>`
+
+	page, err := Parse(xhtml)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	macro, ok := page.Blocks[0].(*Macro)
+	if !ok {
+		t.Fatalf("Parse() block type = %T, want *Macro", page.Blocks[0])
+	}
+	if macro.Body != body {
+		t.Errorf("Macro.Body = %s, want %s", macro.Body, body)
+	}
+
+	got, err := Render(page)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if got != xhtml {
+		t.Errorf("Render() = %s, want %s", got, xhtml)
+	}
+}
+
 func TestMultiTablePage(t *testing.T) {
 	// Page with multiple tables
 	xhtml := `<h1>Status Report</h1>
@@ -423,3 +581,47 @@ func TestRoundTrip(t *testing.T) {
 		t.Errorf("Second XHTML validation failed: %v", err)
 	}
 }
+
+func TestParsePageLink(t *testing.T) {
+	xhtml := `<p>See <ac:link><ri:page ri:space-key="OPS" ri:content-title="Runbook"/><ac:link-body><strong>the runbook</strong></ac:link-body></ac:link> or <ac:link><ri:page ri:content-title="Setup"/></ac:link>.</p>`
+
+	page, err := Parse(xhtml)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	p, ok := page.Blocks[0].(*Paragraph)
+	if !ok {
+		t.Fatalf("Parse() block type = %T, want *Paragraph", page.Blocks[0])
+	}
+	if p.Text != "See the runbook or Setup." {
+		t.Errorf("Paragraph.Text = %q", p.Text)
+	}
+	want := []Span{
+		{Text: "See "},
+		{Text: "the runbook", Bold: true, Page: "Runbook", Space: "OPS"},
+		{Text: " or "},
+		{Text: "Setup", Page: "Setup"},
+		{Text: "."},
+	}
+	if len(p.Spans) != len(want) {
+		t.Fatalf("Paragraph.Spans = %+v, want %+v", p.Spans, want)
+	}
+	for i := range want {
+		if p.Spans[i] != want[i] {
+			t.Errorf("Spans[%d] = %+v, want %+v", i, p.Spans[i], want[i])
+		}
+	}
+
+	got, err := Render(page)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	reparsed, err := Parse(got)
+	if err != nil {
+		t.Fatalf("Parse() of rendered output error = %v", err)
+	}
+	if rp := reparsed.Blocks[0].(*Paragraph); len(rp.Spans) != len(want) || rp.Spans[1] != want[1] || rp.Spans[3] != want[3] {
+		t.Errorf("round trip spans = %+v", rp.Spans)
+	}
+}
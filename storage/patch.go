@@ -0,0 +1,472 @@
+package storage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Patch operation names.
+const (
+	// PatchInsert inserts Block at /blocks/N (N may equal the block count, or be "-" to append).
+	PatchInsert = "insert"
+	// PatchReplace replaces the block at /blocks/N with Block.
+	PatchReplace = "replace"
+	// PatchRemove removes the block at /blocks/N.
+	PatchRemove = "remove"
+	// PatchSetCell sets the cell at /blocks/N/rows/R/cells/C, or the header at /blocks/N/headers/C.
+	PatchSetCell = "set_cell"
+	// PatchAppendItem appends Item to the list at /blocks/N/items, or inserts it at /blocks/N/items/K.
+	// In a task list the item becomes an open task.
+	PatchAppendItem = "append_item"
+	// PatchReplaceText replaces every occurrence of Old with New in the text of /blocks/N,
+	// including the text of blocks nested in its list items, tasks and quoted blocks.
+	PatchReplaceText = "replace_text"
+)
+
+// PatchOp is a single targeted edit of a Page. Paths are JSON-pointer style,
+// rooted at the page (e.g. "/blocks/2/rows/0/cells/1"). Blocks nested in
+// list items and blockquotes are addressed with the paths Walk and Select
+// report, such as "/blocks/5/items/0/children/1" or "/blocks/3/blocks/0",
+// and every operation applies to them as to top-level blocks.
+type PatchOp struct {
+	Op    string    `json:"op"`
+	Path  string    `json:"path"`
+	Block Block     `json:"-"`
+	Cell  *Cell     `json:"cell,omitempty"`
+	Item  *ListItem `json:"item,omitempty"`
+	Old   string    `json:"old,omitempty"`
+	New   string    `json:"new,omitempty"`
+}
+
+// Patch is an ordered list of operations. Each operation sees the result of
+// the previous ones, so indexes refer to the page as it is at that point.
+type Patch []PatchOp
+
+// PatchError reports which operation of a patch failed.
+type PatchError struct {
+	Index int
+	Op    string
+	Path  string
+	Err   error
+}
+
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("patch op %d (%s %s): %v", e.Index, e.Op, e.Path, e.Err)
+}
+
+func (e *PatchError) Unwrap() error { return e.Err }
+
+// Apply returns a copy of page with the patch applied. The input page is
+// never modified; if any operation fails, no changes are returned.
+func Apply(page *Page, patch Patch) (*Page, error) {
+	result := &Page{}
+	if page != nil {
+		result.Blocks = append([]Block{}, page.Blocks...)
+	}
+
+	for i, op := range patch {
+		if err := applyOp(result, op); err != nil {
+			return nil, &PatchError{Index: i, Op: op.Op, Path: op.Path, Err: err}
+		}
+	}
+	return result, nil
+}
+
+func applyOp(page *Page, op PatchOp) error {
+	segments, err := splitPath(op.Path)
+	if err != nil {
+		return err
+	}
+	page.Blocks, err = applyAt(page.Blocks, segments, op)
+	return err
+}
+
+// applyAt applies op to the blocks addressed by segments, descending into
+// nested blocks. It returns the updated blocks; containers on the way are
+// copied rather than modified.
+func applyAt(blocks []Block, segments []string, op PatchOp) ([]Block, error) {
+	page := &Page{Blocks: append([]Block{}, blocks...)}
+	nested := len(segments) >= 5 && segments[1] == "items" && segments[3] == "children" ||
+		len(segments) >= 3 && segments[1] == "blocks"
+	if !nested {
+		if err := applyLocal(page, segments, op); err != nil {
+			return nil, err
+		}
+		return page.Blocks, nil
+	}
+
+	index, block, err := blockAt(page, segments)
+	if err != nil {
+		return nil, err
+	}
+	if page.Blocks[index], err = applyNested(index, block, segments[1:], op); err != nil {
+		return nil, err
+	}
+	return page.Blocks, nil
+}
+
+// applyNested returns a copy of the container block with op applied to
+// the blocks below it, addressed by segments "items/K/children/..." for
+// lists or "blocks/..." for blockquotes.
+func applyNested(index int, block Block, segments []string, op PatchOp) (Block, error) {
+	if segments[0] == "blocks" {
+		quote, ok := block.(*Blockquote)
+		if !ok {
+			return nil, fmt.Errorf("block %d is a %s, not a blockquote", index, block.BlockType())
+		}
+		children, err := applyAt(quote.Blocks, segments[1:], op)
+		if err != nil {
+			return nil, err
+		}
+		return &Blockquote{Blocks: children}, nil
+	}
+
+	rest := segments[3:]
+	switch b := block.(type) {
+	case *BulletList:
+		items, err := applyItemChildren(b.Items, segments[1], rest, op)
+		return &BulletList{Items: items}, err
+	case *NumberedList:
+		items, err := applyItemChildren(b.Items, segments[1], rest, op)
+		return &NumberedList{Items: items}, err
+	case *TaskList:
+		k, err := parseIndex(segments[1], len(b.Items))
+		if err != nil {
+			return nil, err
+		}
+		items := append([]TaskItem{}, b.Items...)
+		if items[k].Children, err = applyAt(items[k].Children, rest, op); err != nil {
+			return nil, err
+		}
+		return &TaskList{Items: items}, nil
+	}
+	return nil, fmt.Errorf("block %d is a %s, not a list", index, block.BlockType())
+}
+
+// applyItemChildren applies op below the children of the list item at
+// index segment.
+func applyItemChildren(items []ListItem, segment string, segments []string, op PatchOp) ([]ListItem, error) {
+	k, err := parseIndex(segment, len(items))
+	if err != nil {
+		return nil, err
+	}
+	items = append([]ListItem{}, items...)
+	if items[k].Children, err = applyAt(items[k].Children, segments, op); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// applyLocal applies op to blocks directly in page.
+func applyLocal(page *Page, segments []string, op PatchOp) error {
+	var err error
+	switch op.Op {
+	case PatchInsert:
+		if op.Block == nil {
+			return fmt.Errorf("block is required")
+		}
+		if len(segments) != 1 {
+			return fmt.Errorf("path must address a block position")
+		}
+		index := len(page.Blocks)
+		if segments[0] != "-" {
+			if index, err = parseIndex(segments[0], len(page.Blocks)+1); err != nil {
+				return err
+			}
+		}
+		page.Blocks = append(page.Blocks, nil)
+		copy(page.Blocks[index+1:], page.Blocks[index:])
+		page.Blocks[index] = op.Block
+		return nil
+	case PatchReplace, PatchRemove:
+		if len(segments) != 1 {
+			return fmt.Errorf("path must address a block")
+		}
+		index, err := parseIndex(segments[0], len(page.Blocks))
+		if err != nil {
+			return err
+		}
+		if op.Op == PatchRemove {
+			page.Blocks = append(page.Blocks[:index], page.Blocks[index+1:]...)
+			return nil
+		}
+		if op.Block == nil {
+			return fmt.Errorf("block is required")
+		}
+		page.Blocks[index] = op.Block
+		return nil
+	case PatchSetCell:
+		return applySetCell(page, segments, op)
+	case PatchAppendItem:
+		return applyAppendItem(page, segments, op)
+	case PatchReplaceText:
+		return applyReplaceText(page, segments, op)
+	default:
+		return fmt.Errorf("unknown op: %s", op.Op)
+	}
+}
+
+// splitPath parses "/blocks/..." into the segments after "blocks".
+func splitPath(path string) ([]string, error) {
+	if !strings.HasPrefix(path, "/blocks/") {
+		return nil, fmt.Errorf("path must start with /blocks/")
+	}
+	return strings.Split(strings.TrimPrefix(path, "/blocks/"), "/"), nil
+}
+
+// parseIndex parses an index segment that must be in [0, limit).
+func parseIndex(segment string, limit int) (int, error) {
+	index, err := strconv.Atoi(segment)
+	if err != nil {
+		return 0, fmt.Errorf("invalid index %q", segment)
+	}
+	if index < 0 || index >= limit {
+		return 0, fmt.Errorf("index %d out of range", index)
+	}
+	return index, nil
+}
+
+// blockAt returns the index and block addressed by the first path segment.
+func blockAt(page *Page, segments []string) (int, Block, error) {
+	index, err := parseIndex(segments[0], len(page.Blocks))
+	if err != nil {
+		return 0, nil, err
+	}
+	return index, pointerBlock(page.Blocks[index]), nil
+}
+
+func applySetCell(page *Page, segments []string, op PatchOp) error {
+	if op.Cell == nil {
+		return fmt.Errorf("cell is required")
+	}
+	index, block, err := blockAt(page, segments)
+	if err != nil {
+		return err
+	}
+	table, ok := block.(*Table)
+	if !ok {
+		return fmt.Errorf("block %d is a %s, not a table", index, block.BlockType())
+	}
+	table = cloneTable(table)
+
+	switch {
+	case len(segments) == 3 && segments[1] == "headers":
+		col, err := parseIndex(segments[2], len(table.Headers))
+		if err != nil {
+			return err
+		}
+		if op.Cell.Macro != nil {
+			return fmt.Errorf("header cells cannot contain macros")
+		}
+		table.Headers[col] = op.Cell.Text
+	case len(segments) == 5 && segments[1] == "rows" && segments[3] == "cells":
+		row, err := parseIndex(segments[2], len(table.Rows))
+		if err != nil {
+			return err
+		}
+		col, err := parseIndex(segments[4], len(table.Rows[row].Cells))
+		if err != nil {
+			return err
+		}
+		table.Rows[row].Cells[col] = *op.Cell
+	default:
+		return fmt.Errorf("path must address /blocks/N/rows/R/cells/C or /blocks/N/headers/C")
+	}
+
+	page.Blocks[index] = table
+	return nil
+}
+
+func applyAppendItem(page *Page, segments []string, op PatchOp) error {
+	if op.Item == nil {
+		return fmt.Errorf("item is required")
+	}
+	if len(segments) < 2 || len(segments) > 3 || segments[1] != "items" {
+		return fmt.Errorf("path must address /blocks/N/items or /blocks/N/items/K")
+	}
+	index, block, err := blockAt(page, segments)
+	if err != nil {
+		return err
+	}
+	if tasks, ok := block.(*TaskList); ok {
+		pos, err := itemPosition(segments, len(tasks.Items))
+		if err != nil {
+			return err
+		}
+		updated := make([]TaskItem, 0, len(tasks.Items)+1)
+		updated = append(updated, tasks.Items[:pos]...)
+		updated = append(updated, TaskItem{Text: op.Item.Text, Spans: op.Item.Spans, Children: op.Item.Children})
+		updated = append(updated, tasks.Items[pos:]...)
+		page.Blocks[index] = &TaskList{Items: updated}
+		return nil
+	}
+	items, ok := listItems(block)
+	if !ok {
+		return fmt.Errorf("block %d is a %s, not a list", index, block.BlockType())
+	}
+	pos, err := itemPosition(segments, len(items))
+	if err != nil {
+		return err
+	}
+	updated := make([]ListItem, 0, len(items)+1)
+	updated = append(updated, items[:pos]...)
+	updated = append(updated, *op.Item)
+	updated = append(updated, items[pos:]...)
+
+	switch block.(type) {
+	case *BulletList:
+		page.Blocks[index] = &BulletList{Items: updated}
+	case *NumberedList:
+		page.Blocks[index] = &NumberedList{Items: updated}
+	}
+	return nil
+}
+
+// itemPosition returns where append_item inserts into a list of n items:
+// the end, or the index given by the optional third path segment.
+func itemPosition(segments []string, n int) (int, error) {
+	if len(segments) == 3 {
+		return parseIndex(segments[2], n+1)
+	}
+	return n, nil
+}
+
+func applyReplaceText(page *Page, segments []string, op PatchOp) error {
+	if op.Old == "" {
+		return fmt.Errorf("old text is required")
+	}
+	if len(segments) != 1 {
+		return fmt.Errorf("path must address a block")
+	}
+	index, block, err := blockAt(page, segments)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	replace := func(s string) string {
+		if strings.Contains(s, op.Old) {
+			found = true
+			return strings.ReplaceAll(s, op.Old, op.New)
+		}
+		return s
+	}
+
+	updated := replaceText(block, op, replace)
+	if updated == nil {
+		return fmt.Errorf("block %d is a %s, which has no editable text", index, block.BlockType())
+	}
+	if !found {
+		return fmt.Errorf("text %q not found in block %d", op.Old, index)
+	}
+	page.Blocks[index] = updated
+	return nil
+}
+
+// replaceText returns a copy of block with replace applied to its text,
+// including blocks nested in list items, tasks and blockquotes, or nil if
+// block has no editable text.
+func replaceText(block Block, op PatchOp, replace func(string) string) Block {
+	switch b := pointerBlock(block).(type) {
+	case *Paragraph:
+		return &Paragraph{Text: replace(b.Text), Spans: replaceSpans(b.Spans, op.Old, op.New)}
+	case *Heading:
+		return &Heading{Level: b.Level, Text: replace(b.Text)}
+	case *CodeBlock:
+		return &CodeBlock{Language: b.Language, Code: replace(b.Code)}
+	case *BulletList:
+		return &BulletList{Items: replaceItems(b.Items, op, replace)}
+	case *NumberedList:
+		return &NumberedList{Items: replaceItems(b.Items, op, replace)}
+	case *TaskList:
+		items := make([]TaskItem, len(b.Items))
+		for i, item := range b.Items {
+			items[i] = TaskItem{
+				Text:     replace(item.Text),
+				Spans:    replaceSpans(item.Spans, op.Old, op.New),
+				Done:     item.Done,
+				Children: replaceChildren(item.Children, op, replace),
+			}
+		}
+		return &TaskList{Items: items}
+	case *Blockquote:
+		return &Blockquote{Blocks: replaceChildren(b.Blocks, op, replace)}
+	case *Table:
+		table := cloneTable(b)
+		for i := range table.Headers {
+			table.Headers[i] = replace(table.Headers[i])
+		}
+		for r := range table.Rows {
+			for c := range table.Rows[r].Cells {
+				table.Rows[r].Cells[c].Text = replace(table.Rows[r].Cells[c].Text)
+			}
+		}
+		return table
+	}
+	return nil
+}
+
+func replaceItems(items []ListItem, op PatchOp, replace func(string) string) []ListItem {
+	result := make([]ListItem, len(items))
+	for i, item := range items {
+		result[i] = ListItem{
+			Text:     replace(item.Text),
+			Spans:    replaceSpans(item.Spans, op.Old, op.New),
+			Children: replaceChildren(item.Children, op, replace),
+		}
+	}
+	return result
+}
+
+// replaceChildren applies replace to the blocks nested in a list item, task
+// or blockquote, leaving blocks without editable text unchanged.
+func replaceChildren(blocks []Block, op PatchOp, replace func(string) string) []Block {
+	if blocks == nil {
+		return nil
+	}
+	result := make([]Block, len(blocks))
+	for i, block := range blocks {
+		result[i] = block
+		if updated := replaceText(block, op, replace); updated != nil {
+			result[i] = updated
+		}
+	}
+	return result
+}
+
+// replaceSpans applies a replacement to formatted text. Occurrences inside a
+// single span keep its formatting; if the old text crosses span boundaries
+// the formatting is dropped so the plain text remains authoritative.
+func replaceSpans(spans []Span, old, new string) []Span {
+	if len(spans) == 0 {
+		return nil
+	}
+	var text strings.Builder
+	for _, span := range spans {
+		text.WriteString(span.Text)
+	}
+	crossed := strings.Count(text.String(), old)
+	result := make([]Span, len(spans))
+	for i, span := range spans {
+		crossed -= strings.Count(span.Text, old)
+		span.Text = strings.ReplaceAll(span.Text, old, new)
+		result[i] = span
+	}
+	if crossed != 0 {
+		return nil
+	}
+	return result
+}
+
+// cloneTable returns a copy of t that shares no slices with it.
+func cloneTable(t *Table) *Table {
+	clone := &Table{
+		Headers: append([]string{}, t.Headers...),
+		Rows:    make([]Row, len(t.Rows)),
+	}
+	for i, row := range t.Rows {
+		clone.Rows[i] = Row{Cells: append([]Cell{}, row.Cells...)}
+	}
+	return clone
+}
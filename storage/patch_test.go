@@ -0,0 +1,331 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+)
+
+func patchTestPage() *Page {
+	return &Page{Blocks: []Block{
+		&Heading{Level: 1, Text: "Services"},
+		&Paragraph{Text: "Owned by the platform team."},
+		&Table{
+			Headers: []string{"Service", "Owner"},
+			Rows: []Row{
+				{Cells: []Cell{{Text: "Auth"}, {Text: "Alice"}}},
+				{Cells: []Cell{{Text: "API"}, {Text: "Bob"}}},
+			},
+		},
+		&BulletList{Items: []ListItem{{Text: "One", Children: []Block{
+			&NumberedList{Items: []ListItem{{Text: "One point one"}}},
+		}}}},
+	}}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch Patch
+		check func(t *testing.T, p *Page)
+	}{
+		{
+			name:  "insert",
+			patch: Patch{{Op: PatchInsert, Path: "/blocks/1", Block: &HorizontalRule{}}},
+			check: func(t *testing.T, p *Page) {
+				if len(p.Blocks) != 5 || p.Blocks[1].BlockType() != "horizontal_rule" {
+					t.Errorf("blocks = %v", p.Blocks)
+				}
+			},
+		},
+		{
+			name:  "insert append",
+			patch: Patch{{Op: PatchInsert, Path: "/blocks/-", Block: &Paragraph{Text: "End"}}},
+			check: func(t *testing.T, p *Page) {
+				if p.Blocks[4].(*Paragraph).Text != "End" {
+					t.Errorf("last block = %v", p.Blocks[4])
+				}
+			},
+		},
+		{
+			name:  "replace",
+			patch: Patch{{Op: PatchReplace, Path: "/blocks/0", Block: &Heading{Level: 2, Text: "New"}}},
+			check: func(t *testing.T, p *Page) {
+				if h := p.Blocks[0].(*Heading); h.Level != 2 || h.Text != "New" {
+					t.Errorf("block 0 = %+v", h)
+				}
+			},
+		},
+		{
+			name:  "remove",
+			patch: Patch{{Op: PatchRemove, Path: "/blocks/1"}},
+			check: func(t *testing.T, p *Page) {
+				if len(p.Blocks) != 3 || p.Blocks[1].BlockType() != "table" {
+					t.Errorf("blocks = %v", p.Blocks)
+				}
+			},
+		},
+		{
+			name:  "set cell",
+			patch: Patch{{Op: PatchSetCell, Path: "/blocks/2/rows/1/cells/1", Cell: &Cell{Text: "Carol"}}},
+			check: func(t *testing.T, p *Page) {
+				if got := p.Blocks[2].(*Table).Rows[1].Cells[1].Text; got != "Carol" {
+					t.Errorf("cell = %q, want Carol", got)
+				}
+			},
+		},
+		{
+			name:  "set header",
+			patch: Patch{{Op: PatchSetCell, Path: "/blocks/2/headers/1", Cell: &Cell{Text: "Team"}}},
+			check: func(t *testing.T, p *Page) {
+				if got := p.Blocks[2].(*Table).Headers[1]; got != "Team" {
+					t.Errorf("header = %q, want Team", got)
+				}
+			},
+		},
+		{
+			name:  "append item",
+			patch: Patch{{Op: PatchAppendItem, Path: "/blocks/3/items", Item: &ListItem{Text: "Two"}}},
+			check: func(t *testing.T, p *Page) {
+				items := p.Blocks[3].(*BulletList).Items
+				if len(items) != 2 || items[1].Text != "Two" {
+					t.Errorf("items = %v", items)
+				}
+			},
+		},
+		{
+			name:  "insert item at index",
+			patch: Patch{{Op: PatchAppendItem, Path: "/blocks/3/items/0", Item: &ListItem{Text: "Zero"}}},
+			check: func(t *testing.T, p *Page) {
+				items := p.Blocks[3].(*BulletList).Items
+				if len(items) != 2 || items[0].Text != "Zero" {
+					t.Errorf("items = %v", items)
+				}
+			},
+		},
+		{
+			name:  "replace text in paragraph",
+			patch: Patch{{Op: PatchReplaceText, Path: "/blocks/1", Old: "platform", New: "infra"}},
+			check: func(t *testing.T, p *Page) {
+				if got := p.Blocks[1].(*Paragraph).Text; got != "Owned by the infra team." {
+					t.Errorf("text = %q", got)
+				}
+			},
+		},
+		{
+			name:  "replace text in table",
+			patch: Patch{{Op: PatchReplaceText, Path: "/blocks/2", Old: "Bob", New: "Robert"}},
+			check: func(t *testing.T, p *Page) {
+				if got := p.Blocks[2].(*Table).Rows[1].Cells[1].Text; got != "Robert" {
+					t.Errorf("cell = %q", got)
+				}
+			},
+		},
+		{
+			name:  "replace text in nested list",
+			patch: Patch{{Op: PatchReplaceText, Path: "/blocks/3", Old: "One", New: "First"}},
+			check: func(t *testing.T, p *Page) {
+				item := p.Blocks[3].(*BulletList).Items[0]
+				nested := item.Children[0].(*NumberedList).Items[0]
+				if item.Text != "First" || nested.Text != "First point one" {
+					t.Errorf("item = %q, nested = %q", item.Text, nested.Text)
+				}
+			},
+		},
+		{
+			name:  "append item to nested list",
+			patch: Patch{{Op: PatchAppendItem, Path: "/blocks/3/items/0/children/0/items", Item: &ListItem{Text: "One point two"}}},
+			check: func(t *testing.T, p *Page) {
+				items := p.Blocks[3].(*BulletList).Items[0].Children[0].(*NumberedList).Items
+				if len(items) != 2 || items[1].Text != "One point two" {
+					t.Errorf("nested items = %v", items)
+				}
+			},
+		},
+		{
+			name:  "insert into list item",
+			patch: Patch{{Op: PatchInsert, Path: "/blocks/3/items/0/children/-", Block: &Paragraph{Text: "Note"}}},
+			check: func(t *testing.T, p *Page) {
+				children := p.Blocks[3].(*BulletList).Items[0].Children
+				if len(children) != 2 || children[1].(*Paragraph).Text != "Note" {
+					t.Errorf("children = %v", children)
+				}
+			},
+		},
+		{
+			name:  "remove nested block",
+			patch: Patch{{Op: PatchRemove, Path: "/blocks/3/items/0/children/0"}},
+			check: func(t *testing.T, p *Page) {
+				if children := p.Blocks[3].(*BulletList).Items[0].Children; len(children) != 0 {
+					t.Errorf("children = %v", children)
+				}
+			},
+		},
+		{
+			name: "operations see earlier results",
+			patch: Patch{
+				{Op: PatchRemove, Path: "/blocks/0"},
+				{Op: PatchSetCell, Path: "/blocks/1/rows/0/cells/0", Cell: &Cell{Text: "SSO"}},
+			},
+			check: func(t *testing.T, p *Page) {
+				if got := p.Blocks[1].(*Table).Rows[0].Cells[0].Text; got != "SSO" {
+					t.Errorf("cell = %q", got)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := patchTestPage()
+			before, _ := Render(original)
+
+			got, err := Apply(original, tt.patch)
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			tt.check(t, got)
+
+			if after, _ := Render(original); after != before {
+				t.Errorf("Apply() modified the input page")
+			}
+		})
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch Patch
+	}{
+		{"unknown op", Patch{{Op: "copy", Path: "/blocks/0"}}},
+		{"bad path", Patch{{Op: PatchRemove, Path: "/rows/0"}}},
+		{"index out of range", Patch{{Op: PatchRemove, Path: "/blocks/9"}}},
+		{"non-numeric index", Patch{{Op: PatchRemove, Path: "/blocks/x"}}},
+		{"insert without block", Patch{{Op: PatchInsert, Path: "/blocks/0"}}},
+		{"set cell on paragraph", Patch{{Op: PatchSetCell, Path: "/blocks/1/rows/0/cells/0", Cell: &Cell{Text: "x"}}}},
+		{"set cell out of range", Patch{{Op: PatchSetCell, Path: "/blocks/2/rows/5/cells/0", Cell: &Cell{Text: "x"}}}},
+		{"append item to table", Patch{{Op: PatchAppendItem, Path: "/blocks/2/items", Item: &ListItem{Text: "x"}}}},
+		{"replace missing text", Patch{{Op: PatchReplaceText, Path: "/blocks/1", Old: "absent", New: "x"}}},
+		{"replace text in rule", Patch{{Op: PatchReplaceText, Path: "/blocks/0", Old: "Services", New: "x"}}},
+		{"nested path through paragraph", Patch{{Op: PatchRemove, Path: "/blocks/1/items/0/children/0"}}},
+		{"nested path through list as blockquote", Patch{{Op: PatchRemove, Path: "/blocks/3/blocks/0"}}},
+		{"nested item out of range", Patch{{Op: PatchRemove, Path: "/blocks/3/items/5/children/0"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := patchTestPage()
+			if tt.name == "replace text in rule" {
+				page.Blocks[0] = &HorizontalRule{}
+			}
+			_, err := Apply(page, tt.patch)
+			if err == nil {
+				t.Fatal("Apply() should return error")
+			}
+			var patchErr *PatchError
+			if !errors.As(err, &patchErr) {
+				t.Fatalf("Apply() error should be *PatchError, got %T", err)
+			}
+			if patchErr.Index != 0 {
+				t.Errorf("PatchError.Index = %d, want 0", patchErr.Index)
+			}
+		})
+	}
+}
+
+func TestApplyIsAtomic(t *testing.T) {
+	page := patchTestPage()
+	_, err := Apply(page, Patch{
+		{Op: PatchRemove, Path: "/blocks/0"},
+		{Op: PatchRemove, Path: "/blocks/10"},
+	})
+	var patchErr *PatchError
+	if !errors.As(err, &patchErr) || patchErr.Index != 1 {
+		t.Fatalf("Apply() error = %v, want failure at op 1", err)
+	}
+	if len(page.Blocks) != 4 {
+		t.Errorf("input page has %d blocks after failed patch, want 4", len(page.Blocks))
+	}
+}
+
+func TestApplySelectedPaths(t *testing.T) {
+	page := &Page{Blocks: []Block{
+		&Heading{Level: 1, Text: "Owners"},
+		&Blockquote{Blocks: []Block{
+			&Paragraph{Text: "Quoted"},
+			&Table{Headers: []string{"Team"}, Rows: []Row{{Cells: []Cell{{Text: "Payments"}}}}},
+		}},
+	}}
+
+	matches, err := Select(page, "table")
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("Select() = %v, %v", matches, err)
+	}
+	got, err := Apply(page, Patch{
+		{Op: PatchSetCell, Path: matches[0].Path + "/rows/0/cells/0", Cell: &Cell{Text: "Ledger"}},
+		{Op: PatchReplaceText, Path: "/blocks/1/blocks/0", Old: "Quoted", New: "Cited"},
+	})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	quote := got.Blocks[1].(*Blockquote)
+	if cell := quote.Blocks[1].(*Table).Rows[0].Cells[0].Text; cell != "Ledger" {
+		t.Errorf("cell = %q, want Ledger", cell)
+	}
+	if text := quote.Blocks[0].(*Paragraph).Text; text != "Cited" {
+		t.Errorf("paragraph = %q, want Cited", text)
+	}
+	if cell := page.Blocks[1].(*Blockquote).Blocks[1].(*Table).Rows[0].Cells[0].Text; cell != "Payments" {
+		t.Errorf("Apply() modified the input page: cell = %q", cell)
+	}
+}
+
+func TestApplyTasksAndQuotes(t *testing.T) {
+	page := &Page{Blocks: []Block{
+		&TaskList{Items: []TaskItem{
+			{Text: "Rotate keys", Spans: []Span{{Text: "Rotate keys", Bold: true}}, Done: true, Children: []Block{
+				&Paragraph{Text: "Rotate keys quarterly"},
+			}},
+		}},
+		&Blockquote{Blocks: []Block{
+			&Paragraph{Text: "Rotate keys before release"},
+			&BulletList{Items: []ListItem{{Text: "Rotate keys on staging"}}},
+		}},
+	}}
+
+	got, err := Apply(page, Patch{
+		{Op: PatchReplaceText, Path: "/blocks/0", Old: "keys", New: "secrets"},
+		{Op: PatchReplaceText, Path: "/blocks/1", Old: "keys", New: "secrets"},
+		{Op: PatchAppendItem, Path: "/blocks/0/items/0", Item: &ListItem{Text: "Audit access"}},
+	})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	tasks := got.Blocks[0].(*TaskList).Items
+	if len(tasks) != 2 || tasks[0].Text != "Audit access" || tasks[0].Done {
+		t.Fatalf("tasks = %+v", tasks)
+	}
+	task := tasks[1]
+	if task.Text != "Rotate secrets" || !task.Done || len(task.Spans) != 1 || task.Spans[0].Text != "Rotate secrets" || !task.Spans[0].Bold {
+		t.Errorf("task = %+v", task)
+	}
+	if text := task.Children[0].(*Paragraph).Text; text != "Rotate secrets quarterly" {
+		t.Errorf("task child = %q", text)
+	}
+
+	quote := got.Blocks[1].(*Blockquote)
+	if text := quote.Blocks[0].(*Paragraph).Text; text != "Rotate secrets before release" {
+		t.Errorf("quoted paragraph = %q", text)
+	}
+	if text := quote.Blocks[1].(*BulletList).Items[0].Text; text != "Rotate secrets on staging" {
+		t.Errorf("quoted item = %q", text)
+	}
+
+	if text := page.Blocks[0].(*TaskList).Items[0].Text; text != "Rotate keys" {
+		t.Errorf("Apply() modified the input page: task = %q", text)
+	}
+	if text := page.Blocks[1].(*Blockquote).Blocks[0].(*Paragraph).Text; text != "Rotate keys before release" {
+		t.Errorf("Apply() modified the input page: quote = %q", text)
+	}
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"fmt"
 	"html"
+	"sort"
 	"strings"
 )
 
@@ -57,6 +58,18 @@ func RenderBlock(block Block) (string, error) {
 		return "<hr/>", nil
 	case HorizontalRule:
 		return "<hr/>", nil
+	case *TaskList:
+		return renderTaskList(b)
+	case TaskList:
+		return renderTaskList(&b)
+	case *Blockquote:
+		return renderBlockquote(b)
+	case Blockquote:
+		return renderBlockquote(&b)
+	case *Image:
+		return renderImage(b)
+	case Image:
+		return renderImage(&b)
 	default:
 		return "", fmt.Errorf("unsupported block type: %T", block)
 	}
@@ -106,6 +119,13 @@ func renderCell(c *Cell) string {
 	return html.EscapeString(c.Text)
 }
 
+// plainTextMacros are the macros whose body is plain text rather than
+// Storage XHTML.
+var plainTextMacros = map[string]bool{
+	"code":     true,
+	"noformat": true,
+}
+
 // RenderMacro converts a Macro to Storage XHTML.
 func RenderMacro(m *Macro) (string, error) {
 	if m == nil {
@@ -116,15 +136,27 @@ func RenderMacro(m *Macro) (string, error) {
 	buf.WriteString(html.EscapeString(m.Name))
 	buf.WriteString(`">`)
 
-	for key, val := range m.Params {
+	// Emit parameters in a stable order so identical macros render identically.
+	keys := make([]string, 0, len(m.Params))
+	for key := range m.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
 		buf.WriteString(`<ac:parameter ac:name="`)
 		buf.WriteString(html.EscapeString(key))
 		buf.WriteString(`">`)
-		buf.WriteString(html.EscapeString(val))
+		buf.WriteString(html.EscapeString(m.Params[key]))
 		buf.WriteString(`</ac:parameter>`)
 	}
 
-	if m.Body != "" {
+	switch {
+	case m.Body == "":
+	case plainTextMacros[m.Name]:
+		buf.WriteString(`<ac:plain-text-body><![CDATA[`)
+		buf.WriteString(m.Body)
+		buf.WriteString(`]]></ac:plain-text-body>`)
+	default:
 		buf.WriteString(`<ac:rich-text-body>`)
 		buf.WriteString(m.Body) // Body is assumed to be valid Storage XHTML
 		buf.WriteString(`</ac:rich-text-body>`)
@@ -138,7 +170,66 @@ func renderParagraph(p *Paragraph) (string, error) {
 	if p == nil {
 		return "", nil
 	}
-	return "<p>" + html.EscapeString(p.Text) + "</p>", nil
+	return "<p>" + renderInline(p.Text, p.Spans) + "</p>", nil
+}
+
+// renderInline renders spans when present and the escaped plain text otherwise.
+func renderInline(text string, spans []Span) string {
+	spans = matchingSpans(text, spans)
+	if len(spans) == 0 {
+		return html.EscapeString(text)
+	}
+	var buf strings.Builder
+	for _, span := range spans {
+		buf.WriteString(renderSpan(span))
+	}
+	return buf.String()
+}
+
+// matchingSpans returns spans unless text is set and differs from their
+// concatenated text, in which case it returns nil. Text is authoritative,
+// so content whose text was edited without its spans renders as the edited
+// plain text; content given only as spans renders as before.
+func matchingSpans(text string, spans []Span) []Span {
+	if text == "" {
+		return spans
+	}
+	var buf strings.Builder
+	for _, span := range spans {
+		buf.WriteString(span.Text)
+	}
+	if buf.String() != text {
+		return nil
+	}
+	return spans
+}
+
+func renderSpan(s Span) string {
+	out := html.EscapeString(s.Text)
+	if s.Code {
+		out = "<code>" + out + "</code>"
+	}
+	if s.Strike {
+		out = "<s>" + out + "</s>"
+	}
+	if s.Italic {
+		out = "<em>" + out + "</em>"
+	}
+	if s.Bold {
+		out = "<strong>" + out + "</strong>"
+	}
+	if s.Link != "" {
+		out = `<a href="` + html.EscapeString(s.Link) + `">` + out + "</a>"
+	}
+	if s.Page != "" {
+		ref := "<ri:page"
+		if s.Space != "" {
+			ref += ` ri:space-key="` + html.EscapeString(s.Space) + `"`
+		}
+		ref += ` ri:content-title="` + html.EscapeString(s.Page) + `"/>`
+		out = "<ac:link>" + ref + "<ac:link-body>" + out + "</ac:link-body></ac:link>"
+	}
+	return out
 }
 
 func renderHeading(h *Heading) (string, error) {
@@ -157,10 +248,8 @@ func renderBulletList(bl *BulletList) (string, error) {
 	}
 	var buf strings.Builder
 	buf.WriteString("<ul>")
-	for _, item := range bl.Items {
-		buf.WriteString("<li>")
-		buf.WriteString(html.EscapeString(item.Text))
-		buf.WriteString("</li>")
+	if err := renderListItems(&buf, bl.Items); err != nil {
+		return "", err
 	}
 	buf.WriteString("</ul>")
 	return buf.String(), nil
@@ -172,12 +261,98 @@ func renderNumberedList(nl *NumberedList) (string, error) {
 	}
 	var buf strings.Builder
 	buf.WriteString("<ol>")
-	for _, item := range nl.Items {
+	if err := renderListItems(&buf, nl.Items); err != nil {
+		return "", err
+	}
+	buf.WriteString("</ol>")
+	return buf.String(), nil
+}
+
+func renderListItems(buf *strings.Builder, items []ListItem) error {
+	for _, item := range items {
 		buf.WriteString("<li>")
-		buf.WriteString(html.EscapeString(item.Text))
+		buf.WriteString(renderInline(item.Text, item.Spans))
+		if err := renderChildren(buf, item.Children); err != nil {
+			return err
+		}
 		buf.WriteString("</li>")
 	}
-	buf.WriteString("</ol>")
+	return nil
+}
+
+func renderChildren(buf *strings.Builder, blocks []Block) error {
+	for _, block := range blocks {
+		s, err := RenderBlock(block)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(s)
+	}
+	return nil
+}
+
+func renderTaskList(tl *TaskList) (string, error) {
+	if tl == nil {
+		return "", nil
+	}
+	var buf strings.Builder
+	buf.WriteString("<ac:task-list>")
+	for _, item := range tl.Items {
+		status := "incomplete"
+		if item.Done {
+			status = "complete"
+		}
+		buf.WriteString("<ac:task><ac:task-status>")
+		buf.WriteString(status)
+		buf.WriteString("</ac:task-status><ac:task-body>")
+		buf.WriteString(renderInline(item.Text, item.Spans))
+		if err := renderChildren(&buf, item.Children); err != nil {
+			return "", err
+		}
+		buf.WriteString("</ac:task-body></ac:task>")
+	}
+	buf.WriteString("</ac:task-list>")
+	return buf.String(), nil
+}
+
+func renderBlockquote(bq *Blockquote) (string, error) {
+	if bq == nil {
+		return "", nil
+	}
+	var buf strings.Builder
+	buf.WriteString("<blockquote>")
+	if err := renderChildren(&buf, bq.Blocks); err != nil {
+		return "", err
+	}
+	buf.WriteString("</blockquote>")
+	return buf.String(), nil
+}
+
+func renderImage(img *Image) (string, error) {
+	if img == nil {
+		return "", nil
+	}
+	var buf strings.Builder
+	buf.WriteString("<ac:image")
+	if img.Alt != "" {
+		buf.WriteString(` ac:alt="`)
+		buf.WriteString(html.EscapeString(img.Alt))
+		buf.WriteString(`"`)
+	}
+	buf.WriteString(">")
+	switch {
+	case img.Attachment != "":
+		buf.WriteString(`<ri:attachment ri:filename="`)
+		buf.WriteString(html.EscapeString(img.Attachment))
+		buf.WriteString(`"/>`)
+	case img.URL != "":
+		buf.WriteString(`<ri:url ri:value="`)
+		buf.WriteString(html.EscapeString(img.URL))
+		buf.WriteString(`"/>`)
+	default:
+		return "", fmt.Errorf("image requires a url or attachment")
+	}
+	buf.WriteString("</ac:image>")
 	return buf.String(), nil
 }
 
@@ -0,0 +1,379 @@
+package storage
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// HTMLOptions controls RenderHTML.
+type HTMLOptions struct {
+	// Standalone wraps the content in a complete HTML5 document.
+	Standalone bool
+	// Title is the document title and leading heading of a standalone
+	// document.
+	Title string
+	// Stylesheet embeds DefaultStylesheet in a standalone document.
+	Stylesheet bool
+	// ResolvePage returns the href of a linked Confluence page. Space is
+	// empty for links within the current space. Links resolved to "" (or
+	// all page links when ResolvePage is nil) are rendered as plain text.
+	ResolvePage func(space, title string) string
+	// ResolveAttachment returns the src of an attached image. When nil the
+	// filename is used as a relative URL.
+	ResolveAttachment func(filename string) string
+}
+
+// DefaultStylesheet is the CSS embedded by standalone documents when
+// HTMLOptions.Stylesheet is set.
+const DefaultStylesheet = `body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; line-height: 1.6; color: #172b4d; max-width: 52rem; margin: 2rem auto; padding: 0 1rem; }
+h1, h2, h3, h4, h5, h6 { line-height: 1.25; margin: 1.5em 0 0.5em; }
+a { color: #0052cc; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #c1c7d0; padding: 0.4em 0.75em; text-align: left; vertical-align: top; }
+th { background: #f4f5f7; }
+pre { background: #f4f5f7; border-radius: 3px; padding: 0.75em 1em; overflow-x: auto; }
+code { font-family: SFMono-Regular, Consolas, "Liberation Mono", Menlo, monospace; font-size: 0.9em; }
+blockquote { border-left: 3px solid #c1c7d0; margin: 1em 0; padding-left: 1em; color: #5e6c84; }
+img { max-width: 100%; }
+figure { margin: 1em 0; }
+hr { border: 0; border-top: 1px solid #dfe1e6; }
+.task-list { list-style: none; padding-left: 1.25em; }
+.task-list-item input { margin-right: 0.5em; }
+.panel { border-radius: 3px; margin: 1em 0; padding: 0.75em 1em; background: #f4f5f7; border-left: 4px solid #c1c7d0; }
+.panel-info { background: #deebff; border-color: #0052cc; }
+.panel-tip { background: #e3fcef; border-color: #00875a; }
+.panel-note { background: #eae6ff; border-color: #5243aa; }
+.panel-warning { background: #fffae6; border-color: #ff991f; }
+.panel-title { font-weight: 600; margin-top: 0; }
+.status { display: inline-block; border-radius: 3px; padding: 0 0.4em; font-size: 0.75em; font-weight: 700; text-transform: uppercase; background: #dfe1e6; }
+.status-green { background: #e3fcef; color: #006644; }
+.status-yellow { background: #fffae6; color: #974f0c; }
+.status-red { background: #ffebe6; color: #bf2600; }
+.status-blue { background: #deebff; color: #0747a6; }
+.status-purple { background: #eae6ff; color: #403294; }
+details { margin: 1em 0; }
+summary { cursor: pointer; font-weight: 600; }
+`
+
+// panelMacros are the macros rendered as styled panels.
+var panelMacros = map[string]bool{
+	"info":    true,
+	"tip":     true,
+	"note":    true,
+	"warning": true,
+	"panel":   true,
+}
+
+// RenderHTML converts a Page to semantic HTML5 for publishing outside
+// Confluence.
+//
+// Headings get id attributes for anchors, code is marked with
+// "language-*" classes for client-side highlighters, panels become
+// <aside class="panel panel-info"> and friends, expand macros become
+// <details>, and status macros become <span class="status">. Macros that
+// need Confluence to render (for example toc or jira) are left out unless
+// they have a body, which is rendered in a <div class="macro">.
+func RenderHTML(page *Page, opts HTMLOptions) (string, error) {
+	r := &htmlRenderer{opts: opts, ids: make(map[string]int)}
+	var body string
+	if page != nil {
+		var err error
+		if body, err = r.blocks(page.Blocks); err != nil {
+			return "", err
+		}
+	}
+	if !opts.Standalone {
+		return body, nil
+	}
+
+	var buf strings.Builder
+	buf.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	buf.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	buf.WriteString("<title>" + html.EscapeString(opts.Title) + "</title>\n")
+	if opts.Stylesheet {
+		buf.WriteString("<style>\n" + DefaultStylesheet + "</style>\n")
+	}
+	buf.WriteString("</head>\n<body>\n<article>\n")
+	if opts.Title != "" {
+		buf.WriteString("<h1>" + html.EscapeString(opts.Title) + "</h1>\n")
+	}
+	if body != "" {
+		buf.WriteString(body + "\n")
+	}
+	buf.WriteString("</article>\n</body>\n</html>\n")
+	return buf.String(), nil
+}
+
+type htmlRenderer struct {
+	opts HTMLOptions
+	ids  map[string]int // heading anchors already used
+}
+
+func (r *htmlRenderer) blocks(blocks []Block) (string, error) {
+	parts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		s, err := r.block(block)
+		if err != nil {
+			return "", err
+		}
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, "\n"), nil
+}
+
+func (r *htmlRenderer) block(block Block) (string, error) {
+	switch b := pointerBlock(block).(type) {
+	case *Paragraph:
+		return "<p>" + r.inline(b.Text, b.Spans) + "</p>", nil
+	case *Heading:
+		if b.Level < 1 || b.Level > 6 {
+			return "", fmt.Errorf("invalid heading level: %d", b.Level)
+		}
+		tag := "h" + strconv.Itoa(b.Level)
+		return "<" + tag + ` id="` + r.anchor(b.Text) + `">` + html.EscapeString(b.Text) + "</" + tag + ">", nil
+	case *Table:
+		return r.table(b)
+	case *BulletList:
+		return r.list("ul", b.Items)
+	case *NumberedList:
+		return r.list("ol", b.Items)
+	case *TaskList:
+		return r.taskList(b)
+	case *CodeBlock:
+		return codeHTML(b.Language, b.Code), nil
+	case *HorizontalRule:
+		return "<hr>", nil
+	case *Blockquote:
+		inner, err := r.blocks(b.Blocks)
+		if err != nil {
+			return "", err
+		}
+		return "<blockquote>\n" + inner + "\n</blockquote>", nil
+	case *Image:
+		src := b.URL
+		if b.Attachment != "" {
+			src = b.Attachment
+			if r.opts.ResolveAttachment != nil {
+				src = r.opts.ResolveAttachment(b.Attachment)
+			}
+		}
+		if src == "" {
+			return "", fmt.Errorf("image requires a url or attachment")
+		}
+		return `<figure><img src="` + html.EscapeString(src) + `" alt="` + html.EscapeString(b.Alt) + `"></figure>`, nil
+	case *Macro:
+		if b.Name == "status" {
+			return "<p>" + statusHTML(b) + "</p>", nil
+		}
+		return r.macro(b)
+	default:
+		return "", fmt.Errorf("unsupported block type: %T", block)
+	}
+}
+
+func (r *htmlRenderer) table(t *Table) (string, error) {
+	var buf strings.Builder
+	buf.WriteString("<table>\n")
+	if len(t.Headers) > 0 {
+		buf.WriteString("<thead>\n<tr>")
+		for _, h := range t.Headers {
+			buf.WriteString("<th>" + html.EscapeString(h) + "</th>")
+		}
+		buf.WriteString("</tr>\n</thead>\n")
+	}
+	buf.WriteString("<tbody>\n")
+	for _, row := range t.Rows {
+		buf.WriteString("<tr>")
+		for _, cell := range row.Cells {
+			content := html.EscapeString(cell.Text)
+			if cell.Macro != nil {
+				var err error
+				if content, err = r.cellMacro(cell.Macro); err != nil {
+					return "", err
+				}
+			}
+			buf.WriteString("<td>" + content + "</td>")
+		}
+		buf.WriteString("</tr>\n")
+	}
+	buf.WriteString("</tbody>\n</table>")
+	return buf.String(), nil
+}
+
+func (r *htmlRenderer) cellMacro(m *Macro) (string, error) {
+	if m.Name == "status" {
+		return statusHTML(m), nil
+	}
+	return r.macro(m)
+}
+
+func (r *htmlRenderer) list(tag string, items []ListItem) (string, error) {
+	var buf strings.Builder
+	buf.WriteString("<" + tag + ">\n")
+	for _, item := range items {
+		children, err := r.children(item.Children)
+		if err != nil {
+			return "", err
+		}
+		buf.WriteString("<li>" + r.inline(item.Text, item.Spans) + children + "</li>\n")
+	}
+	buf.WriteString("</" + tag + ">")
+	return buf.String(), nil
+}
+
+func (r *htmlRenderer) taskList(tl *TaskList) (string, error) {
+	var buf strings.Builder
+	buf.WriteString("<ul class=\"task-list\">\n")
+	for _, item := range tl.Items {
+		children, err := r.children(item.Children)
+		if err != nil {
+			return "", err
+		}
+		box := `<input type="checkbox" disabled>`
+		if item.Done {
+			box = `<input type="checkbox" disabled checked>`
+		}
+		buf.WriteString(`<li class="task-list-item">` + box + r.inline(item.Text, item.Spans) + children + "</li>\n")
+	}
+	buf.WriteString("</ul>")
+	return buf.String(), nil
+}
+
+func (r *htmlRenderer) children(blocks []Block) (string, error) {
+	if len(blocks) == 0 {
+		return "", nil
+	}
+	inner, err := r.blocks(blocks)
+	if err != nil {
+		return "", err
+	}
+	return "\n" + inner + "\n", nil
+}
+
+func (r *htmlRenderer) macro(m *Macro) (string, error) {
+	if m.Name == "code" || m.Name == "noformat" {
+		return codeHTML(m.Params["language"], m.Body), nil
+	}
+
+	body, err := r.macroBody(m)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case panelMacros[m.Name]:
+		var buf strings.Builder
+		buf.WriteString(`<aside class="panel panel-` + m.Name + `">` + "\n")
+		if title := m.Params["title"]; title != "" {
+			buf.WriteString(`<p class="panel-title">` + html.EscapeString(title) + "</p>\n")
+		}
+		if body != "" {
+			buf.WriteString(body + "\n")
+		}
+		buf.WriteString("</aside>")
+		return buf.String(), nil
+	case m.Name == "expand":
+		title := m.Params["title"]
+		if title == "" {
+			title = "Details"
+		}
+		return "<details>\n<summary>" + html.EscapeString(title) + "</summary>\n" + body + "\n</details>", nil
+	case body != "":
+		return `<div class="macro macro-` + html.EscapeString(m.Name) + `">` + "\n" + body + "\n</div>", nil
+	}
+	return "", nil
+}
+
+// macroBody renders a macro's rich-text body. Bodies that are not valid
+// Storage XHTML are rendered as a plain-text paragraph.
+func (r *htmlRenderer) macroBody(m *Macro) (string, error) {
+	if m.Body == "" {
+		return "", nil
+	}
+	body, err := Parse(m.Body)
+	if err != nil || len(body.Blocks) == 0 {
+		return "<p>" + html.EscapeString(m.Body) + "</p>", nil
+	}
+	return r.blocks(body.Blocks)
+}
+
+func (r *htmlRenderer) inline(text string, spans []Span) string {
+	spans = matchingSpans(text, spans)
+	if len(spans) == 0 {
+		return html.EscapeString(text)
+	}
+	var buf strings.Builder
+	for _, s := range spans {
+		out := html.EscapeString(s.Text)
+		if s.Code {
+			out = "<code>" + out + "</code>"
+		}
+		if s.Strike {
+			out = "<s>" + out + "</s>"
+		}
+		if s.Italic {
+			out = "<em>" + out + "</em>"
+		}
+		if s.Bold {
+			out = "<strong>" + out + "</strong>"
+		}
+		href := s.Link
+		if s.Page != "" && r.opts.ResolvePage != nil {
+			href = r.opts.ResolvePage(s.Space, s.Page)
+		}
+		if href != "" {
+			out = `<a href="` + html.EscapeString(href) + `">` + out + "</a>"
+		}
+		buf.WriteString(out)
+	}
+	return buf.String()
+}
+
+// anchor returns a unique id for a heading, derived from its text.
+func (r *htmlRenderer) anchor(text string) string {
+	var buf strings.Builder
+	dash := false
+	for _, c := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(c) || unicode.IsDigit(c):
+			if dash && buf.Len() > 0 {
+				buf.WriteByte('-')
+			}
+			buf.WriteRune(c)
+			dash = false
+		default:
+			dash = true
+		}
+	}
+	id := buf.String()
+	if id == "" {
+		id = "section"
+	}
+	n := r.ids[id]
+	r.ids[id] = n + 1
+	if n > 0 {
+		id += "-" + strconv.Itoa(n)
+	}
+	return html.EscapeString(id)
+}
+
+func statusHTML(m *Macro) string {
+	colour := strings.ToLower(m.Params["colour"])
+	if colour == "" {
+		colour = "grey"
+	}
+	return `<span class="status status-` + html.EscapeString(colour) + `">` + html.EscapeString(m.Params["title"]) + "</span>"
+}
+
+func codeHTML(language, code string) string {
+	class := ""
+	if language != "" {
+		class = ` class="language-` + html.EscapeString(language) + `"`
+	}
+	return "<pre><code" + class + ">" + html.EscapeString(code) + "</code></pre>"
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderHTML(t *testing.T) {
+	tests := []struct {
+		name   string
+		blocks []Block
+		opts   HTMLOptions
+		want   string
+	}{
+		{
+			name: "headings get unique anchors",
+			blocks: []Block{
+				&Heading{Level: 1, Text: "Getting Started"},
+				&Heading{Level: 2, Text: "getting started!"},
+				&Paragraph{Text: "a < b"},
+			},
+			want: `<h1 id="getting-started">Getting Started</h1>` + "\n" +
+				`<h2 id="getting-started-1">getting started!</h2>` + "\n" +
+				"<p>a &lt; b</p>",
+		},
+		{
+			name: "spans and resolved links",
+			blocks: []Block{&Paragraph{Spans: []Span{
+				{Text: "See "},
+				{Text: "Setup", Bold: true, Page: "Setup Guide"},
+				{Text: " and "},
+				{Text: "Other", Page: "Elsewhere", Space: "OPS"},
+				{Text: " or "},
+				{Text: "docs", Link: "https://example.com"},
+			}}},
+			opts: HTMLOptions{ResolvePage: func(space, title string) string {
+				if space != "" {
+					return ""
+				}
+				return strings.ReplaceAll(strings.ToLower(title), " ", "-") + ".html"
+			}},
+			want: `<p>See <a href="setup-guide.html"><strong>Setup</strong></a> and Other or <a href="https://example.com">docs</a></p>`,
+		},
+		{
+			name:   "code block with language class",
+			blocks: []Block{&CodeBlock{Language: "go", Code: "if a < b {}"}},
+			want:   `<pre><code class="language-go">if a &lt; b {}</code></pre>`,
+		},
+		{
+			name: "table with status",
+			blocks: []Block{&Table{
+				Headers: []string{"Service", "State"},
+				Rows: []Row{{Cells: []Cell{
+					{Text: "API"},
+					{Macro: &Macro{Name: "status", Params: map[string]string{"colour": "Green", "title": "OK"}}},
+				}}},
+			}},
+			want: "<table>\n<thead>\n<tr><th>Service</th><th>State</th></tr>\n</thead>\n<tbody>\n" +
+				`<tr><td>API</td><td><span class="status status-green">OK</span></td></tr>` + "\n</tbody>\n</table>",
+		},
+		{
+			name: "task list with nested list",
+			blocks: []Block{&TaskList{Items: []TaskItem{
+				{Text: "done", Done: true},
+				{Text: "open", Children: []Block{&BulletList{Items: []ListItem{{Text: "sub"}}}}},
+			}}},
+			want: "<ul class=\"task-list\">\n" +
+				`<li class="task-list-item"><input type="checkbox" disabled checked>done</li>` + "\n" +
+				`<li class="task-list-item"><input type="checkbox" disabled>open` + "\n<ul>\n<li>sub</li>\n</ul>\n</li>\n</ul>",
+		},
+		{
+			name:   "attachment image",
+			blocks: []Block{&Image{Attachment: "arch diagram.png", Alt: "Architecture"}},
+			opts:   HTMLOptions{ResolveAttachment: func(name string) string { return "/files/" + name }},
+			want:   `<figure><img src="/files/arch diagram.png" alt="Architecture"></figure>`,
+		},
+		{
+			name:   "warning panel",
+			blocks: []Block{&Macro{Name: "warning", Params: map[string]string{"title": "Careful"}, Body: "<p>Back up <strong>first</strong>.</p>"}},
+			want:   "<aside class=\"panel panel-warning\">\n<p class=\"panel-title\">Careful</p>\n<p>Back up <strong>first</strong>.</p>\n</aside>",
+		},
+		{
+			name:   "expand macro",
+			blocks: []Block{&Macro{Name: "expand", Body: "More"}},
+			want:   "<details>\n<summary>Details</summary>\n<p>More</p>\n</details>",
+		},
+		{
+			name:   "macros needing Confluence are dropped",
+			blocks: []Block{&Macro{Name: "toc"}, &Paragraph{Text: "after"}},
+			want:   "<p>after</p>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderHTML(&Page{Blocks: tt.blocks}, tt.opts)
+			if err != nil {
+				t.Fatalf("RenderHTML() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RenderHTML() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderHTMLStandalone(t *testing.T) {
+	page := &Page{Blocks: []Block{&Paragraph{Text: "Hello"}}}
+
+	got, err := RenderHTML(page, HTMLOptions{Standalone: true, Title: "Notes & Tips", Stylesheet: true})
+	if err != nil {
+		t.Fatalf("RenderHTML() error = %v", err)
+	}
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<title>Notes &amp; Tips</title>",
+		"<style>\n" + DefaultStylesheet + "</style>",
+		"<article>\n<h1>Notes &amp; Tips</h1>\n<p>Hello</p>\n</article>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("RenderHTML() missing %q in:\n%s", want, got)
+		}
+	}
+
+	got, err = RenderHTML(page, HTMLOptions{Standalone: true})
+	if err != nil {
+		t.Fatalf("RenderHTML() error = %v", err)
+	}
+	if strings.Contains(got, "<style>") {
+		t.Error("RenderHTML() embedded a stylesheet without Stylesheet set")
+	}
+}
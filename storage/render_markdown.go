@@ -0,0 +1,410 @@
+package storage
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// calloutMacros maps panel macros to the GitHub alert they are rendered as.
+var calloutMacros = map[string]string{
+	"info":    "NOTE",
+	"tip":     "TIP",
+	"note":    "IMPORTANT",
+	"warning": "WARNING",
+	"panel":   "",
+}
+
+// RenderMarkdown converts a Page to GitHub-flavoured Markdown.
+//
+// Macros without a Markdown equivalent fall back as follows: info, tip,
+// note and warning panels become blockquote callouts ("> [!NOTE]"), code
+// macros become fenced code, status macros become bold labels, expand
+// macros become their title followed by their body, and any other macro is
+// kept verbatim as Storage XHTML in a fenced block tagged
+// "confluence-macro". Links to Confluence pages point at the page title as
+// a relative URL, under "../SPACE/" for pages in another space.
+func RenderMarkdown(page *Page) (string, error) {
+	if page == nil {
+		return "", nil
+	}
+	parts := make([]string, 0, len(page.Blocks))
+	for _, block := range page.Blocks {
+		s, err := RenderBlockMarkdown(block)
+		if err != nil {
+			return "", err
+		}
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	if len(parts) == 0 {
+		return "", nil
+	}
+	return strings.Join(parts, "\n\n") + "\n", nil
+}
+
+// RenderBlockMarkdown converts a single Block to Markdown, without a
+// trailing newline.
+func RenderBlockMarkdown(block Block) (string, error) {
+	switch b := pointerBlock(block).(type) {
+	case *Paragraph:
+		return markdownLines(renderMarkdownInline(b.Text, b.Spans)), nil
+	case *Heading:
+		if b.Level < 1 || b.Level > 6 {
+			return "", fmt.Errorf("invalid heading level: %d", b.Level)
+		}
+		return strings.Repeat("#", b.Level) + " " + escapeMarkdown(b.Text), nil
+	case *Table:
+		return renderTableMarkdown(b), nil
+	case *BulletList:
+		return renderItemsMarkdown(b.Items, func(int) string { return "- " })
+	case *NumberedList:
+		return renderItemsMarkdown(b.Items, func(i int) string { return strconv.Itoa(i+1) + ". " })
+	case *TaskList:
+		return renderTasksMarkdown(b)
+	case *CodeBlock:
+		return fencedBlock(b.Language, b.Code), nil
+	case *HorizontalRule:
+		return "---", nil
+	case *Blockquote:
+		inner, err := RenderMarkdown(&Page{Blocks: b.Blocks})
+		if err != nil {
+			return "", err
+		}
+		return quoteMarkdown(strings.TrimSuffix(inner, "\n")), nil
+	case *Image:
+		src := b.URL
+		if b.Attachment != "" {
+			src = b.Attachment
+		}
+		return "![" + escapeMarkdown(b.Alt) + "](" + markdownDestination(src) + ")", nil
+	case *Macro:
+		return renderMacroMarkdown(b)
+	default:
+		return "", fmt.Errorf("unsupported block type: %T", block)
+	}
+}
+
+func renderMacroMarkdown(m *Macro) (string, error) {
+	switch {
+	case m.Name == "code" || m.Name == "noformat":
+		return fencedBlock(m.Params["language"], m.Body), nil
+	case m.Name == "status":
+		return "**[" + escapeMarkdown(m.Params["title"]) + "]**", nil
+	case m.Name == "expand":
+		body, err := macroBodyMarkdown(m)
+		if err != nil {
+			return "", err
+		}
+		title := m.Params["title"]
+		if title == "" {
+			title = "Details"
+		}
+		return strings.TrimSuffix("**"+escapeMarkdown(title)+"**\n\n"+body, "\n\n"), nil
+	}
+
+	if alert, ok := calloutMacros[m.Name]; ok {
+		body, err := macroBodyMarkdown(m)
+		if err != nil {
+			return "", err
+		}
+		var lines []string
+		if alert != "" {
+			lines = append(lines, "[!"+alert+"]")
+		}
+		if title := m.Params["title"]; title != "" {
+			lines = append(lines, "**"+escapeMarkdown(title)+"**")
+		}
+		if body != "" {
+			if len(lines) > 0 && alert == "" {
+				lines = append(lines, "")
+			}
+			lines = append(lines, body)
+		}
+		return quoteMarkdown(strings.Join(lines, "\n")), nil
+	}
+
+	xhtml, err := RenderMacro(m)
+	if err != nil {
+		return "", err
+	}
+	return fencedBlock("confluence-macro", xhtml), nil
+}
+
+// macroBodyMarkdown renders a macro's rich-text body. Bodies that are not
+// valid Storage XHTML are kept as plain text.
+func macroBodyMarkdown(m *Macro) (string, error) {
+	if m.Body == "" {
+		return "", nil
+	}
+	body, err := Parse(m.Body)
+	if err != nil || len(body.Blocks) == 0 {
+		// Not Storage XHTML; keep the body as plain text.
+		return escapeMarkdown(m.Body), nil
+	}
+	md, err := RenderMarkdown(body)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(md, "\n"), nil
+}
+
+func renderTableMarkdown(t *Table) string {
+	columns := len(t.Headers)
+	for _, row := range t.Rows {
+		columns = max(columns, len(row.Cells))
+	}
+	if columns == 0 {
+		return ""
+	}
+
+	headers := make([]string, columns)
+	for i, h := range t.Headers {
+		headers[i] = tableCellMarkdown(h)
+	}
+	lines := []string{tableRowMarkdown(headers)}
+
+	separator := make([]string, columns)
+	for i := range separator {
+		separator[i] = "---"
+	}
+	lines = append(lines, tableRowMarkdown(separator))
+
+	for _, row := range t.Rows {
+		cells := make([]string, columns)
+		for i, cell := range row.Cells {
+			cells[i] = cellMarkdown(cell)
+		}
+		lines = append(lines, tableRowMarkdown(cells))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func cellMarkdown(c Cell) string {
+	if c.Macro == nil {
+		return tableCellMarkdown(c.Text)
+	}
+	if c.Macro.Name == "status" {
+		return "**[" + tableCellMarkdown(c.Macro.Params["title"]) + "]**"
+	}
+	keys := make([]string, 0, len(c.Macro.Params))
+	for key := range c.Macro.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	params := make([]string, len(keys))
+	for i, key := range keys {
+		params[i] = key + "=" + c.Macro.Params[key]
+	}
+	return "`" + strings.TrimSpace(c.Macro.Name+" "+strings.Join(params, " ")) + "`"
+}
+
+func tableCellMarkdown(text string) string {
+	text = strings.ReplaceAll(escapeMarkdown(text), "|", `\|`)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func tableRowMarkdown(cells []string) string {
+	return "| " + strings.Join(cells, " | ") + " |"
+}
+
+func renderItemsMarkdown(items []ListItem, marker func(int) string) (string, error) {
+	lines := make([]string, 0, len(items))
+	for i, item := range items {
+		entry, err := itemMarkdown(marker(i), renderMarkdownInline(item.Text, item.Spans), item.Children)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, entry)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func renderTasksMarkdown(tl *TaskList) (string, error) {
+	lines := make([]string, 0, len(tl.Items))
+	for _, item := range tl.Items {
+		box := "[ ] "
+		if item.Done {
+			box = "[x] "
+		}
+		entry, err := itemMarkdown("- ", box+renderMarkdownInline(item.Text, item.Spans), item.Children)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, entry)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// itemMarkdown renders a list item, indenting continuation lines and nested
+// blocks to the item's content column.
+func itemMarkdown(marker, text string, children []Block) (string, error) {
+	indent := strings.Repeat(" ", len(marker))
+	lines := strings.Split(markdownLines(text), "\n")
+	for i := 1; i < len(lines); i++ {
+		lines[i] = indent + lines[i]
+	}
+	for _, child := range children {
+		md, err := RenderBlockMarkdown(child)
+		if err != nil {
+			return "", err
+		}
+		for _, line := range strings.Split(md, "\n") {
+			if line == "" {
+				lines = append(lines, "")
+			} else {
+				lines = append(lines, indent+line)
+			}
+		}
+	}
+	return marker + strings.Join(lines, "\n"), nil
+}
+
+func quoteMarkdown(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = ">"
+		} else {
+			lines[i] = "> " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fencedBlock wraps code in a fence longer than any backtick run it contains.
+func fencedBlock(info, code string) string {
+	fence := strings.Repeat("`", max(3, longestBacktickRun(code)+1))
+	return fence + info + "\n" + code + "\n" + fence
+}
+
+// renderMarkdownInline renders spans when present and the escaped plain
+// text otherwise.
+func renderMarkdownInline(text string, spans []Span) string {
+	spans = matchingSpans(text, spans)
+	if len(spans) == 0 {
+		return escapeMarkdown(text)
+	}
+	var buf strings.Builder
+	for _, span := range spans {
+		buf.WriteString(spanMarkdown(span))
+	}
+	return buf.String()
+}
+
+func spanMarkdown(s Span) string {
+	// Emphasis delimiters must touch non-space text, so surrounding
+	// whitespace stays outside the marks.
+	core := strings.TrimSpace(s.Text)
+	if core == "" {
+		return s.Text
+	}
+	lead := s.Text[:strings.Index(s.Text, core)]
+	trail := s.Text[len(lead)+len(core):]
+
+	out := escapeMarkdown(core)
+	if s.Code {
+		out = codeSpan(core)
+	}
+	if s.Strike {
+		out = "~~" + out + "~~"
+	}
+	if s.Italic {
+		out = "*" + out + "*"
+	}
+	if s.Bold {
+		out = "**" + out + "**"
+	}
+	if s.Link != "" {
+		out = "[" + out + "](" + markdownDestination(s.Link) + ")"
+	} else if s.Page != "" {
+		out = "[" + out + "](" + pageDestination(s.Space, s.Page) + ")"
+	}
+	return lead + out + trail
+}
+
+// pageDestination returns the link destination of a Confluence page link,
+// so that the target survives export.
+func pageDestination(space, title string) string {
+	dest := url.PathEscape(title)
+	if space != "" {
+		dest = "../" + url.PathEscape(space) + "/" + dest
+	}
+	return dest
+}
+
+func codeSpan(code string) string {
+	fence := strings.Repeat("`", longestBacktickRun(code)+1)
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+		return fence + " " + code + " " + fence
+	}
+	return fence + code + fence
+}
+
+func longestBacktickRun(s string) int {
+	longest := 0
+	for i := 0; i < len(s); {
+		if s[i] != '`' {
+			i++
+			continue
+		}
+		n := runLength(s, i, '`')
+		longest = max(longest, n)
+		i += n
+	}
+	return longest
+}
+
+func markdownDestination(url string) string {
+	if strings.ContainsAny(url, " ()") {
+		return "<" + url + ">"
+	}
+	return url
+}
+
+// escapeMarkdown escapes characters that would otherwise be read as inline
+// Markdown syntax. Underscores inside words are left alone.
+func escapeMarkdown(text string) string {
+	var buf strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch c {
+		case '\\', '`', '*', '[', ']', '<', '~':
+			buf.WriteByte('\\')
+		case '_':
+			if i == 0 || i == len(text)-1 || !isWordByte(text[i-1]) || !isWordByte(text[i+1]) {
+				buf.WriteByte('\\')
+			}
+		case '&':
+			if strings.IndexByte(text[i:], ';') > 0 {
+				buf.WriteByte('\\')
+			}
+		}
+		buf.WriteByte(c)
+	}
+	return buf.String()
+}
+
+// markdownLines escapes characters at the start of each line that would
+// otherwise begin a block (headings, quotes, lists, rules).
+func markdownLines(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		switch {
+		case trimmed == "":
+		case strings.ContainsRune("#>-+=|", rune(trimmed[0])):
+			lines[i] = `\` + trimmed
+		case listItemPattern.MatchString(trimmed):
+			m := listItemPattern.FindStringSubmatch(trimmed)
+			// Escape the delimiter of ordered markers like "1." or "2)".
+			lines[i] = m[2][:len(m[2])-1] + `\` + trimmed[len(m[2])-1:]
+		default:
+			lines[i] = trimmed
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name   string
+		blocks []Block
+		want   string
+	}{
+		{
+			name: "heading and paragraph",
+			blocks: []Block{
+				&Heading{Level: 2, Text: "Overview"},
+				&Paragraph{Text: "Plain *text* with snake_case"},
+			},
+			want: "## Overview\n\nPlain \\*text\\* with snake_case\n",
+		},
+		{
+			name: "paragraph spans",
+			blocks: []Block{&Paragraph{Spans: []Span{
+				{Text: "See "},
+				{Text: "docs ", Bold: true, Link: "https://example.com"},
+				{Text: "and "},
+				{Text: "x`y", Code: true},
+				{Text: " "},
+				{Text: "old", Strike: true, Italic: true},
+			}}},
+			want: "See [**docs**](https://example.com) and ``x`y`` *~~old~~*\n",
+		},
+		{
+			name: "page links",
+			blocks: []Block{&Paragraph{Spans: []Span{
+				{Text: "See "},
+				{Text: "the design", Page: "Design"},
+				{Text: " and "},
+				{Text: "runbook", Bold: true, Page: "Deploy (prod)", Space: "OPS"},
+			}}},
+			want: "See [the design](Design) and [**runbook**](../OPS/Deploy%20%28prod%29)\n",
+		},
+		{
+			name:   "line starts are escaped",
+			blocks: []Block{&Paragraph{Text: "# not a heading\n1. not a list\n- nor this"}},
+			want:   "\\# not a heading\n1\\. not a list\n\\- nor this\n",
+		},
+		{
+			name: "nested lists",
+			blocks: []Block{&BulletList{Items: []ListItem{
+				{Text: "one"},
+				{Text: "two", Children: []Block{&NumberedList{Items: []ListItem{{Text: "a"}, {Text: "b"}}}}},
+			}}},
+			want: "- one\n- two\n  1. a\n  2. b\n",
+		},
+		{
+			name: "task list",
+			blocks: []Block{&TaskList{Items: []TaskItem{
+				{Text: "done", Done: true},
+				{Text: "open", Children: []Block{&BulletList{Items: []ListItem{{Text: "sub"}}}}},
+			}}},
+			want: "- [x] done\n- [ ] open\n  - sub\n",
+		},
+		{
+			name: "table",
+			blocks: []Block{&Table{
+				Headers: []string{"Name", "Status"},
+				Rows: []Row{
+					{Cells: []Cell{{Text: "a|b"}, {Macro: &Macro{Name: "status", Params: map[string]string{"title": "OK"}}}}},
+					{Cells: []Cell{{Text: "c"}}},
+				},
+			}},
+			want: "| Name | Status |\n| --- | --- |\n| a\\|b | **[OK]** |\n| c |  |\n",
+		},
+		{
+			name:   "code block with backticks",
+			blocks: []Block{&CodeBlock{Language: "md", Code: "```go\nx\n```"}},
+			want:   "````md\n```go\nx\n```\n````\n",
+		},
+		{
+			name:   "blockquote and image",
+			blocks: []Block{&Blockquote{Blocks: []Block{&Paragraph{Text: "a"}, &Paragraph{Text: "b"}}}, &Image{Attachment: "x.png", Alt: "X"}},
+			want:   "> a\n>\n> b\n\n![X](x.png)\n",
+		},
+		{
+			name:   "info panel callout",
+			blocks: []Block{&Macro{Name: "info", Params: map[string]string{"title": "Heads up"}, Body: "<p>Read this.</p>"}},
+			want:   "> [!NOTE]\n> **Heads up**\n> Read this.\n",
+		},
+		{
+			name:   "warning panel with plain body",
+			blocks: []Block{&Macro{Name: "warning", Body: "Careful"}},
+			want:   "> [!WARNING]\n> Careful\n",
+		},
+		{
+			name:   "code macro",
+			blocks: []Block{&Macro{Name: "code", Params: map[string]string{"language": "sql"}, Body: "SELECT 1"}},
+			want:   "```sql\nSELECT 1\n```\n",
+		},
+		{
+			name:   "unknown macro",
+			blocks: []Block{&Macro{Name: "toc", Params: map[string]string{"maxLevel": "2"}}},
+			want:   "```confluence-macro\n<ac:structured-macro ac:name=\"toc\"><ac:parameter ac:name=\"maxLevel\">2</ac:parameter></ac:structured-macro>\n```\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderMarkdown(&Page{Blocks: tt.blocks})
+			if err != nil {
+				t.Fatalf("RenderMarkdown() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RenderMarkdown() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMarkdownRoundTrip(t *testing.T) {
+	inputs := []string{
+		"# Title\n\nSome **bold** and *italic* and `code` with [a link](https://example.com).",
+		"- one\n- two\n  - nested\n    1. deep",
+		"- [ ] todo\n- [x] done",
+		"| A | B |\n|---|---|\n| 1 | 2 |",
+		"```go\nfmt.Println(\"hi\")\n```",
+		"> quoted\n>\n> - item",
+		"Literal \\*stars\\* and 1\\. dots",
+		"![Diagram](arch.png)\n\n---",
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			want, err := Render(ParseMarkdown(input))
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			md, err := RenderMarkdown(ParseMarkdown(input))
+			if err != nil {
+				t.Fatalf("RenderMarkdown() error = %v", err)
+			}
+			got, err := Render(ParseMarkdown(md))
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got != want {
+				t.Errorf("round trip through\n%s\n got = %s\nwant = %s", md, got, want)
+			}
+		})
+	}
+}
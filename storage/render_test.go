@@ -101,6 +101,24 @@ func TestRenderParagraph(t *testing.T) {
 			want:    "<p>Hello &lt;World&gt; &amp; &#34;Friends&#34;</p>",
 			wantErr: false,
 		},
+		{
+			name:    "paragraph with matching spans",
+			p:       &Paragraph{Text: "Hello, World!", Spans: []Span{{Text: "Hello, "}, {Text: "World", Bold: true}, {Text: "!"}}},
+			want:    "<p>Hello, <strong>World</strong>!</p>",
+			wantErr: false,
+		},
+		{
+			name:    "text wins over stale spans",
+			p:       &Paragraph{Text: "Goodbye, World!", Spans: []Span{{Text: "Hello, "}, {Text: "World", Bold: true}, {Text: "!"}}},
+			want:    "<p>Goodbye, World!</p>",
+			wantErr: false,
+		},
+		{
+			name:    "spans without text",
+			p:       &Paragraph{Spans: []Span{{Text: "Hi", Italic: true}}},
+			want:    "<p><em>Hi</em></p>",
+			wantErr: false,
+		},
 		{
 			name:    "empty paragraph",
 			p:       &Paragraph{Text: ""},
@@ -234,6 +252,17 @@ func TestRenderMacro(t *testing.T) {
 				`<ac:parameter ac:name="language">go</ac:parameter>`,
 			},
 		},
+		{
+			name: "code macro with body",
+			m: &Macro{
+				Name: "code",
+				Body: "if a < b {}",
+			},
+			wantErr: false,
+			contains: []string{
+				`<ac:plain-text-body><![CDATA[if a < b {}]]></ac:plain-text-body>`,
+			},
+		},
 		{
 			name:     "nil macro",
 			m:        nil,
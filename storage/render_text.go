@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"strconv"
+	"strings"
+)
+
+// RenderText converts a Page to plain text for search indexes and language
+// models. Formatting and markup are dropped; structure is kept with
+// indentation, list markers and " | "-separated table cells. Macros are
+// reduced to their text: status macros to "[TITLE]", panels and expand
+// macros to their title and body, and macros without a body are omitted.
+func RenderText(page *Page) string {
+	if page == nil {
+		return ""
+	}
+	parts := make([]string, 0, len(page.Blocks))
+	for _, block := range page.Blocks {
+		if s := RenderBlockText(block); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.Join(parts, "\n\n") + "\n"
+}
+
+// RenderBlockText converts a single Block to plain text, without a trailing
+// newline.
+func RenderBlockText(block Block) string {
+	switch b := pointerBlock(block).(type) {
+	case *Paragraph:
+		return b.Text
+	case *Heading:
+		return b.Text
+	case *Table:
+		return tableText(b)
+	case *BulletList:
+		return itemsText(b.Items, func(int) string { return "- " })
+	case *NumberedList:
+		return itemsText(b.Items, func(i int) string { return strconv.Itoa(i+1) + ". " })
+	case *TaskList:
+		lines := make([]string, 0, len(b.Items))
+		for _, item := range b.Items {
+			box := "[ ] "
+			if item.Done {
+				box = "[x] "
+			}
+			lines = append(lines, itemText(box, item.Text, item.Children))
+		}
+		return strings.Join(lines, "\n")
+	case *CodeBlock:
+		return b.Code
+	case *HorizontalRule:
+		return "---"
+	case *Blockquote:
+		inner := strings.TrimSuffix(RenderText(&Page{Blocks: b.Blocks}), "\n")
+		return indentText(inner, "> ")
+	case *Image:
+		if b.Alt != "" {
+			return "[image: " + b.Alt + "]"
+		}
+		return "[image]"
+	case *Macro:
+		return macroText(b)
+	default:
+		return ""
+	}
+}
+
+func macroText(m *Macro) string {
+	switch m.Name {
+	case "status":
+		return "[" + strings.ToUpper(m.Params["title"]) + "]"
+	case "code", "noformat":
+		return m.Body
+	}
+
+	body := m.Body
+	if page, err := Parse(m.Body); err == nil && len(page.Blocks) > 0 {
+		body = strings.TrimSuffix(RenderText(page), "\n")
+	}
+	if title := m.Params["title"]; title != "" {
+		return strings.TrimSuffix(title+"\n"+body, "\n")
+	}
+	return body
+}
+
+func tableText(t *Table) string {
+	var lines []string
+	if len(t.Headers) > 0 {
+		lines = append(lines, strings.Join(t.Headers, " | "))
+	}
+	for _, row := range t.Rows {
+		cells := make([]string, len(row.Cells))
+		for i, cell := range row.Cells {
+			cells[i] = cell.Text
+			if cell.Macro != nil {
+				cells[i] = macroText(cell.Macro)
+			}
+		}
+		lines = append(lines, strings.Join(cells, " | "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func itemsText(items []ListItem, marker func(int) string) string {
+	lines := make([]string, 0, len(items))
+	for i, item := range items {
+		lines = append(lines, itemText(marker(i), item.Text, item.Children))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// itemText renders a list item with its nested blocks indented under it.
+func itemText(marker, text string, children []Block) string {
+	var buf strings.Builder
+	buf.WriteString(marker + text)
+	for _, child := range children {
+		if s := RenderBlockText(child); s != "" {
+			buf.WriteString("\n" + indentText(s, "  "))
+		}
+	}
+	return buf.String()
+}
+
+func indentText(text, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		} else {
+			lines[i] = strings.TrimRight(prefix, " ")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
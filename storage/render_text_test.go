@@ -0,0 +1,44 @@
+package storage
+
+import "testing"
+
+func TestRenderText(t *testing.T) {
+	page := &Page{Blocks: []Block{
+		&Heading{Level: 1, Text: "Release Notes"},
+		&Paragraph{Text: "Some bold text", Spans: []Span{{Text: "Some "}, {Text: "bold", Bold: true}, {Text: " text"}}},
+		&Table{
+			Headers: []string{"Service", "State"},
+			Rows: []Row{{Cells: []Cell{
+				{Text: "API"},
+				{Macro: &Macro{Name: "status", Params: map[string]string{"title": "ok"}}},
+			}}},
+		},
+		&BulletList{Items: []ListItem{
+			{Text: "one", Children: []Block{&NumberedList{Items: []ListItem{{Text: "a"}}}}},
+			{Text: "two"},
+		}},
+		&TaskList{Items: []TaskItem{{Text: "ship", Done: true}, {Text: "announce"}}},
+		&Blockquote{Blocks: []Block{&Paragraph{Text: "quoted"}, &Paragraph{Text: "twice"}}},
+		&Macro{Name: "info", Params: map[string]string{"title": "Note"}, Body: "<p>Read <em>this</em>.</p>"},
+		&Macro{Name: "toc"},
+		&CodeBlock{Code: "make build"},
+		&Image{Alt: "Diagram", URL: "https://example.com/d.png"},
+	}}
+
+	want := "Release Notes\n\n" +
+		"Some bold text\n\n" +
+		"Service | State\nAPI | [OK]\n\n" +
+		"- one\n  1. a\n- two\n\n" +
+		"[x] ship\n[ ] announce\n\n" +
+		"> quoted\n>\n> twice\n\n" +
+		"Note\nRead this.\n\n" +
+		"make build\n\n" +
+		"[image: Diagram]\n"
+
+	if got := RenderText(page); got != want {
+		t.Errorf("RenderText() =\n%q\nwant\n%q", got, want)
+	}
+	if got := RenderText(&Page{}); got != "" {
+		t.Errorf("RenderText(empty) = %q, want empty", got)
+	}
+}
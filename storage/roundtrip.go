@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LossError reports Storage XHTML that Parse and Render would not
+// reproduce, so that publishing an edit made through the block model would
+// delete content from the page.
+type LossError struct {
+	// Elements lists the elements that would be dropped, such as
+	// "ac:layout" or "span". It is empty when only attributes or text
+	// would change.
+	Elements []string
+}
+
+func (e *LossError) Error() string {
+	if len(e.Elements) == 0 {
+		return "page content does not survive parsing and rendering unchanged (attributes or text differ)"
+	}
+	return fmt.Sprintf("page contains markup that would be lost on re-rendering: %s", strings.Join(e.Elements, ", "))
+}
+
+// CheckRoundTrip reports whether xhtml survives Parse and Render unchanged,
+// comparing both in normalized form. Content is only safe to edit through
+// the block model and republish when it does; otherwise CheckRoundTrip
+// returns a *LossError.
+func CheckRoundTrip(xhtml string) error {
+	page, err := Parse(xhtml)
+	if err != nil {
+		return err
+	}
+	rendered, err := Render(page)
+	if err != nil {
+		return err
+	}
+	want, err := Normalize(xhtml)
+	if err != nil {
+		return err
+	}
+	got, err := Normalize(rendered)
+	if err != nil {
+		return err
+	}
+	if got == want {
+		return nil
+	}
+
+	// Name the elements that occur less often after the round trip.
+	wantTree, err := parseTree(xhtml)
+	if err != nil {
+		return err
+	}
+	gotTree, err := parseTree(rendered)
+	if err != nil {
+		return err
+	}
+	wantCounts, gotCounts := map[string]int{}, map[string]int{}
+	countElements(wantTree, wantCounts)
+	countElements(gotTree, gotCounts)
+	lossErr := &LossError{}
+	for name, n := range wantCounts {
+		if gotCounts[name] < n {
+			lossErr.Elements = append(lossErr.Elements, name)
+		}
+	}
+	sort.Strings(lossErr.Elements)
+	return lossErr
+}
+
+// countElements counts the elements below n by qualified name.
+func countElements(n *xnode, counts map[string]int) {
+	for _, child := range n.children {
+		if !child.isText() {
+			counts[child.name]++
+			countElements(child, counts)
+		}
+	}
+}
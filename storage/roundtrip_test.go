@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCheckRoundTrip(t *testing.T) {
+	safe := []string{
+		`<h1>Overview</h1><p>Some <strong>bold</strong> and <em>italic</em> text with <a href="https://example.com">a link</a>.</p>`,
+		`<table><tbody><tr><th><p>Name</p></th><th><p>Value</p></th></tr><tr><td><p>a</p></td><td>b</td></tr></tbody></table>`,
+		`<ul><li><p>one</p><ul><li>nested</li></ul></li><li>two</li></ul>`,
+		`<ac:structured-macro ac:name="info" ac:macro-id="x1"><ac:rich-text-body><p>Note</p></ac:rich-text-body></ac:structured-macro>`,
+		`<ac:structured-macro ac:name="code"><ac:parameter ac:name="language">go</ac:parameter><ac:plain-text-body><![CDATA[fmt.Println("hi")]]></ac:plain-text-body></ac:structured-macro>`,
+	}
+	for _, xhtml := range safe {
+		if err := CheckRoundTrip(xhtml); err != nil {
+			t.Errorf("CheckRoundTrip(%q) = %v, want nil", xhtml, err)
+		}
+	}
+
+	lossy := []struct {
+		xhtml string
+		want  []string
+	}{
+		{`<ac:layout><ac:layout-section ac:type="two_equal"><ac:layout-cell><p>Left</p></ac:layout-cell><ac:layout-cell><p>Right</p></ac:layout-cell></ac:layout-section></ac:layout>`,
+			[]string{"ac:layout", "ac:layout-cell", "ac:layout-section", "p"}},
+		{`<div><p>Boxed</p></div>`, []string{"div", "p"}},
+		{`<p>Some <span style="color: red;">red</span> text</p>`, []string{"span"}},
+		{`<p>Thanks <ac:emoticon ac:name="smile" /></p>`, []string{"ac:emoticon"}},
+		{`<p>Ask <ac:link><ri:user ri:account-id="abc123" /></ac:link></p>`, []string{"ac:link", "ri:user"}},
+		{`<p><ac:inline-comment-marker ac:ref="r1">Commented</ac:inline-comment-marker> text</p>`, []string{"ac:inline-comment-marker"}},
+	}
+	for _, tt := range lossy {
+		err := CheckRoundTrip(tt.xhtml)
+		var lossErr *LossError
+		if !errors.As(err, &lossErr) {
+			t.Errorf("CheckRoundTrip(%q) = %v, want *LossError", tt.xhtml, err)
+			continue
+		}
+		if !reflect.DeepEqual(lossErr.Elements, tt.want) {
+			t.Errorf("CheckRoundTrip(%q) elements = %v, want %v", tt.xhtml, lossErr.Elements, tt.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Section is a part of a page introduced by a top-level heading. It extends
+// to the next heading of the same or a higher level, so it includes its
+// subsections. Blocks in page.Blocks[Start:End] belong to the section.
+type Section struct {
+	Heading  *Heading   `json:"heading,omitempty"` // nil for the root section
+	Path     string     `json:"path"`              // heading texts joined by "/"
+	Start    int        `json:"start"`             // index of the heading block
+	End      int        `json:"end"`               // index after the last block
+	Children []*Section `json:"children,omitempty"`
+}
+
+// Sections returns the section tree of page. The root section has no
+// heading and spans the whole page; its children are the sections of the
+// highest-level headings.
+func Sections(page *Page) *Section {
+	root := &Section{}
+	if page == nil {
+		return root
+	}
+	root.End = len(page.Blocks)
+
+	stack := []*Section{root}
+	for i, block := range page.Blocks {
+		h, ok := pointerBlock(block).(*Heading)
+		if !ok {
+			continue
+		}
+		for len(stack) > 1 && stack[len(stack)-1].Heading.Level >= h.Level {
+			stack[len(stack)-1].End = i
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1]
+		section := &Section{Heading: h, Path: joinSectionPath(parent.Path, h.Text), Start: i}
+		parent.Children = append(parent.Children, section)
+		stack = append(stack, section)
+	}
+	for _, section := range stack[1:] {
+		section.End = len(page.Blocks)
+	}
+	return root
+}
+
+// Walk calls fn for each section below s in document order.
+func (s *Section) Walk(fn func(*Section)) {
+	for _, child := range s.Children {
+		fn(child)
+		child.Walk(fn)
+	}
+}
+
+// FindSection returns the section addressed by path: heading texts
+// separated by "/", matched case-insensitively. Each heading may be nested
+// at any depth below the previous one, so "Risks" finds a Risks section
+// anywhere and "Design/Risks" one inside Design. A "/" within a heading is
+// written as "\/". Paths matching no section or several sections are
+// errors; the message lists the candidates.
+func FindSection(page *Page, path string) (*Section, error) {
+	root := Sections(page)
+	segments := splitSectionPath(path)
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("section path is empty")
+	}
+
+	matches := []*Section{root}
+	for _, segment := range segments {
+		var next []*Section
+		seen := make(map[*Section]bool)
+		for _, m := range matches {
+			m.Walk(func(s *Section) {
+				if !seen[s] && strings.EqualFold(strings.TrimSpace(s.Heading.Text), segment) {
+					seen[s] = true
+					next = append(next, s)
+				}
+			})
+		}
+		matches = next
+	}
+
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		var paths []string
+		root.Walk(func(s *Section) { paths = append(paths, s.Path) })
+		return nil, fmt.Errorf("section %q not found; sections: %s", path, strings.Join(paths, ", "))
+	default:
+		paths := make([]string, len(matches))
+		for i, m := range matches {
+			paths[i] = m.Path
+		}
+		return nil, fmt.Errorf("section %q is ambiguous; matches: %s", path, strings.Join(paths, ", "))
+	}
+}
+
+// ReplaceSection returns a copy of page in which the body of the section at
+// path, everything after its heading including subsections, is replaced by
+// blocks. The heading and the rest of the page are left untouched.
+func ReplaceSection(page *Page, path string, blocks []Block) (*Page, error) {
+	section, err := FindSection(page, path)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Block, 0, len(page.Blocks)-(section.End-section.Start-1)+len(blocks))
+	out = append(out, page.Blocks[:section.Start+1]...)
+	out = append(out, blocks...)
+	out = append(out, page.Blocks[section.End:]...)
+	return &Page{Blocks: out}, nil
+}
+
+func joinSectionPath(parent, text string) string {
+	text = strings.ReplaceAll(strings.TrimSpace(text), "/", `\/`)
+	if parent == "" {
+		return text
+	}
+	return parent + "/" + text
+}
+
+// splitSectionPath splits a path on unescaped "/" and unescapes "\/".
+func splitSectionPath(path string) []string {
+	var segments []string
+	var buf strings.Builder
+	flush := func() {
+		if s := strings.TrimSpace(buf.String()); s != "" {
+			segments = append(segments, s)
+		}
+		buf.Reset()
+	}
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\' && i+1 < len(path) && path[i+1] == '/':
+			buf.WriteByte('/')
+			i++
+		case path[i] == '/':
+			flush()
+		default:
+			buf.WriteByte(path[i])
+		}
+	}
+	flush()
+	return segments
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func sectionTestPage() *Page {
+	return &Page{Blocks: []Block{
+		&Paragraph{Text: "Intro"},
+		&Heading{Level: 1, Text: "Design"},
+		&Paragraph{Text: "Design body"},
+		&Heading{Level: 2, Text: "Risks"},
+		&Paragraph{Text: "Design risks"},
+		&Heading{Level: 3, Text: "CI/CD"},
+		&Paragraph{Text: "Pipelines"},
+		&Heading{Level: 1, Text: "Rollout"},
+		&Heading{Level: 3, Text: "Risks"},
+		&Paragraph{Text: "Rollout risks"},
+	}}
+}
+
+func TestSections(t *testing.T) {
+	root := Sections(sectionTestPage())
+
+	var got []string
+	root.Walk(func(s *Section) {
+		got = append(got, fmt.Sprintf("%s %d-%d", s.Path, s.Start, s.End))
+	})
+	want := []string{
+		"Design 1-7",
+		`Design/Risks 3-7`,
+		`Design/Risks/CI\/CD 5-7`,
+		"Rollout 7-10",
+		"Rollout/Risks 8-10",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Sections() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if root.Start != 0 || root.End != 10 || root.Heading != nil {
+		t.Errorf("root section = %+v", root)
+	}
+}
+
+func TestFindSection(t *testing.T) {
+	page := sectionTestPage()
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr string
+	}{
+		{path: "Design", want: "Design"},
+		{path: "design/risks", want: "Design/Risks"},
+		{path: "Rollout/Risks", want: "Rollout/Risks"},
+		{path: "Design/CI\\/CD", want: `Design/Risks/CI\/CD`},
+		{path: "Risks", wantErr: "ambiguous"},
+		{path: "Missing", wantErr: "not found"},
+		{path: " / ", wantErr: "empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := FindSection(page, tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("FindSection() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FindSection() error = %v", err)
+			}
+			if got.Path != tt.want {
+				t.Errorf("FindSection() = %s, want %s", got.Path, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplaceSection(t *testing.T) {
+	page := sectionTestPage()
+
+	got, err := ReplaceSection(page, "Design/Risks", []Block{&Paragraph{Text: "None"}})
+	if err != nil {
+		t.Fatalf("ReplaceSection() error = %v", err)
+	}
+
+	want := []string{"Intro", "Design", "Design body", "Risks", "None", "Rollout", "Risks", "Rollout risks"}
+	if len(got.Blocks) != len(want) {
+		t.Fatalf("ReplaceSection() blocks = %d, want %d", len(got.Blocks), len(want))
+	}
+	for i, block := range got.Blocks {
+		if text := RenderBlockText(block); text != want[i] {
+			t.Errorf("blocks[%d] = %q, want %q", i, text, want[i])
+		}
+	}
+	if len(page.Blocks) != 10 {
+		t.Errorf("ReplaceSection() modified the original page")
+	}
+}
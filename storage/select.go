@@ -0,0 +1,334 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Selector picks blocks out of a page, including blocks nested in lists,
+// blockquotes and table cells. Build one with ParseSelector or directly.
+type Selector struct {
+	Type     string           // block type, or "" or "*" for any type
+	Filters  []SelectorFilter // all must match
+	Section  string           // section path as for FindSection; "" for the whole page
+	TopLevel bool             // only match blocks directly in page.Blocks
+	Nth      int              // 1-based index among matches; 0 keeps all
+}
+
+// SelectorFilter tests one attribute of a block. Op is "=" for a
+// case-insensitive match of the whole value or "*=" for a case-insensitive
+// substring match. Attributes with several values, like table headers,
+// match if any value does.
+type SelectorFilter struct {
+	Attr  string `json:"attr"`
+	Op    string `json:"op"`
+	Value string `json:"value"`
+}
+
+// Match is a block selected from a page.
+type Match struct {
+	Path    string `json:"path"`    // JSON-pointer style path, see Walk
+	Section string `json:"section"` // path of the innermost enclosing section
+	Block   Block  `json:"-"`
+}
+
+// MarshalJSON includes the block tagged with its type.
+func (m Match) MarshalJSON() ([]byte, error) {
+	type plain Match
+	block, err := marshalBlock(m.Block)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		plain
+		Block json.RawMessage `json:"block"`
+	}{plain(m), block})
+}
+
+var selectorTypes = map[string]bool{
+	"paragraph": true, "heading": true, "table": true, "bullet_list": true,
+	"numbered_list": true, "task_list": true, "blockquote": true, "image": true,
+	"code_block": true, "horizontal_rule": true, "macro": true,
+}
+
+// selectorAttrs returns the values of a block attribute that filters test.
+var selectorAttrs = map[string]func(Block) []string{
+	"text": func(b Block) []string { return []string{RenderBlockText(b)} },
+	"header": func(b Block) []string {
+		if t, ok := pointerBlock(b).(*Table); ok {
+			return t.Headers
+		}
+		return nil
+	},
+	"name": func(b Block) []string {
+		if m, ok := pointerBlock(b).(*Macro); ok {
+			return []string{m.Name}
+		}
+		return nil
+	},
+	"level": func(b Block) []string {
+		if h, ok := pointerBlock(b).(*Heading); ok {
+			return []string{strconv.Itoa(h.Level)}
+		}
+		return nil
+	},
+	"language": func(b Block) []string {
+		switch v := pointerBlock(b).(type) {
+		case *CodeBlock:
+			return []string{v.Language}
+		case *Macro:
+			return []string{v.Params["language"]}
+		}
+		return nil
+	},
+}
+
+// ParseSelector parses a selector of the form
+//
+//	type[attr=value][attr*=value]:section(path):top:nth(N)
+//
+// where every part is optional. The type is a block type such as "table" or
+// "macro", or "*". Attributes are text, header (table headers), name (macro
+// name), level (heading level) and language (code language). Values may be
+// double-quoted to include "]" or ")". For example, the second table with
+// an Owner column under the heading Owners is
+//
+//	table[header=Owner]:section(Owners):nth(2)
+func ParseSelector(s string) (*Selector, error) {
+	p := &selectorParser{input: strings.TrimSpace(s)}
+	sel := &Selector{Type: p.ident()}
+	if sel.Type == "" && p.peek() == '*' {
+		sel.Type = string(p.next())
+	}
+	if sel.Type != "" && sel.Type != "*" && !selectorTypes[sel.Type] {
+		return nil, fmt.Errorf("unknown block type %q; types: %s", sel.Type, strings.Join(sortedKeys(selectorTypes), ", "))
+	}
+
+	for !p.done() {
+		switch p.next() {
+		case '[':
+			filter, err := p.filter()
+			if err != nil {
+				return nil, err
+			}
+			sel.Filters = append(sel.Filters, filter)
+		case ':':
+			if err := p.pseudo(sel); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, p.errorf("expected [ or :")
+		}
+	}
+	return sel, nil
+}
+
+// Select returns the blocks of page matched by selector, in document order.
+func Select(page *Page, selector string) ([]Match, error) {
+	sel, err := ParseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	return sel.Select(page)
+}
+
+// Select returns the blocks of page matched by s, in document order. It
+// fails if s.Section matches no section or several sections.
+func (s *Selector) Select(page *Page) ([]Match, error) {
+	if page == nil {
+		page = &Page{}
+	}
+	start, end := 0, len(page.Blocks)
+	if s.Section != "" {
+		section, err := FindSection(page, s.Section)
+		if err != nil {
+			return nil, err
+		}
+		start, end = section.Start, section.End
+	}
+
+	owners := make([]string, len(page.Blocks))
+	Sections(page).Walk(func(sec *Section) {
+		for i := sec.Start; i < sec.End; i++ {
+			owners[i] = sec.Path
+		}
+	})
+
+	matches := []Match{}
+	for i := start; i < end; i++ {
+		walkBlock("/blocks/"+strconv.Itoa(i), page.Blocks[i], func(path string, block Block) bool {
+			if s.matches(block) {
+				matches = append(matches, Match{Path: path, Section: owners[i], Block: block})
+			}
+			return !s.TopLevel
+		})
+	}
+
+	if s.Nth > 0 {
+		if s.Nth > len(matches) {
+			return []Match{}, nil
+		}
+		matches = matches[s.Nth-1 : s.Nth]
+	}
+	return matches, nil
+}
+
+func (s *Selector) matches(block Block) bool {
+	if s.Type != "" && s.Type != "*" && block.BlockType() != s.Type {
+		return false
+	}
+	for _, f := range s.Filters {
+		if !f.matches(block) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f SelectorFilter) matches(block Block) bool {
+	values := selectorAttrs[f.Attr]
+	if values == nil {
+		return false
+	}
+	want := strings.ToLower(f.Value)
+	for _, v := range values(block) {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if f.Op == "*=" && strings.Contains(v, want) || f.Op != "*=" && v == want {
+			return true
+		}
+	}
+	return false
+}
+
+type selectorParser struct {
+	input string
+	pos   int
+}
+
+func (p *selectorParser) done() bool { return p.pos >= len(p.input) }
+
+func (p *selectorParser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *selectorParser) next() byte {
+	c := p.peek()
+	p.pos++
+	return c
+}
+
+func (p *selectorParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid selector %q at offset %d: %s", p.input, p.pos, fmt.Sprintf(format, args...))
+}
+
+// ident reads a block type, attribute or pseudo-class name.
+func (p *selectorParser) ident() string {
+	start := p.pos
+	for !p.done() {
+		c := p.peek()
+		if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			break
+		}
+		p.pos++
+	}
+	return strings.ToLower(p.input[start:p.pos])
+}
+
+// value reads a quoted value, or an unquoted one up to end.
+func (p *selectorParser) value(end byte) (string, error) {
+	if p.peek() != '"' {
+		start := p.pos
+		for !p.done() && p.peek() != end {
+			p.pos++
+		}
+		return strings.TrimSpace(p.input[start:p.pos]), nil
+	}
+
+	p.pos++
+	var buf strings.Builder
+	for {
+		switch c := p.next(); {
+		case p.pos > len(p.input):
+			p.pos--
+			return "", p.errorf("unterminated quoted value")
+		case c == '"':
+			return buf.String(), nil
+		case c == '\\' && !p.done():
+			buf.WriteByte(p.next())
+		default:
+			buf.WriteByte(c)
+		}
+	}
+}
+
+func (p *selectorParser) expect(c byte) error {
+	if p.next() != c {
+		p.pos--
+		return p.errorf("expected %q", c)
+	}
+	return nil
+}
+
+// filter parses the rest of "[attr=value]" after the opening bracket.
+func (p *selectorParser) filter() (SelectorFilter, error) {
+	f := SelectorFilter{Attr: p.ident()}
+	if selectorAttrs[f.Attr] == nil {
+		return f, p.errorf("unknown attribute %q; attributes: %s", f.Attr, strings.Join(sortedKeys(selectorAttrs), ", "))
+	}
+	switch {
+	case strings.HasPrefix(p.input[p.pos:], "*="):
+		f.Op = "*="
+	case p.peek() == '=':
+		f.Op = "="
+	default:
+		return f, p.errorf("expected = or *=")
+	}
+	p.pos += len(f.Op)
+
+	var err error
+	if f.Value, err = p.value(']'); err != nil {
+		return f, err
+	}
+	return f, p.expect(']')
+}
+
+// pseudo parses the rest of ":section(path)", ":top" or ":nth(N)" after the
+// colon.
+func (p *selectorParser) pseudo(sel *Selector) error {
+	name := p.ident()
+	if name == "top" {
+		sel.TopLevel = true
+		return nil
+	}
+	if name != "section" && name != "nth" {
+		return p.errorf("unknown pseudo-class %q; expected section, top or nth", name)
+	}
+	if err := p.expect('('); err != nil {
+		return err
+	}
+	arg, err := p.value(')')
+	if err != nil {
+		return err
+	}
+	if name == "section" {
+		sel.Section = arg
+	} else if sel.Nth, err = strconv.Atoi(arg); err != nil || sel.Nth < 1 {
+		return p.errorf("nth requires a positive integer")
+	}
+	return p.expect(')')
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
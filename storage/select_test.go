@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var selectTestPage = &Page{Blocks: []Block{
+	&Heading{Level: 1, Text: "Owners"},
+	&Table{Headers: []string{"Team", "Owner"}, Rows: []Row{{Cells: []Cell{{Text: "API"}, {Macro: &Macro{Name: "status", Params: map[string]string{"title": "OK"}}}}}}},
+	&Paragraph{Text: "Escalate to the on-call owner."},
+	&Table{Headers: []string{"Service", "Owner"}, Rows: []Row{{Cells: []Cell{{Text: "db"}, {Text: "Ana"}}}}},
+	&Heading{Level: 2, Text: "Backups"},
+	&BulletList{Items: []ListItem{{Text: "nightly", Children: []Block{&CodeBlock{Language: "bash", Code: "backup.sh"}}}}},
+	&Heading{Level: 1, Text: "Links"},
+	&Blockquote{Blocks: []Block{&Paragraph{Text: "Quoted owner"}}},
+	&Table{Headers: []string{"Owner"}},
+}}
+
+func TestWalk(t *testing.T) {
+	var paths []string
+	Walk(selectTestPage, func(path string, block Block) bool {
+		paths = append(paths, path+" "+block.BlockType())
+		return block.BlockType() != "blockquote"
+	})
+	want := []string{
+		"/blocks/0 heading",
+		"/blocks/1 table",
+		"/blocks/1/rows/0/cells/1 macro",
+		"/blocks/2 paragraph",
+		"/blocks/3 table",
+		"/blocks/4 heading",
+		"/blocks/5 bullet_list",
+		"/blocks/5/items/0/children/0 code_block",
+		"/blocks/6 heading",
+		"/blocks/7 blockquote",
+		"/blocks/8 table",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("Walk() paths =\n%s\nwant\n%s", strings.Join(paths, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     []string // "path section"
+	}{
+		{"table:section(Owners):nth(2)", []string{"/blocks/3 Owners"}},
+		{"table[header=owner]", []string{"/blocks/1 Owners", "/blocks/3 Owners", "/blocks/8 Links"}},
+		{"table[header=Service]", []string{"/blocks/3 Owners"}},
+		{"macro[name=status]", []string{"/blocks/1/rows/0/cells/1 Owners"}},
+		{`paragraph[text*="OWNER"]`, []string{"/blocks/2 Owners", "/blocks/7/blocks/0 Links"}},
+		{`*[text*=owner]:top`, []string{"/blocks/0 Owners", "/blocks/1 Owners", "/blocks/2 Owners", "/blocks/3 Owners", "/blocks/7 Links", "/blocks/8 Links"}},
+		{"heading[level=2]", []string{"/blocks/4 Owners/Backups"}},
+		{"code_block[language=bash]", []string{"/blocks/5/items/0/children/0 Owners/Backups"}},
+		{":section(Backups)", []string{"/blocks/4 Owners/Backups", "/blocks/5 Owners/Backups", "/blocks/5/items/0/children/0 Owners/Backups"}},
+		{"table:nth(9)", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			matches, err := Select(selectTestPage, tt.selector)
+			if err != nil {
+				t.Fatalf("Select() error = %v", err)
+			}
+			got := []string{}
+			for _, m := range matches {
+				got = append(got, m.Path+" "+m.Section)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Select() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSelectorErrors(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     string
+	}{
+		{"tables", `unknown block type "tables"`},
+		{"table[owner=x]", `unknown attribute "owner"`},
+		{"table[header~x]", "expected = or *="},
+		{"table[header=x", `expected ']'`},
+		{`table[header="x]`, "unterminated quoted value"},
+		{"table:first", `unknown pseudo-class "first"`},
+		{"table:nth(0)", "nth requires a positive integer"},
+		{"table section", "expected [ or :"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			_, err := ParseSelector(tt.selector)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseSelector() error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSelectorQuoted(t *testing.T) {
+	sel, err := ParseSelector(`macro[name="a]b"]:section("Q\"A")`)
+	if err != nil {
+		t.Fatalf("ParseSelector() error = %v", err)
+	}
+	if sel.Filters[0].Value != "a]b" || sel.Section != `Q"A` {
+		t.Errorf("ParseSelector() = %+v", sel)
+	}
+}
+
+func TestSelectSectionNotFound(t *testing.T) {
+	if _, err := Select(selectTestPage, "table:section(Risks)"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Select() error = %v", err)
+	}
+}
+
+func TestMatchJSON(t *testing.T) {
+	data, err := json.Marshal(Match{Path: "/blocks/0", Section: "Owners", Block: &Heading{Level: 1, Text: "Owners"}})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `{"path":"/blocks/0","section":"Owners","block":{"level":1,"text":"Owners","type":"heading"}}`
+	if string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+}
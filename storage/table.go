@@ -0,0 +1,410 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TableFromCSV builds a table from comma-separated values. The first record
+// is the header row. Short records are padded with empty cells; records
+// with non-empty fields beyond the last header are an error.
+func TableFromCSV(r io.Reader) (*Table, error) {
+	return tableFromDelimited(r, ',')
+}
+
+// TableFromTSV builds a table from tab-separated values, as copied from a
+// spreadsheet. The first record is the header row.
+func TableFromTSV(r io.Reader) (*Table, error) {
+	return tableFromDelimited(r, '\t')
+}
+
+func tableFromDelimited(r io.Reader, comma rune) (*Table, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no header row")
+	}
+
+	table := &Table{Headers: records[0], Rows: make([]Row, 0, len(records)-1)}
+	for r, record := range records[1:] {
+		row := Row{Cells: make([]Cell, len(table.Headers))}
+		for i, value := range record {
+			if i < len(row.Cells) {
+				row.Cells[i].Text = value
+			} else if strings.TrimSpace(value) != "" {
+				return nil, fmt.Errorf("record %d has %d fields, but the header has %d", r, len(record), len(table.Headers))
+			}
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table, nil
+}
+
+// TableFromJSON builds a table from a JSON array of objects. Columns appear
+// in the order their keys are first seen. Strings are used as is; other
+// values are written as JSON, except null, which leaves the cell empty.
+func TableFromJSON(data []byte) (*Table, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := decoder.Token(); err != nil || tok != json.Delim('[') {
+		return nil, fmt.Errorf("expected a JSON array of objects")
+	}
+
+	table := &Table{Headers: []string{}, Rows: []Row{}}
+	columns := make(map[string]int)
+	for decoder.More() {
+		if tok, err := decoder.Token(); err != nil || tok != json.Delim('{') {
+			return nil, fmt.Errorf("record %d: expected an object", len(table.Rows))
+		}
+		values := make(map[int]string)
+		for decoder.More() {
+			tok, err := decoder.Token()
+			if err != nil {
+				return nil, fmt.Errorf("record %d: %w", len(table.Rows), err)
+			}
+			key, _ := tok.(string)
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); err != nil {
+				return nil, fmt.Errorf("record %d: %w", len(table.Rows), err)
+			}
+			col, ok := columns[key]
+			if !ok {
+				col = len(table.Headers)
+				columns[key] = col
+				table.Headers = append(table.Headers, key)
+			}
+			values[col] = jsonCellText(raw)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, fmt.Errorf("record %d: %w", len(table.Rows), err)
+		}
+
+		row := Row{Cells: make([]Cell, 0, len(table.Headers))}
+		for col := range table.Headers {
+			row.Cells = append(row.Cells, Cell{Text: values[col]})
+		}
+		table.Rows = append(table.Rows, row)
+	}
+
+	// Earlier rows are shorter when later records introduce new keys.
+	table.normalize()
+	return table, nil
+}
+
+func jsonCellText(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	if string(raw) == "null" {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
+
+// WriteCSV writes the table as delimited text with a header row, using comma
+// as the separator (',' for CSV, '\t' for TSV). Macro cells are written as
+// their text; status macros as their title.
+func (t *Table) WriteCSV(w io.Writer, comma rune) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+	if len(t.Headers) > 0 {
+		if err := writer.Write(t.Headers); err != nil {
+			return err
+		}
+	}
+	for _, row := range t.Rows {
+		record := make([]string, len(row.Cells))
+		for i, cell := range row.Cells {
+			record[i] = cellText(cell)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Clone returns a copy of t that shares no slices with it.
+func (t *Table) Clone() *Table {
+	return cloneTable(t)
+}
+
+// ColumnIndex returns the index of the column with the given header,
+// compared case-insensitively, or -1.
+func (t *Table) ColumnIndex(header string) int {
+	header = strings.TrimSpace(header)
+	for i, h := range t.Headers {
+		if strings.EqualFold(strings.TrimSpace(h), header) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (t *Table) column(header string) (int, error) {
+	col := t.ColumnIndex(header)
+	if col < 0 {
+		return -1, fmt.Errorf("column %q not found; columns: %s", header, strings.Join(t.Headers, ", "))
+	}
+	return col, nil
+}
+
+// SortBy sorts the rows by the column with the given header. If every
+// non-empty cell in the column is a number, rows sort numerically;
+// otherwise they sort by text, ignoring case. Empty cells sort last in
+// either direction, and equal rows keep their order.
+func (t *Table) SortBy(header string, descending bool) error {
+	col, err := t.column(header)
+	if err != nil {
+		return err
+	}
+	t.normalize()
+
+	keys := make([]string, len(t.Rows))
+	numbers := make([]float64, len(t.Rows))
+	numeric := true
+	for i, row := range t.Rows {
+		keys[i] = strings.TrimSpace(cellText(row.Cells[col]))
+		if keys[i] == "" {
+			continue
+		}
+		if numbers[i], err = strconv.ParseFloat(keys[i], 64); err != nil {
+			numeric = false
+		}
+	}
+
+	order := make([]int, len(t.Rows))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		i, j := order[a], order[b]
+		if keys[i] == "" || keys[j] == "" {
+			return keys[j] == "" && keys[i] != ""
+		}
+		var cmp int
+		switch {
+		case numeric && numbers[i] < numbers[j]:
+			cmp = -1
+		case numeric && numbers[i] > numbers[j]:
+			cmp = 1
+		case !numeric:
+			cmp = strings.Compare(strings.ToLower(keys[i]), strings.ToLower(keys[j]))
+		}
+		if descending {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+
+	rows := make([]Row, len(t.Rows))
+	for i, idx := range order {
+		rows[i] = t.Rows[idx]
+	}
+	t.Rows = rows
+	return nil
+}
+
+// AddColumn inserts an empty column with the given header at index, or
+// appends it when index is negative or past the last column.
+func (t *Table) AddColumn(header string, index int) error {
+	if t.ColumnIndex(header) >= 0 {
+		return fmt.Errorf("column %q already exists", header)
+	}
+	t.normalize()
+	if index < 0 || index > len(t.Headers) {
+		index = len(t.Headers)
+	}
+	t.Headers = append(t.Headers[:index], append([]string{header}, t.Headers[index:]...)...)
+	for i := range t.Rows {
+		cells := t.Rows[i].Cells
+		t.Rows[i].Cells = append(cells[:index], append([]Cell{{}}, cells[index:]...)...)
+	}
+	return nil
+}
+
+// RemoveColumn removes the column with the given header.
+func (t *Table) RemoveColumn(header string) error {
+	col, err := t.column(header)
+	if err != nil {
+		return err
+	}
+	t.normalize()
+	t.Headers = append(t.Headers[:col], t.Headers[col+1:]...)
+	for i := range t.Rows {
+		t.Rows[i].Cells = append(t.Rows[i].Cells[:col], t.Rows[i].Cells[col+1:]...)
+	}
+	return nil
+}
+
+// RenameColumn changes the header of a column.
+func (t *Table) RenameColumn(header, newHeader string) error {
+	col, err := t.column(header)
+	if err != nil {
+		return err
+	}
+	if other := t.ColumnIndex(newHeader); other >= 0 && other != col {
+		return fmt.Errorf("column %q already exists", newHeader)
+	}
+	t.Headers[col] = newHeader
+	return nil
+}
+
+// Upsert merges the rows of src into t, matching rows by the text of the
+// key column. Matched rows get the values of src's columns; other columns
+// are left alone. Unmatched rows are appended. Columns are matched by
+// header, ignoring case and order, and every column of src must exist in t.
+// Status macros keep their colour when given a new title as text; other
+// macro cells are replaced by the new text unless it is unchanged.
+func (t *Table) Upsert(key string, src *Table) (inserted, updated int, err error) {
+	keyCol, err := t.column(key)
+	if err != nil {
+		return 0, 0, err
+	}
+	srcKey := src.ColumnIndex(key)
+	if srcKey < 0 {
+		return 0, 0, fmt.Errorf("key column %q missing from the new rows", key)
+	}
+	mapping := make([]int, len(src.Headers))
+	for i, h := range src.Headers {
+		if mapping[i], err = t.column(h); err != nil {
+			return 0, 0, err
+		}
+	}
+	t.normalize()
+
+	existing := make(map[string]int)
+	for i, row := range t.Rows {
+		k := strings.TrimSpace(cellText(row.Cells[keyCol]))
+		if _, dup := existing[k]; !dup {
+			existing[k] = i
+		}
+	}
+
+	for r, srcRow := range src.Rows {
+		var k string
+		if srcKey < len(srcRow.Cells) {
+			k = strings.TrimSpace(cellText(srcRow.Cells[srcKey]))
+		}
+		if k == "" {
+			return inserted, updated, fmt.Errorf("row %d has no %s", r, key)
+		}
+		idx, found := existing[k]
+		if !found {
+			idx = len(t.Rows)
+			existing[k] = idx
+			t.Rows = append(t.Rows, Row{Cells: make([]Cell, len(t.Headers))})
+			inserted++
+		} else {
+			updated++
+		}
+		for i, cell := range srcRow.Cells {
+			if i < len(mapping) {
+				cells := t.Rows[idx].Cells
+				cells[mapping[i]] = mergeCell(cells[mapping[i]], cell)
+			}
+		}
+	}
+	return inserted, updated, nil
+}
+
+// mergeCell returns the cell that replaces old when Upsert writes cell
+// over it. A plain-text value keeps a macro whose text it matches, and
+// becomes the title of a status macro; other macros are replaced.
+func mergeCell(old, cell Cell) Cell {
+	if old.Macro == nil || cell.Macro != nil {
+		return cell
+	}
+	if cellText(old) == cell.Text {
+		return old
+	}
+	if old.Macro.Name == "status" && cell.Text != "" {
+		macro := *old.Macro
+		macro.Params = make(map[string]string, len(old.Macro.Params)+1)
+		for k, v := range old.Macro.Params {
+			macro.Params[k] = v
+		}
+		macro.Params["title"] = cell.Text
+		return Cell{Macro: &macro}
+	}
+	return cell
+}
+
+// normalize pads every row to the number of columns.
+func (t *Table) normalize() {
+	for i, row := range t.Rows {
+		if len(row.Cells) < len(t.Headers) {
+			t.Rows[i].Cells = append(row.Cells, make([]Cell, len(t.Headers)-len(row.Cells))...)
+		}
+	}
+}
+
+// cellText returns the text of a cell; for a status macro, its title.
+func cellText(cell Cell) string {
+	switch {
+	case cell.Macro == nil:
+		return cell.Text
+	case cell.Macro.Name == "status":
+		return cell.Macro.Params["title"]
+	default:
+		return macroText(cell.Macro)
+	}
+}
+
+// FindTable returns the block index and table of a top-level table in page.
+// Only tables whose headers include all of headers (ignoring case) are
+// considered. n selects the nth such table counting from 0; a negative n
+// requires exactly one candidate.
+func FindTable(page *Page, headers []string, n int) (int, *Table, error) {
+	var indexes []int
+	if page != nil {
+		for i, block := range page.Blocks {
+			if t, ok := asTable(block); ok && hasHeaders(t, headers) {
+				indexes = append(indexes, i)
+			}
+		}
+	}
+
+	what := "table"
+	if len(headers) > 0 {
+		what = fmt.Sprintf("table with headers %s", strings.Join(headers, ", "))
+	}
+	switch {
+	case len(indexes) == 0:
+		return -1, nil, fmt.Errorf("no %s found", what)
+	case n < 0 && len(indexes) > 1:
+		return -1, nil, fmt.Errorf("%d tables match; choose one by index or headers", len(indexes))
+	case n < 0:
+		n = 0
+	case n >= len(indexes):
+		return -1, nil, fmt.Errorf("%s %d not found; %d match", what, n, len(indexes))
+	}
+	t, _ := asTable(page.Blocks[indexes[n]])
+	return indexes[n], t, nil
+}
+
+func hasHeaders(t *Table, headers []string) bool {
+	for _, h := range headers {
+		if t.ColumnIndex(h) < 0 {
+			return false
+		}
+	}
+	return true
+}
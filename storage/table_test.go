@@ -0,0 +1,252 @@
+package storage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func tableCSV(t *testing.T, table *Table) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := table.WriteCSV(&buf, ','); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestTableFromCSV(t *testing.T) {
+	table, err := TableFromCSV(strings.NewReader("Host,Owner\nweb1,\"Ana, B\"\ndb1\n"))
+	if err != nil {
+		t.Fatalf("TableFromCSV() error = %v", err)
+	}
+	if got := tableCSV(t, table); got != "Host,Owner\nweb1,\"Ana, B\"\ndb1,\n" {
+		t.Errorf("TableFromCSV() = %q", got)
+	}
+
+	table, err = TableFromTSV(strings.NewReader("Host\tCPU\nweb1\t4\n"))
+	if err != nil {
+		t.Fatalf("TableFromTSV() error = %v", err)
+	}
+	if table.Headers[1] != "CPU" || table.Rows[0].Cells[1].Text != "4" {
+		t.Errorf("TableFromTSV() = %+v", table)
+	}
+
+	if _, err := TableFromCSV(strings.NewReader("")); err == nil {
+		t.Error("TableFromCSV() should reject empty input")
+	}
+
+	table, err = TableFromCSV(strings.NewReader("Host,Owner\nweb1,Ana,,\n"))
+	if err != nil {
+		t.Fatalf("TableFromCSV() error = %v", err)
+	}
+	if len(table.Rows[0].Cells) != 2 {
+		t.Errorf("TableFromCSV() cells = %v", table.Rows[0].Cells)
+	}
+	_, err = TableFromCSV(strings.NewReader("Host,Owner\nweb1,Ana\ndb1,Bo,extra\n"))
+	if err == nil || err.Error() != "record 1 has 3 fields, but the header has 2" {
+		t.Errorf("TableFromCSV() error = %v, want extra fields rejected", err)
+	}
+}
+
+func TestTableFromJSON(t *testing.T) {
+	table, err := TableFromJSON([]byte(`[{"host":"web1","cpu":4},{"host":"db1","disk":null,"tags":["a","b"]}]`))
+	if err != nil {
+		t.Fatalf("TableFromJSON() error = %v", err)
+	}
+	want := "host,cpu,disk,tags\nweb1,4,,\ndb1,,,\"[\"\"a\"\",\"\"b\"\"]\"\n"
+	if got := tableCSV(t, table); got != want {
+		t.Errorf("TableFromJSON() =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, err := TableFromJSON([]byte(`{"host":"web1"}`)); err == nil {
+		t.Error("TableFromJSON() should reject an object")
+	}
+	if _, err := TableFromJSON([]byte(`["web1"]`)); err == nil {
+		t.Error("TableFromJSON() should reject non-object records")
+	}
+}
+
+func TestTableWriteCSVMacros(t *testing.T) {
+	table := &Table{
+		Headers: []string{"Service", "State"},
+		Rows:    []Row{{Cells: []Cell{{Text: "api"}, {Macro: &Macro{Name: "status", Params: map[string]string{"title": "OK"}}}}}},
+	}
+	var buf bytes.Buffer
+	if err := table.WriteCSV(&buf, '\t'); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	if buf.String() != "Service\tState\napi\tOK\n" {
+		t.Errorf("WriteCSV() = %q", buf.String())
+	}
+}
+
+func TestTableSortBy(t *testing.T) {
+	tests := []struct {
+		name       string
+		column     string
+		descending bool
+		want       string
+	}{
+		{"numeric", "cpu", false, "Host,CPU\nc,2\na,10\nd,10\nb,\n"},
+		{"numeric descending", "CPU", true, "Host,CPU\na,10\nd,10\nc,2\nb,\n"},
+		{"text", "host", false, "Host,CPU\na,10\nb,\nc,2\nd,10\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := TableFromCSV(strings.NewReader("Host,CPU\na,10\nb,\nc,2\nd,10\n"))
+			if err != nil {
+				t.Fatalf("TableFromCSV() error = %v", err)
+			}
+			if err := table.SortBy(tt.column, tt.descending); err != nil {
+				t.Fatalf("SortBy() error = %v", err)
+			}
+			if got := tableCSV(t, table); got != tt.want {
+				t.Errorf("SortBy() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	if err := (&Table{Headers: []string{"A"}}).SortBy("B", false); err == nil || !strings.Contains(err.Error(), "columns: A") {
+		t.Errorf("SortBy() error = %v", err)
+	}
+}
+
+func TestTableColumns(t *testing.T) {
+	table := &Table{
+		Headers: []string{"Host", "Owner"},
+		Rows:    []Row{{Cells: []Cell{{Text: "web1"}}}},
+	}
+	if err := table.AddColumn("Env", 1); err != nil {
+		t.Fatalf("AddColumn() error = %v", err)
+	}
+	if err := table.AddColumn("Notes", -1); err != nil {
+		t.Fatalf("AddColumn() error = %v", err)
+	}
+	if err := table.RenameColumn("owner", "Team"); err != nil {
+		t.Fatalf("RenameColumn() error = %v", err)
+	}
+	if err := table.RemoveColumn("env"); err != nil {
+		t.Fatalf("RemoveColumn() error = %v", err)
+	}
+	if got := tableCSV(t, table); got != "Host,Team,Notes\nweb1,,\n" {
+		t.Errorf("columns = %q", got)
+	}
+
+	if err := table.AddColumn("host", 0); err == nil {
+		t.Error("AddColumn() should reject a duplicate header")
+	}
+	if err := table.RenameColumn("Host", "team"); err == nil {
+		t.Error("RenameColumn() should reject a duplicate header")
+	}
+	if err := table.RemoveColumn("Env"); err == nil {
+		t.Error("RemoveColumn() should reject a missing column")
+	}
+}
+
+func TestTableUpsert(t *testing.T) {
+	table, err := TableFromCSV(strings.NewReader("Host,CPU,Owner\nweb1,2,Ana\ndb1,8,Bo\n"))
+	if err != nil {
+		t.Fatalf("TableFromCSV() error = %v", err)
+	}
+	src, err := TableFromCSV(strings.NewReader("cpu,host\n4,web1\n16,cache1\n"))
+	if err != nil {
+		t.Fatalf("TableFromCSV() error = %v", err)
+	}
+
+	inserted, updated, err := table.Upsert("Host", src)
+	if err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+	if inserted != 1 || updated != 1 {
+		t.Errorf("Upsert() = %d inserted, %d updated", inserted, updated)
+	}
+	if got := tableCSV(t, table); got != "Host,CPU,Owner\nweb1,4,Ana\ndb1,8,Bo\ncache1,16,\n" {
+		t.Errorf("Upsert() =\n%s", got)
+	}
+
+	bad, _ := TableFromCSV(strings.NewReader("Host,RAM\nweb1,8\n"))
+	if _, _, err := table.Upsert("Host", bad); err == nil || !strings.Contains(err.Error(), `"RAM" not found`) {
+		t.Errorf("Upsert() error = %v", err)
+	}
+	noKey, _ := TableFromCSV(strings.NewReader("Host,CPU\n,8\n"))
+	if _, _, err := table.Upsert("Host", noKey); err == nil {
+		t.Error("Upsert() should reject rows without a key")
+	}
+}
+
+func TestTableUpsertMacroCells(t *testing.T) {
+	status := func(title string) *Macro {
+		return &Macro{Name: "status", Params: map[string]string{"colour": "Green", "title": title}}
+	}
+	jira := &Macro{Name: "jira", Params: map[string]string{"key": "OPS-1"}}
+	table := &Table{
+		Headers: []string{"Host", "State", "Ticket"},
+		Rows: []Row{
+			{Cells: []Cell{{Text: "web1"}, {Macro: status("UP")}, {Macro: jira}}},
+			{Cells: []Cell{{Text: "db1"}, {Macro: status("UP")}, {Macro: jira}}},
+		},
+	}
+	src, err := TableFromCSV(strings.NewReader("Host,State,Ticket\nweb1,DOWN,OPS-2\ndb1,UP," + cellText(Cell{Macro: jira}) + "\n"))
+	if err != nil {
+		t.Fatalf("TableFromCSV() error = %v", err)
+	}
+	up := table.Rows[0].Cells[1].Macro
+
+	if _, _, err := table.Upsert("Host", src); err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+	web, db := table.Rows[0].Cells, table.Rows[1].Cells
+	if m := web[1].Macro; m == nil || m.Params["title"] != "DOWN" || m.Params["colour"] != "Green" {
+		t.Errorf("status cell = %+v, want macro titled DOWN", web[1])
+	}
+	if web[2].Macro != nil || web[2].Text != "OPS-2" {
+		t.Errorf("changed macro cell = %+v, want text OPS-2", web[2])
+	}
+	if db[1].Macro == nil || db[1].Macro.Params["title"] != "UP" || db[2].Macro != jira {
+		t.Errorf("unchanged cells = %+v, want macros kept", db)
+	}
+	if up.Params["title"] != "UP" {
+		t.Error("Upsert() modified the replaced status macro")
+	}
+}
+
+func TestFindTable(t *testing.T) {
+	page := &Page{Blocks: []Block{
+		&Paragraph{Text: "x"},
+		&Table{Headers: []string{"Host", "Owner"}},
+		Table{Headers: []string{"Service", "Owner"}},
+	}}
+
+	tests := []struct {
+		name    string
+		headers []string
+		n       int
+		want    int
+		wantErr string
+	}{
+		{"by index", nil, 1, 2, ""},
+		{"by headers", []string{"service"}, -1, 2, ""},
+		{"headers and index", []string{"Owner"}, 0, 1, ""},
+		{"ambiguous", []string{"Owner"}, -1, 0, "2 tables match"},
+		{"out of range", nil, 2, 0, "table 2 not found"},
+		{"no match", []string{"Team"}, -1, 0, "no table with headers Team"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, table, err := FindTable(page, tt.headers, tt.n)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("FindTable() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FindTable() error = %v", err)
+			}
+			if index != tt.want || table == nil {
+				t.Errorf("FindTable() = %d, %v, want %d", index, table, tt.want)
+			}
+		})
+	}
+}
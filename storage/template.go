@@ -0,0 +1,699 @@
+package storage
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TemplateVariable describes a variable that a Template expects.
+type TemplateVariable struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	Default     string `json:"default,omitempty"`
+	List        bool   `json:"list,omitempty"` // a list for {{#each}}
+}
+
+// Template is a page with placeholders, filled in by Execute. See
+// ExecuteTemplate for the placeholder syntax.
+type Template struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Title       string             `json:"title"` // page title, may contain placeholders
+	Variables   []TemplateVariable `json:"variables"`
+	Body        *Page              `json:"-"`
+}
+
+// Execute returns the title and page produced by filling the template with
+// vars. Declared variables missing from vars take their default; missing
+// required variables are an error.
+func (t *Template) Execute(vars map[string]interface{}) (string, *Page, error) {
+	filled := make(map[string]interface{}, len(vars)+len(t.Variables))
+	for k, v := range vars {
+		filled[k] = v
+	}
+	var missing []string
+	for _, v := range t.Variables {
+		if _, ok := filled[v.Name]; ok {
+			continue
+		}
+		switch {
+		case v.Required:
+			missing = append(missing, v.Name)
+		case v.List:
+			filled[v.Name] = []interface{}{}
+		default:
+			filled[v.Name] = v.Default
+		}
+	}
+	if len(missing) > 0 {
+		return "", nil, fmt.Errorf("template %s: missing required variables: %s", t.Name, strings.Join(missing, ", "))
+	}
+
+	title, err := ExecuteTemplateText(t.Title, filled)
+	if err != nil {
+		return "", nil, fmt.Errorf("template %s: title: %w", t.Name, err)
+	}
+	page, err := ExecuteTemplate(t.Body, filled)
+	if err != nil {
+		return "", nil, fmt.Errorf("template %s: %w", t.Name, err)
+	}
+	return title, page, nil
+}
+
+// ExecuteTemplate returns a copy of page with its placeholders filled from
+// vars:
+//
+//   - {{name}} is replaced by the value of name, and {{.}} by the current
+//     element inside {{#each}}. Undefined variables are an error.
+//   - {{#if name}}...{{else}}...{{/if}} keeps the first part when name is
+//     set to a non-empty value, otherwise the optional second part.
+//   - {{#each name}}...{{/each}} repeats its content for each element of the
+//     list name. Fields of object elements are available as variables.
+//
+// Sections may span whole blocks, written as paragraphs holding only the
+// tag, or lie within the text of a block. A list item whose text is wrapped
+// in a section, or a table row whose first cell starts and last cell ends
+// with the tags, is repeated or dropped as a whole. Values substituted into
+// macro bodies are escaped as XML.
+func ExecuteTemplate(page *Page, vars map[string]interface{}) (*Page, error) {
+	if page == nil {
+		return &Page{Blocks: []Block{}}, nil
+	}
+	x := &templateExec{}
+	blocks, err := x.blocks(page.Blocks, &templateScope{vars: vars})
+	if err != nil {
+		return nil, err
+	}
+	return &Page{Blocks: blocks}, nil
+}
+
+// ExecuteTemplateText fills the placeholders of a single string, as
+// ExecuteTemplate does within a block.
+func ExecuteTemplateText(text string, vars map[string]interface{}) (string, error) {
+	x := &templateExec{}
+	return x.text(text, &templateScope{vars: vars}, false)
+}
+
+var templateTag = regexp.MustCompile(`\{\{\s*(.*?)\s*\}\}`)
+
+type templateKind int
+
+const (
+	tokText templateKind = iota
+	tokVar
+	tokIf
+	tokElse
+	tokEndIf
+	tokEach
+	tokEndEach
+)
+
+type templateToken struct {
+	kind templateKind
+	arg  string // variable name, or the literal text
+}
+
+func tokenizeTemplate(s string) ([]templateToken, error) {
+	var tokens []templateToken
+	last := 0
+	for _, m := range templateTag.FindAllStringSubmatchIndex(s, -1) {
+		if m[0] > last {
+			tokens = append(tokens, templateToken{kind: tokText, arg: s[last:m[0]]})
+		}
+		last = m[1]
+		tag := s[m[2]:m[3]]
+		name := strings.TrimSpace(tag[strings.IndexByte(tag+" ", ' '):])
+		switch {
+		case strings.HasPrefix(tag, "#if "):
+			tokens = append(tokens, templateToken{kind: tokIf, arg: name})
+		case strings.HasPrefix(tag, "#each "):
+			tokens = append(tokens, templateToken{kind: tokEach, arg: name})
+		case tag == "else":
+			tokens = append(tokens, templateToken{kind: tokElse})
+		case tag == "/if":
+			tokens = append(tokens, templateToken{kind: tokEndIf})
+		case tag == "/each":
+			tokens = append(tokens, templateToken{kind: tokEndEach})
+		case strings.HasPrefix(tag, "#") || strings.HasPrefix(tag, "/") || tag == "":
+			return nil, fmt.Errorf("unknown template tag {{%s}}", tag)
+		default:
+			tokens = append(tokens, templateToken{kind: tokVar, arg: tag})
+		}
+	}
+	if last < len(s) {
+		tokens = append(tokens, templateToken{kind: tokText, arg: s[last:]})
+	}
+	return tokens, nil
+}
+
+// matchSection finds the {{else}} (or -1) and closing tag of the section
+// opened at kinds[start].
+func matchSection(kinds []templateKind, start int) (elseAt, end int, err error) {
+	closer := tokEndIf
+	if kinds[start] == tokEach {
+		closer = tokEndEach
+	}
+	elseAt, depth := -1, 0
+	for i := start + 1; i < len(kinds); i++ {
+		switch kinds[i] {
+		case tokIf, tokEach:
+			depth++
+		case tokEndIf, tokEndEach:
+			if depth > 0 {
+				depth--
+				continue
+			}
+			if kinds[i] != closer {
+				return 0, 0, fmt.Errorf("mismatched closing tag in section")
+			}
+			return elseAt, i, nil
+		case tokElse:
+			if depth == 0 {
+				if closer != tokEndIf || elseAt >= 0 {
+					return 0, 0, fmt.Errorf("unexpected {{else}}")
+				}
+				elseAt = i
+			}
+		}
+	}
+	return 0, 0, fmt.Errorf("unclosed section")
+}
+
+type templateScope struct {
+	vars   map[string]interface{}
+	dot    interface{}
+	parent *templateScope
+}
+
+func (s *templateScope) lookup(name string) (interface{}, bool) {
+	if name == "." {
+		return s.dot, s.dot != nil
+	}
+	for scope := s; scope != nil; scope = scope.parent {
+		if v, ok := scope.vars[name]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func (s *templateScope) truthy(name string) bool {
+	v, _ := s.lookup(name)
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case float64:
+		return v != 0
+	case int:
+		return v != 0
+	case map[string]interface{}:
+		return len(v) > 0
+	}
+	return len(templateList(v)) > 0
+}
+
+// elements returns a scope for each element of the list name.
+func (s *templateScope) elements(name string) ([]*templateScope, error) {
+	v, ok := s.lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("undefined variable %q", name)
+	}
+	list := templateList(v)
+	if list == nil && v != nil {
+		return nil, fmt.Errorf("variable %q is not a list", name)
+	}
+	scopes := make([]*templateScope, len(list))
+	for i, elem := range list {
+		fields, _ := elem.(map[string]interface{})
+		scopes[i] = &templateScope{vars: fields, dot: elem, parent: s}
+	}
+	return scopes, nil
+}
+
+func templateList(v interface{}) []interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		return v
+	case []string:
+		list := make([]interface{}, len(v))
+		for i, s := range v {
+			list[i] = s
+		}
+		return list
+	case []map[string]interface{}:
+		list := make([]interface{}, len(v))
+		for i, m := range v {
+			list[i] = m
+		}
+		return list
+	}
+	return nil
+}
+
+type templateExec struct{}
+
+// text fills the placeholders of s; escape XML-escapes substituted values.
+func (x *templateExec) text(s string, scope *templateScope, escape bool) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+	tokens, err := tokenizeTemplate(s)
+	if err != nil {
+		return "", err
+	}
+	var buf strings.Builder
+	if err := x.tokens(&buf, tokens, scope, escape); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (x *templateExec) tokens(buf *strings.Builder, tokens []templateToken, scope *templateScope, escape bool) error {
+	kinds := make([]templateKind, len(tokens))
+	for i, t := range tokens {
+		kinds[i] = t.kind
+	}
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch t.kind {
+		case tokText:
+			buf.WriteString(t.arg)
+		case tokVar:
+			v, ok := scope.lookup(t.arg)
+			if !ok {
+				return fmt.Errorf("undefined variable %q", t.arg)
+			}
+			s := templateString(v)
+			if escape {
+				s = html.EscapeString(s)
+			}
+			buf.WriteString(s)
+		case tokIf, tokEach:
+			elseAt, end, err := matchSection(kinds, i)
+			if err != nil {
+				return err
+			}
+			err = x.section(t, i+1, elseAt, end, scope, func(from, to int, scope *templateScope) error {
+				return x.tokens(buf, tokens[from:to], scope, escape)
+			})
+			if err != nil {
+				return err
+			}
+			i = end
+		default:
+			return fmt.Errorf("unexpected closing tag")
+		}
+	}
+	return nil
+}
+
+// section runs body once per selected range of a section opened by t, whose
+// content starts at from, with optional else at elseAt and the closing tag at
+// end.
+func (x *templateExec) section(t templateToken, from, elseAt, end int, scope *templateScope, body func(from, to int, scope *templateScope) error) error {
+	if t.kind == tokEach {
+		scopes, err := scope.elements(t.arg)
+		if err != nil {
+			return err
+		}
+		for _, elem := range scopes {
+			if err := body(from, end, elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	switch {
+	case scope.truthy(t.arg) && elseAt >= 0:
+		return body(from, elseAt, scope)
+	case scope.truthy(t.arg):
+		return body(from, end, scope)
+	case elseAt >= 0:
+		return body(elseAt+1, end, scope)
+	}
+	return nil
+}
+
+func templateString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []interface{}, []string:
+		list := templateList(v)
+		parts := make([]string, len(list))
+		for i, elem := range list {
+			parts[i] = templateString(elem)
+		}
+		return strings.Join(parts, ", ")
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return strings.Join(keys, ", ")
+	}
+	return fmt.Sprint(v)
+}
+
+// blockTag returns the section tag a block consists of, if any.
+func blockTag(block Block) (templateToken, bool) {
+	p, ok := pointerBlock(block).(*Paragraph)
+	if !ok {
+		return templateToken{}, false
+	}
+	tokens, err := tokenizeTemplate(strings.TrimSpace(p.Text))
+	if err != nil || len(tokens) != 1 || tokens[0].kind == tokText || tokens[0].kind == tokVar {
+		return templateToken{}, false
+	}
+	return tokens[0], true
+}
+
+func (x *templateExec) blocks(blocks []Block, scope *templateScope) ([]Block, error) {
+	tags := make([]templateToken, len(blocks))
+	kinds := make([]templateKind, len(blocks))
+	for i, b := range blocks {
+		tags[i], _ = blockTag(b)
+		kinds[i] = tags[i].kind
+	}
+
+	out := []Block{}
+	for i := 0; i < len(blocks); i++ {
+		switch kinds[i] {
+		case tokText:
+			b, err := x.block(blocks[i], scope)
+			if err != nil {
+				return nil, fmt.Errorf("block %d: %w", i, err)
+			}
+			if !emptied(blocks[i], b) {
+				out = append(out, b)
+			}
+		case tokIf, tokEach:
+			elseAt, end, err := matchSection(kinds, i)
+			if err != nil {
+				return nil, fmt.Errorf("block %d: %w", i, err)
+			}
+			err = x.section(tags[i], i+1, elseAt, end, scope, func(from, to int, scope *templateScope) error {
+				inner, err := x.blocks(blocks[from:to], scope)
+				out = append(out, inner...)
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+			i = end
+		default:
+			return nil, fmt.Errorf("block %d: unexpected template tag", i)
+		}
+	}
+	return out, nil
+}
+
+// emptied reports whether filling left nothing of a paragraph or list that
+// had content, such as a paragraph holding an unset optional variable.
+func emptied(orig, filled Block) bool {
+	switch f := filled.(type) {
+	case *Paragraph:
+		return strings.TrimSpace(f.Text) == "" && strings.TrimSpace(RenderBlockText(orig)) != ""
+	case *BulletList:
+		return len(f.Items) == 0
+	case *NumberedList:
+		return len(f.Items) == 0
+	case *TaskList:
+		return len(f.Items) == 0
+	}
+	return false
+}
+
+// filler returns a function that fills the placeholders of a string and
+// records the first error in err.
+func (x *templateExec) filler(scope *templateScope, err *error) func(string) string {
+	return func(s string) string {
+		if *err != nil {
+			return s
+		}
+		var out string
+		out, *err = x.text(s, scope, false)
+		return out
+	}
+}
+
+// block returns a copy of block with its text filled in.
+func (x *templateExec) block(block Block, scope *templateScope) (Block, error) {
+	var err error
+	fill := x.filler(scope, &err)
+
+	var result Block
+	switch b := pointerBlock(block).(type) {
+	case *Paragraph:
+		result = &Paragraph{Text: fill(b.Text), Spans: fillSpans(b.Spans, fill)}
+	case *Heading:
+		result = &Heading{Level: b.Level, Text: fill(b.Text)}
+	case *Table:
+		var t *Table
+		t, err = x.table(b, scope)
+		result = t
+	case *BulletList:
+		var items []ListItem
+		items, err = x.items(b.Items, scope)
+		result = &BulletList{Items: items}
+	case *NumberedList:
+		var items []ListItem
+		items, err = x.items(b.Items, scope)
+		result = &NumberedList{Items: items}
+	case *TaskList:
+		var items []TaskItem
+		items, err = x.tasks(b.Items, scope)
+		result = &TaskList{Items: items}
+	case *Blockquote:
+		var blocks []Block
+		blocks, err = x.blocks(b.Blocks, scope)
+		result = &Blockquote{Blocks: blocks}
+	case *Image:
+		result = &Image{URL: fill(b.URL), Attachment: fill(b.Attachment), Alt: fill(b.Alt)}
+	case *CodeBlock:
+		result = &CodeBlock{Language: b.Language, Code: fill(b.Code)}
+	case *Macro:
+		var m *Macro
+		m, err = x.macro(b, scope)
+		result = m
+	default:
+		result = block
+	}
+	return result, err
+}
+
+func fillSpans(spans []Span, fill func(string) string) []Span {
+	if spans == nil {
+		return nil
+	}
+	out := make([]Span, len(spans))
+	for i, s := range spans {
+		s.Text = fill(s.Text)
+		s.Link = fill(s.Link)
+		out[i] = s
+	}
+	return out
+}
+
+func (x *templateExec) macro(m *Macro, scope *templateScope) (*Macro, error) {
+	out := &Macro{Name: m.Name}
+	if m.Params != nil {
+		out.Params = make(map[string]string, len(m.Params))
+		for k, v := range m.Params {
+			filled, err := x.text(v, scope, false)
+			if err != nil {
+				return nil, err
+			}
+			out.Params[k] = filled
+		}
+	}
+	var err error
+	// Code bodies are plain text; other bodies are XHTML.
+	out.Body, err = x.text(m.Body, scope, !plainTextMacros[m.Name])
+	return out, err
+}
+
+// wrapper returns the opening tag of an {{#if}} or {{#each}} section,
+// without {{else}}, that encloses all of texts: the text of a list item, or
+// the cells of a table row.
+func wrapper(texts []string) (templateToken, bool) {
+	tokens, err := tokenizeTemplate(strings.TrimSpace(strings.Join(texts, "\x00")))
+	if err != nil || len(tokens) < 2 || (tokens[0].kind != tokIf && tokens[0].kind != tokEach) {
+		return templateToken{}, false
+	}
+	kinds := make([]templateKind, len(tokens))
+	for i, t := range tokens {
+		kinds[i] = t.kind
+	}
+	elseAt, end, err := matchSection(kinds, 0)
+	return tokens[0], err == nil && elseAt < 0 && end == len(tokens)-1
+}
+
+// unwrap removes the opening tag from the first text and the closing tag
+// from the last, as found by wrapper.
+func unwrap(texts []string) []string {
+	out := append([]string{}, texts...)
+	first := strings.TrimSpace(out[0])
+	out[0] = first[templateTag.FindStringIndex(first)[1]:]
+	last := strings.TrimSpace(out[len(out)-1])
+	tags := templateTag.FindAllStringIndex(last, -1)
+	out[len(out)-1] = last[:tags[len(tags)-1][0]]
+	return out
+}
+
+// repeat calls fn with the scope of each element of an {{#each}} tag, or
+// once if an {{#if}} tag's condition holds.
+func (x *templateExec) repeat(tag templateToken, scope *templateScope, fn func(*templateScope) error) error {
+	return x.section(tag, 0, -1, 0, scope, func(_, _ int, scope *templateScope) error {
+		return fn(scope)
+	})
+}
+
+// unwrapItem strips the section wrapping a list item, if any.
+func unwrapItem(item ListItem) (templateToken, ListItem, bool) {
+	if len(item.Spans) == 0 {
+		tag, ok := wrapper([]string{item.Text})
+		if ok {
+			item.Text = unwrap([]string{item.Text})[0]
+		}
+		return tag, item, ok
+	}
+
+	texts := make([]string, len(item.Spans))
+	for i, span := range item.Spans {
+		texts[i] = span.Text
+	}
+	tag, ok := wrapper(texts)
+	if !ok {
+		return tag, item, false
+	}
+	texts = unwrap(texts)
+	item.Spans = append([]Span{}, item.Spans...)
+	for i := range item.Spans {
+		item.Spans[i].Text = texts[i]
+	}
+	item.Text = strings.Join(texts, "")
+	return tag, item, true
+}
+
+func (x *templateExec) items(items []ListItem, scope *templateScope) ([]ListItem, error) {
+	out := []ListItem{}
+	for i, item := range items {
+		tag, inner, ok := unwrapItem(item)
+		if !ok {
+			filled, err := x.item(item, scope)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+			out = append(out, filled)
+			continue
+		}
+		err := x.repeat(tag, scope, func(scope *templateScope) error {
+			filled, err := x.item(inner, scope)
+			out = append(out, filled)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+	return out, nil
+}
+
+func (x *templateExec) item(item ListItem, scope *templateScope) (ListItem, error) {
+	var err error
+	fill := x.filler(scope, &err)
+	out := ListItem{Text: fill(item.Text), Spans: fillSpans(item.Spans, fill)}
+	if err != nil || len(item.Children) == 0 {
+		return out, err
+	}
+	out.Children, err = x.blocks(item.Children, scope)
+	return out, err
+}
+
+func (x *templateExec) tasks(tasks []TaskItem, scope *templateScope) ([]TaskItem, error) {
+	out := []TaskItem{}
+	for _, task := range tasks {
+		items, err := x.items([]ListItem{{Text: task.Text, Spans: task.Spans, Children: task.Children}}, scope)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			out = append(out, TaskItem{Text: item.Text, Spans: item.Spans, Done: task.Done, Children: item.Children})
+		}
+	}
+	return out, nil
+}
+
+func (x *templateExec) table(t *Table, scope *templateScope) (*Table, error) {
+	var err error
+	fill := x.filler(scope, &err)
+	out := &Table{Headers: make([]string, len(t.Headers)), Rows: []Row{}}
+	for i, h := range t.Headers {
+		out.Headers[i] = fill(h)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("headers: %w", err)
+	}
+
+	for r, row := range t.Rows {
+		texts := make([]string, len(row.Cells))
+		for i, cell := range row.Cells {
+			texts[i] = cell.Text
+		}
+		tag, ok := wrapper(texts)
+		if !ok {
+			filled, err := x.row(row.Cells, scope)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", r, err)
+			}
+			out.Rows = append(out.Rows, filled)
+			continue
+		}
+
+		cells := append([]Cell{}, row.Cells...)
+		for i, text := range unwrap(texts) {
+			cells[i].Text = text
+		}
+		err := x.repeat(tag, scope, func(scope *templateScope) error {
+			filled, err := x.row(cells, scope)
+			out.Rows = append(out.Rows, filled)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", r, err)
+		}
+	}
+	return out, nil
+}
+
+func (x *templateExec) row(cells []Cell, scope *templateScope) (Row, error) {
+	out := Row{Cells: make([]Cell, len(cells))}
+	for i, cell := range cells {
+		if cell.Macro != nil {
+			m, err := x.macro(cell.Macro, scope)
+			if err != nil {
+				return out, err
+			}
+			out.Cells[i] = Cell{Macro: m}
+			continue
+		}
+		text, err := x.text(cell.Text, scope, false)
+		if err != nil {
+			return out, err
+		}
+		out.Cells[i] = Cell{Text: text}
+	}
+	return out, nil
+}
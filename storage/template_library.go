@@ -0,0 +1,258 @@
+package storage
+
+// builtinTemplates holds the built-in templates. Bodies are written in
+// Markdown and parsed on each call, so callers may modify what they get.
+var builtinTemplates = []struct {
+	Template
+	markdown string
+}{
+	{
+		Template: Template{
+			Name:        "meeting-notes",
+			Description: "Meeting notes with attendees, agenda, discussion, decisions and action items",
+			Title:       "{{date}} {{title}}",
+			Variables: []TemplateVariable{
+				{Name: "date", Description: "Meeting date, e.g. 2024-05-01", Required: true},
+				{Name: "title", Description: "Meeting name", Default: "Meeting notes"},
+				{Name: "attendees", Description: "Names of attendees", List: true},
+				{Name: "agenda", Description: "Agenda topics", List: true},
+				{Name: "notes", Description: "Discussion notes"},
+				{Name: "decisions", Description: "Decisions made", List: true},
+				{Name: "actions", Description: "Action items: objects with task, owner and due", List: true},
+			},
+		},
+		markdown: `## Date
+
+{{date}}
+
+## Attendees
+
+{{#if attendees}}
+
+- {{#each attendees}}{{.}}{{/each}}
+
+{{else}}
+
+_No attendees recorded._
+
+{{/if}}
+
+## Agenda
+
+{{#if agenda}}
+
+1. {{#each agenda}}{{.}}{{/each}}
+
+{{/if}}
+
+## Discussion
+
+{{notes}}
+
+## Decisions
+
+{{#if decisions}}
+
+- {{#each decisions}}{{.}}{{/each}}
+
+{{else}}
+
+_No decisions._
+
+{{/if}}
+
+## Action items
+
+- [ ] {{#each actions}}{{task}}{{#if owner}} ({{owner}}){{/if}}{{#if due}}, due {{due}}{{/if}}{{/each}}
+`,
+	},
+	{
+		Template: Template{
+			Name:        "decision-record",
+			Description: "Architecture decision record (ADR): context, options, decision and consequences",
+			Title:       "{{#if number}}ADR-{{number}}: {{/if}}{{title}}",
+			Variables: []TemplateVariable{
+				{Name: "title", Description: "Short decision title", Required: true},
+				{Name: "number", Description: "Record number"},
+				{Name: "status", Description: "Proposed, Accepted, Deprecated or Superseded", Default: "Proposed"},
+				{Name: "date", Description: "Decision date"},
+				{Name: "deciders", Description: "People involved in the decision", List: true},
+				{Name: "context", Description: "The forces at play and the problem being decided"},
+				{Name: "options", Description: "Options considered: objects with name, pros and cons", List: true},
+				{Name: "decision", Description: "The chosen option and why"},
+				{Name: "consequences", Description: "What becomes easier or harder"},
+			},
+		},
+		markdown: `| Status | Date | Deciders |
+|---|---|---|
+| {{status}} | {{date}} | {{deciders}} |
+
+## Context
+
+{{context}}
+
+## Options considered
+
+{{#if options}}
+
+| Option | Pros | Cons |
+|---|---|---|
+| {{#each options}}{{name}} | {{pros}} | {{cons}}{{/each}} |
+
+{{/if}}
+
+## Decision
+
+{{decision}}
+
+## Consequences
+
+{{consequences}}
+`,
+	},
+	{
+		Template: Template{
+			Name:        "incident-postmortem",
+			Description: "Blameless incident postmortem with impact, timeline, root cause and follow-ups",
+			Title:       "Postmortem: {{title}}",
+			Variables: []TemplateVariable{
+				{Name: "title", Description: "Incident name", Required: true},
+				{Name: "date", Description: "Incident date"},
+				{Name: "severity", Description: "Severity level", Default: "SEV-2"},
+				{Name: "authors", Description: "Postmortem authors", List: true},
+				{Name: "summary", Description: "What happened, in a few sentences"},
+				{Name: "impact", Description: "Who and what was affected, and for how long"},
+				{Name: "timeline", Description: "Events: objects with time and event", List: true},
+				{Name: "root_cause", Description: "Why it happened"},
+				{Name: "resolution", Description: "How service was restored"},
+				{Name: "lessons", Description: "What went well and what did not", List: true},
+				{Name: "actions", Description: "Follow-up actions: objects with task and owner", List: true},
+			},
+		},
+		markdown: `| Date | Severity | Authors |
+|---|---|---|
+| {{date}} | {{severity}} | {{authors}} |
+
+## Summary
+
+{{summary}}
+
+## Impact
+
+{{impact}}
+
+## Timeline
+
+{{#if timeline}}
+
+| Time | Event |
+|---|---|
+| {{#each timeline}}{{time}} | {{event}}{{/each}} |
+
+{{/if}}
+
+## Root cause
+
+{{root_cause}}
+
+## Resolution
+
+{{resolution}}
+
+## Lessons learned
+
+- {{#each lessons}}{{.}}{{/each}}
+
+## Action items
+
+- [ ] {{#each actions}}{{task}}{{#if owner}} ({{owner}}){{/if}}{{/each}}
+`,
+	},
+	{
+		Template: Template{
+			Name:        "runbook",
+			Description: "Operational runbook with prerequisites, numbered procedures, verification, rollback and escalation",
+			Title:       "Runbook: {{service}}{{#if task}} - {{task}}{{/if}}",
+			Variables: []TemplateVariable{
+				{Name: "service", Description: "Service the runbook is for", Required: true},
+				{Name: "task", Description: "Operation the runbook covers"},
+				{Name: "owner", Description: "Owning team"},
+				{Name: "description", Description: "When to use this runbook"},
+				{Name: "prerequisites", Description: "Access and tools needed", List: true},
+				{Name: "steps", Description: "Procedure: objects with title, description and command", List: true},
+				{Name: "verification", Description: "How to check the operation succeeded"},
+				{Name: "rollback", Description: "How to undo the operation"},
+				{Name: "escalation", Description: "Contacts: objects with name and contact", List: true},
+			},
+		},
+		markdown: `| Service | Owner |
+|---|---|
+| {{service}} | {{owner}} |
+
+## Overview
+
+{{description}}
+
+## Prerequisites
+
+- [ ] {{#each prerequisites}}{{.}}{{/each}}
+
+## Procedure
+
+{{#each steps}}
+
+### {{title}}
+
+{{#if description}}
+
+{{description}}
+
+{{/if}}
+
+{{#if command}}
+
+` + "```bash\n{{command}}\n```" + `
+
+{{/if}}
+
+{{/each}}
+
+## Verification
+
+{{verification}}
+
+## Rollback
+
+{{rollback}}
+
+## Escalation
+
+| Name | Contact |
+|---|---|
+| {{#each escalation}}{{name}} | {{contact}}{{/each}} |
+`,
+	},
+}
+
+// BuiltinTemplates returns the built-in templates: meeting-notes,
+// decision-record, incident-postmortem and runbook.
+func BuiltinTemplates() []*Template {
+	templates := make([]*Template, len(builtinTemplates))
+	for i, b := range builtinTemplates {
+		t := b.Template
+		t.Variables = append([]TemplateVariable{}, t.Variables...)
+		t.Body = ParseMarkdown(b.markdown)
+		templates[i] = &t
+	}
+	return templates
+}
+
+// BuiltinTemplate returns the built-in template with the given name.
+func BuiltinTemplate(name string) (*Template, bool) {
+	for _, t := range BuiltinTemplates() {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExecuteTemplate(t *testing.T) {
+	body := ParseMarkdown(`# {{team}} sync
+
+{{#if agenda}}
+
+Agenda: {{agenda}}
+
+{{else}}
+
+No agenda.
+
+{{/if}}
+
+- {{#each attendees}}**{{.}}**{{/each}}
+- Guests: {{#each guests}}{{.}}; {{/each}}
+
+| Owner | Task |
+|---|---|
+| {{#each actions}}{{owner}} | {{task}}{{/each}} |
+
+- [ ] {{#each actions}}{{task}} ({{owner}}){{/each}}
+
+{{#each notes}}
+
+## {{title}}
+
+{{text}}
+
+{{/each}}
+`)
+	body.Blocks = append(body.Blocks, &Macro{Name: "info", Body: "<p>{{team}}</p>"})
+
+	vars := map[string]interface{}{
+		"team":      "Core",
+		"agenda":    "",
+		"attendees": []interface{}{"Ana", "Bo"},
+		"guests":    []string{"Cy", "Di"},
+		"actions": []interface{}{
+			map[string]interface{}{"owner": "Ana", "task": "Ship"},
+			map[string]interface{}{"owner": "Bo", "task": "Test"},
+		},
+		"notes": []map[string]interface{}{{"title": "Risks", "text": "None"}},
+	}
+	vars["team"] = "Core & Infra"
+
+	page, err := ExecuteTemplate(body, vars)
+	if err != nil {
+		t.Fatalf("ExecuteTemplate() error = %v", err)
+	}
+	got, err := Render(page)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	want := `<h1>Core &amp; Infra sync</h1>` +
+		`<p>No agenda.</p>` +
+		`<ul><li><strong>Ana</strong></li><li><strong>Bo</strong></li><li>Guests: Cy; Di; </li></ul>` +
+		`<table><tbody><tr><th>Owner</th><th>Task</th></tr><tr><td>Ana</td><td>Ship</td></tr><tr><td>Bo</td><td>Test</td></tr></tbody></table>` +
+		`<ac:task-list><ac:task><ac:task-status>incomplete</ac:task-status><ac:task-body>Ship (Ana)</ac:task-body></ac:task>` +
+		`<ac:task><ac:task-status>incomplete</ac:task-status><ac:task-body>Test (Bo)</ac:task-body></ac:task></ac:task-list>` +
+		`<h2>Risks</h2><p>None</p>` +
+		`<ac:structured-macro ac:name="info"><ac:rich-text-body><p>Core &amp; Infra</p></ac:rich-text-body></ac:structured-macro>`
+	if got != want {
+		t.Errorf("ExecuteTemplate() =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, err := Render(body); err != nil {
+		t.Fatalf("template modified: %v", err)
+	}
+	if body.Blocks[0].(*Heading).Text != "{{team}} sync" {
+		t.Error("ExecuteTemplate() modified the template")
+	}
+}
+
+func TestExecuteTemplateErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"undefined", "Hello {{name}}", `undefined variable "name"`},
+		{"unclosed block", "{{#if x}}\n\ntext", "unclosed section"},
+		{"unclosed inline", "a {{#each xs}} b", "unclosed section"},
+		{"mismatched", "{{#if x}} a {{/each}}", "mismatched closing tag"},
+		{"stray close", "{{/if}}", "unexpected template tag"},
+		{"else in each", "{{#each xs}}{{else}}{{/each}}", "unexpected {{else}}"},
+		{"unknown tag", "{{#with x}}", "unknown template tag"},
+		{"not a list", "{{#each x}}{{.}}{{/each}}", `"x" is not a list`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ExecuteTemplate(ParseMarkdown(tt.body), map[string]interface{}{"x": "y", "xs": []interface{}{}})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ExecuteTemplate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateExecute(t *testing.T) {
+	tmpl := &Template{
+		Name:  "t",
+		Title: "{{date}} notes{{#if team}} ({{team}}){{/if}}",
+		Variables: []TemplateVariable{
+			{Name: "date", Required: true},
+			{Name: "team"},
+			{Name: "items", List: true},
+			{Name: "status", Default: "Draft"},
+		},
+		Body: ParseMarkdown("Status: {{status}}\n\n- {{#each items}}{{.}}{{/each}}\n"),
+	}
+
+	title, page, err := tmpl.Execute(map[string]interface{}{"date": "2024-05-01"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if title != "2024-05-01 notes" {
+		t.Errorf("Execute() title = %q", title)
+	}
+	if got, _ := Render(page); got != "<p>Status: Draft</p>" {
+		t.Errorf("Execute() page = %s", got)
+	}
+
+	if _, _, err := tmpl.Execute(nil); err == nil || !strings.Contains(err.Error(), "missing required variables: date") {
+		t.Errorf("Execute() error = %v", err)
+	}
+}
+
+func TestBuiltinTemplates(t *testing.T) {
+	templates := BuiltinTemplates()
+	if len(templates) < 4 {
+		t.Fatalf("BuiltinTemplates() = %d templates", len(templates))
+	}
+	for _, tmpl := range templates {
+		t.Run(tmpl.Name, func(t *testing.T) {
+			vars := map[string]interface{}{}
+			for _, v := range tmpl.Variables {
+				if v.Required {
+					vars[v.Name] = "x"
+				}
+			}
+			title, page, err := tmpl.Execute(vars)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if title == "" || len(page.Blocks) == 0 {
+				t.Errorf("Execute() = %q, %d blocks", title, len(page.Blocks))
+			}
+			xhtml, err := Render(page)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if err := Validate(xhtml); err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+			if strings.Contains(xhtml, "{{") {
+				t.Errorf("unfilled placeholder in %s", xhtml)
+			}
+		})
+	}
+
+	if _, ok := BuiltinTemplate("runbook"); !ok {
+		t.Error("BuiltinTemplate(runbook) not found")
+	}
+	if _, ok := BuiltinTemplate("nope"); ok {
+		t.Error("BuiltinTemplate(nope) found")
+	}
+}
@@ -3,6 +3,8 @@
 // parsing from Storage XHTML, and validation.
 package storage
 
+import "encoding/json"
+
 // Block represents any content block in Confluence Storage Format.
 type Block interface {
 	// BlockType returns the type identifier (e.g., "table", "paragraph").
@@ -44,9 +46,25 @@ type Macro struct {
 // BlockType implements Block.
 func (Macro) BlockType() string { return "macro" }
 
-// Paragraph represents a simple text paragraph.
+// Span is a run of inline text sharing the same formatting.
+type Span struct {
+	Text   string `json:"text"`
+	Bold   bool   `json:"bold,omitempty"`
+	Italic bool   `json:"italic,omitempty"`
+	Code   bool   `json:"code,omitempty"`
+	Strike bool   `json:"strike,omitempty"`
+	Link   string `json:"link,omitempty"`  // href when the span is a link
+	Page   string `json:"page,omitempty"`  // title of the linked Confluence page
+	Space  string `json:"space,omitempty"` // space key of the linked page, if in another space
+}
+
+// Paragraph represents a text paragraph. Text is the plain-text content; when
+// Spans is set it holds the same text with inline formatting and is used for
+// rendering. If Text is set and the spans' text differs from it, Text wins
+// and the spans are ignored.
 type Paragraph struct {
-	Text string `json:"text"`
+	Text  string `json:"text"`
+	Spans []Span `json:"spans,omitempty"`
 }
 
 // BlockType implements Block.
@@ -77,11 +95,71 @@ type NumberedList struct {
 // BlockType implements Block.
 func (NumberedList) BlockType() string { return "numbered_list" }
 
-// ListItem represents a list item.
+// ListItem represents a list item. Like Paragraph, Spans optionally carries
+// the formatted form of Text, and is ignored if it disagrees with Text.
+// Children holds nested blocks such as sublists.
 type ListItem struct {
-	Text string `json:"text"`
+	Text     string  `json:"text"`
+	Spans    []Span  `json:"spans,omitempty"`
+	Children []Block `json:"-"`
+}
+
+// MarshalJSON includes the item's children tagged with their type.
+func (li ListItem) MarshalJSON() ([]byte, error) {
+	type plain ListItem
+	return marshalWithChildren(plain(li), li.Children)
+}
+
+// TaskList represents a Confluence task list (ac:task-list).
+type TaskList struct {
+	Items []TaskItem `json:"items"`
+}
+
+// BlockType implements Block.
+func (TaskList) BlockType() string { return "task_list" }
+
+// TaskItem represents a single task with its completion state.
+type TaskItem struct {
+	Text     string  `json:"text"`
+	Spans    []Span  `json:"spans,omitempty"`
+	Done     bool    `json:"done"`
+	Children []Block `json:"-"`
+}
+
+// MarshalJSON includes the task's children tagged with their type.
+func (ti TaskItem) MarshalJSON() ([]byte, error) {
+	type plain TaskItem
+	return marshalWithChildren(plain(ti), ti.Children)
+}
+
+// Blockquote represents a quotation containing other blocks.
+type Blockquote struct {
+	Blocks []Block `json:"-"`
+}
+
+// BlockType implements Block.
+func (Blockquote) BlockType() string { return "blockquote" }
+
+// MarshalJSON includes the quoted blocks tagged with their type.
+func (bq Blockquote) MarshalJSON() ([]byte, error) {
+	blocks, err := marshalBlocks(bq.Blocks)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]interface{}{"blocks": blocks})
 }
 
+// Image represents an image (ac:image), either an external URL or a file
+// attached to the page.
+type Image struct {
+	URL        string `json:"url,omitempty"`
+	Attachment string `json:"attachment,omitempty"` // attachment file name
+	Alt        string `json:"alt,omitempty"`
+}
+
+// BlockType implements Block.
+func (Image) BlockType() string { return "image" }
+
 // CodeBlock represents a code block with optional language.
 type CodeBlock struct {
 	Language string `json:"language,omitempty"`
@@ -96,3 +174,25 @@ type HorizontalRule struct{}
 
 // BlockType implements Block.
 func (HorizontalRule) BlockType() string { return "horizontal_rule" }
+
+// marshalWithChildren marshals v and adds a "children" field holding the
+// given blocks tagged with their type.
+func marshalWithChildren(v interface{}, children []Block) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(children) == 0 {
+		return data, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	blocks, err := marshalBlocks(children)
+	if err != nil {
+		return nil, err
+	}
+	fields["children"], err = json.Marshal(blocks)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
@@ -72,7 +72,7 @@ func ValidateWithOptions(xhtml string, opts ValidatorOptions) error {
 	}
 
 	// Wrap in root element for XML parsing
-	wrapped := "<root xmlns:ac=\"http://atlassian.com/confluence\" xmlns:ri=\"http://atlassian.com/confluence\">" + xhtml + "</root>"
+	wrapped := rootOpen + xhtml + rootClose
 	decoder := xml.NewDecoder(strings.NewReader(wrapped))
 	decoder.Entity = htmlEntities
 
@@ -0,0 +1,55 @@
+package storage
+
+import "strconv"
+
+// WalkFunc is called by Walk for each block with its JSON-pointer style path
+// (e.g. "/blocks/2/items/0/children/1"). Returning false skips the blocks
+// nested inside block.
+type WalkFunc func(path string, block Block) bool
+
+// Walk visits the blocks of page in document order, descending into list
+// item children, blockquotes and macros in table cells. A macro in a cell
+// is visited with the cell's path, "/blocks/N/rows/R/cells/C", which
+// PatchSetCell accepts.
+func Walk(page *Page, fn WalkFunc) {
+	if page == nil {
+		return
+	}
+	walkBlocks("/blocks", page.Blocks, fn)
+}
+
+func walkBlocks(prefix string, blocks []Block, fn WalkFunc) {
+	for i, block := range blocks {
+		walkBlock(prefix+"/"+strconv.Itoa(i), block, fn)
+	}
+}
+
+func walkBlock(path string, block Block, fn WalkFunc) {
+	if !fn(path, block) {
+		return
+	}
+	switch b := pointerBlock(block).(type) {
+	case *Table:
+		for r, row := range b.Rows {
+			for c, cell := range row.Cells {
+				if cell.Macro != nil {
+					fn(path+"/rows/"+strconv.Itoa(r)+"/cells/"+strconv.Itoa(c), cell.Macro)
+				}
+			}
+		}
+	case *BulletList:
+		for k, item := range b.Items {
+			walkBlocks(path+"/items/"+strconv.Itoa(k)+"/children", item.Children, fn)
+		}
+	case *NumberedList:
+		for k, item := range b.Items {
+			walkBlocks(path+"/items/"+strconv.Itoa(k)+"/children", item.Children, fn)
+		}
+	case *TaskList:
+		for k, item := range b.Items {
+			walkBlocks(path+"/items/"+strconv.Itoa(k)+"/children", item.Children, fn)
+		}
+	case *Blockquote:
+		walkBlocks(path+"/blocks", b.Blocks, fn)
+	}
+}